@@ -1,17 +1,33 @@
 package collection
 
 import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"container/heap"
 	"context"
 	cryptorand "crypto/rand"
+	"database/sql"
+	"encoding/binary"
+	"encoding/gob"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"iter"
+	"math"
 	"math/big"
 	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
 	"runtime"
+	"runtime/debug"
 	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 )
@@ -20,9 +36,70 @@ var ErrNoElement = errors.New("no element")
 var ErrIndexOutOfRange = errors.New("index out of range")
 var ErrEmptyCollection = errors.New("empty collection")
 var ErrNotExactlyOneElement = errors.New("not exactly one element")
+var ErrKeyMismatch = errors.New("key mismatch")
+var ErrDuplicateKey = errors.New("duplicate key")
+var ErrInvalidPercentile = errors.New("invalid percentile")
+var ErrNotOrdered = errors.New("not ordered")
+var ErrInvalidInterval = errors.New("invalid interval")
+var ErrInvalidLineSize = errors.New("invalid line size")
+var ErrCorruptSnapshot = errors.New("corrupt snapshot")
+var ErrUnsupportedSnapshotVersion = errors.New("unsupported snapshot version")
+var ErrLengthMismatch = errors.New("length mismatch")
+var ErrInvalidCast = errors.New("invalid cast")
+var ErrConsumed = errors.New("already consumed")
 
 type Collection[T any] func(yield func(T) bool)
 
+// Pair holds two related values
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// Fallible is a collection whose enumeration can fail, such as one read lazily from an io.Reader. A Fallible which
+// reads from a single-use source, such as an io.Reader, can only be enumerated once; later enumerations yield nothing
+// and report ErrConsumed
+type Fallible[T any] struct {
+	*Collection[T]
+
+	mu  sync.Mutex
+	err error
+}
+
+// Err returns the error which stopped the most recent enumeration, or nil if there was none. It is safe to call
+// while the collection is being enumerated
+func (f *Fallible[T]) Err() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.err
+}
+
+// newFallible creates a Fallible from a sequence which returns the error, if any, that stopped it
+func newFallible[T any](seq func(yield func(T) bool) error) *Fallible[T] {
+	f := &Fallible[T]{}
+	f.Collection = New[T](iter.Seq[T](func(yield func(T) bool) {
+		err := seq(yield)
+
+		f.mu.Lock()
+		f.err = err
+		f.mu.Unlock()
+	}))
+	return f
+}
+
+// newSingleUseFallible creates a Fallible from a sequence which can only be enumerated once, such as one reading from
+// an io.Reader. Later enumerations yield nothing and report ErrConsumed
+func newSingleUseFallible[T any](seq func(yield func(T) bool) error) *Fallible[T] {
+	var used atomic.Bool
+	return newFallible(func(yield func(T) bool) error {
+		if used.Swap(true) {
+			return ErrConsumed
+		}
+		return seq(yield)
+	})
+}
+
 // New creates a new Collection from either an iterator or a slice
 func New[T any, I iter.Seq[T] | []T](seq I) *Collection[T] {
 	if s, ok := any(seq).([]T); ok {
@@ -32,6 +109,12 @@ func New[T any, I iter.Seq[T] | []T](seq I) *Collection[T] {
 	}
 }
 
+// Empty creates a new Collection with no elements
+func Empty[T any]() *Collection[T] {
+	d := Collection[T](func(yield func(T) bool) {})
+	return &d
+}
+
 // NewFromIterator creates a new Collection from an iterator
 func NewFromIterator[T any](s iter.Seq[T]) *Collection[T] {
 	d := Collection[T](s)
@@ -59,7 +142,9 @@ func NewFromMap[K comparable, V any](m map[K]V) *Collection[V] {
 	return NewFromSlice(values)
 }
 
-// NewFromChannel creates a new Collection from a channel
+// NewFromChannel creates a new Collection from a channel. Enumerating the collection receives from the channel, so
+// a second enumeration only yields values sent since the first; use NewFromChannelReplay or Cache to enumerate
+// the values more than once
 func NewFromChannel[T any](ch <-chan T) *Collection[T] {
 	return New[T](iter.Seq[T](func(yield func(T) bool) {
 		for v := range ch {
@@ -70,6 +155,106 @@ func NewFromChannel[T any](ch <-chan T) *Collection[T] {
 	}))
 }
 
+// NewFromChannelCtx creates a new Collection from a channel which ends when the channel is closed or the context
+// is done, whichever happens first
+func NewFromChannelCtx[T any](ctx context.Context, ch <-chan T) *Collection[T] {
+	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case v, ok := <-ch:
+				if !ok || !yield(v) {
+					return
+				}
+			}
+		}
+	}))
+}
+
+// NewFromChannels creates a new Collection which receives from each channel concurrently, yielding values in the
+// order they arrive and ending once every channel is closed
+func NewFromChannels[T any](chs ...<-chan T) *Collection[T] {
+	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		done := make(chan struct{})
+		defer close(done)
+
+		out := make(chan T)
+		var wg sync.WaitGroup
+		for _, ch := range chs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					select {
+					case <-done:
+						return
+					case v, ok := <-ch:
+						if !ok {
+							return
+						}
+						select {
+						case <-done:
+							return
+						case out <- v:
+						}
+					}
+				}
+			}()
+		}
+		go func() {
+			wg.Wait()
+			close(out)
+		}()
+
+		for v := range out {
+			if !yield(v) {
+				return
+			}
+		}
+	}))
+}
+
+// NewFromChannelReplay creates a new Collection from a channel which records values as they are received, so
+// later enumerations replay every value received so far before continuing to receive from the channel. Replaying
+// does not wait while another enumeration is waiting on an idle channel
+func NewFromChannelReplay[T any](ch <-chan T) *Collection[T] {
+	return NewFromChannel(ch).Cache()
+}
+
+// NewFromSeq2Values creates a new Collection from the values of a key-value iterator, discarding the keys
+func NewFromSeq2Values[K, V any](s iter.Seq2[K, V]) *Collection[V] {
+	return New[V](iter.Seq[V](func(yield func(V) bool) {
+		for _, v := range s {
+			if !yield(v) {
+				return
+			}
+		}
+	}))
+}
+
+// NewFromSeq2Keys creates a new Collection from the keys of a key-value iterator, discarding the values
+func NewFromSeq2Keys[K, V any](s iter.Seq2[K, V]) *Collection[K] {
+	return New[K](iter.Seq[K](func(yield func(K) bool) {
+		for k := range s {
+			if !yield(k) {
+				return
+			}
+		}
+	}))
+}
+
+// NewFromSeq2 creates a new Collection of pairs from a key-value iterator
+func NewFromSeq2[K, V any](s iter.Seq2[K, V]) *Collection[Pair[K, V]] {
+	return New[Pair[K, V]](iter.Seq[Pair[K, V]](func(yield func(Pair[K, V]) bool) {
+		for k, v := range s {
+			if !yield(Pair[K, V]{First: k, Second: v}) {
+				return
+			}
+		}
+	}))
+}
+
 // NewFromRange creates a new Collection from a range of integers
 func NewFromRange(start, count int) *Collection[int] {
 	if count < 0 {
@@ -85,6 +270,85 @@ func NewFromRange(start, count int) *Collection[int] {
 	}))
 }
 
+// NewFromRangeStep creates a new Collection of integers from start up to but excluding end, advancing by step. A
+// negative step produces a descending range. Panics if step is zero
+func NewFromRangeStep(start, end, step int) *Collection[int] {
+	return rangeStep(start, end, step)
+}
+
+// NewFromRangeStep64 creates a new Collection of int64 values from start up to but excluding end, advancing by
+// step. A negative step produces a descending range. Panics if step is zero
+func NewFromRangeStep64(start, end, step int64) *Collection[int64] {
+	return rangeStep(start, end, step)
+}
+
+func rangeStep[T int | int64](start, end, step T) *Collection[T] {
+	if step == 0 {
+		panic("collection: range step must not be zero")
+	}
+
+	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		// The remaining distance is compared unsigned so that stepping past end cannot overflow
+		for v := start; (step > 0 && v < end) || (step < 0 && v > end); v += step {
+			if !yield(v) {
+				return
+			}
+			if step > 0 && uint64(end-v) <= uint64(step) || step < 0 && uint64(v-end) <= uint64(-step) {
+				return
+			}
+		}
+	}))
+}
+
+// NewFromFloatRange creates a new Collection of evenly spaced float64 values from start up to but excluding end,
+// advancing by step. Each element is computed as start+i*step rather than by repeated addition, so rounding error
+// does not accumulate. A negative step produces a descending range. Panics if step is zero or NaN
+func NewFromFloatRange(start, end, step float64) *Collection[float64] {
+	if step == 0 || math.IsNaN(step) {
+		panic("collection: range step must not be zero or NaN")
+	}
+
+	// A small tolerance, relative to the number of steps, keeps a span that is an exact multiple of step from gaining
+	// an extra element near end
+	steps := (end - start) / step
+	count := math.Ceil(steps - math.Abs(steps)*1e-12)
+	if !(count > 0) {
+		return Empty[float64]()
+	}
+
+	return New[float64](iter.Seq[float64](func(yield func(float64) bool) {
+		for i := 0.0; i < count; i++ {
+			if !yield(start + i*step) {
+				return
+			}
+		}
+	}))
+}
+
+// Unfold creates a new Collection by repeatedly calling f with the current state, yielding each returned element
+// and threading the returned state into the next call, until f returns false
+func Unfold[S, T any](seed S, f func(state S) (T, S, bool)) *Collection[T] {
+	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		state := seed
+		for {
+			v, next, ok := f(state)
+			if !ok || !yield(v) {
+				return
+			}
+			state = next
+		}
+	}))
+}
+
+// Iterate creates a new infinite Collection of seed, f(seed), f(f(seed)) and so on, intended to be bounded with
+// operations such as Take or TakeWhile
+func Iterate[T any](seed T, f func(x T) T) *Collection[T] {
+	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		for v := seed; yield(v); v = f(v) {
+		}
+	}))
+}
+
 // NewFromJSON deserializes JSON into a new collection
 func NewFromJSON[T any](data []byte) (c *Collection[T], err error) {
 	var items []T
@@ -95,17 +359,212 @@ func NewFromJSON[T any](data []byte) (c *Collection[T], err error) {
 	return
 }
 
+// NewFromJSONReader creates a new Fallible collection which lazily decodes a JSON array read from r. Elements are
+// read from r as they are enumerated, so the collection can only be enumerated once
+func NewFromJSONReader[T any](r io.Reader) *Fallible[T] {
+	return newSingleUseFallible(func(yield func(T) bool) error {
+		decoder := json.NewDecoder(r)
+		token, err := decoder.Token()
+		if err != nil {
+			return fmt.Errorf("failed to read JSON array: %w", err)
+		}
+		if token == nil {
+			return nil
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			return fmt.Errorf("failed to read JSON array: unexpected %v", token)
+		}
+
+		for i := 0; decoder.More(); i++ {
+			var v T
+			if err := decoder.Decode(&v); err != nil {
+				return fmt.Errorf("failed to decode element %d: %w", i, err)
+			}
+			if !yield(v) {
+				return nil
+			}
+		}
+
+		if _, err := decoder.Token(); err != nil {
+			return fmt.Errorf("failed to read end of JSON array: %w", err)
+		}
+		return nil
+	})
+}
+
+// NewFromNDJSON creates a new Fallible collection which lazily decodes newline-delimited JSON read from r. Lines
+// are read from r as they are enumerated, so the collection can only be enumerated once
+func NewFromNDJSON[T any](r io.Reader) *Fallible[T] {
+	return newSingleUseFallible(func(yield func(T) bool) error {
+		reader := bufio.NewReader(r)
+		for line := 1; ; line++ {
+			data, readErr := reader.ReadBytes('\n')
+			if readErr != nil && readErr != io.EOF {
+				return fmt.Errorf("line %d: %w", line, readErr)
+			}
+
+			if data = bytes.TrimSpace(data); len(data) > 0 {
+				var v T
+				if err := json.Unmarshal(data, &v); err != nil {
+					return fmt.Errorf("line %d: %w", line, err)
+				}
+				if !yield(v) {
+					return nil
+				}
+			}
+
+			if readErr == io.EOF {
+				return nil
+			}
+		}
+	})
+}
+
+// NewFromGob creates a new Fallible collection which lazily decodes a gob stream written by EncodeGob. The stream is
+// read from r as the collection is enumerated, and carries its type information only once, so the collection can
+// only be enumerated once
+func NewFromGob[T any](r io.Reader) *Fallible[T] {
+	return newSingleUseFallible(func(yield func(T) bool) error {
+		decoder := gob.NewDecoder(r)
+		for i := 0; ; i++ {
+			var more bool
+			if err := decoder.Decode(&more); err != nil {
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				return fmt.Errorf("failed to decode element %d: %w", i, err)
+			}
+			if !more {
+				return nil
+			}
+
+			var v T
+			if err := decoder.Decode(&v); err != nil {
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				return fmt.Errorf("failed to decode element %d: %w", i, err)
+			}
+			if !yield(v) {
+				return nil
+			}
+		}
+	})
+}
+
+// NewFromLines creates a new Fallible collection which lazily reads the lines of r, without their line endings.
+// Lines are read from r as they are enumerated, so the collection can only be enumerated once
+func NewFromLines(r io.Reader) *Fallible[string] {
+	return NewFromLinesWithLimit(r, bufio.MaxScanTokenSize)
+}
+
+// NewFromLinesWithLimit is like NewFromLines but accepts lines up to maxLineSize bytes, excluding the line ending,
+// reporting bufio.ErrTooLong for longer lines. A maxLineSize that is not positive reports an error wrapping ErrInvalidLineSize
+func NewFromLinesWithLimit(r io.Reader, maxLineSize int) *Fallible[string] {
+	return newSingleUseFallible(func(yield func(string) bool) error {
+		if maxLineSize <= 0 {
+			return fmt.Errorf("%w: %d is not positive", ErrInvalidLineSize, maxLineSize)
+		}
+		scanner := bufio.NewScanner(r)
+		// Leave room for a trailing "\r\n" so a line of exactly maxLineSize bytes still fits the buffer
+		scanner.Buffer(make([]byte, 0, min(maxLineSize+2, 4096)), maxLineSize+2)
+		scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+			advance, token, err := bufio.ScanLines(data, atEOF)
+			if len(token) > maxLineSize {
+				return 0, nil, bufio.ErrTooLong
+			}
+			return advance, token, err
+		})
+		for scanner.Scan() {
+			if !yield(scanner.Text()) {
+				return nil
+			}
+		}
+		return scanner.Err()
+	})
+}
+
+// NewFromScanner creates a new Fallible collection from a Next/Value style cursor, reporting err once it is exhausted.
+// A cursor cannot be rewound, so the collection can only be enumerated once
+func NewFromScanner[T any](next func() bool, value func() T, err func() error) *Fallible[T] {
+	return newSingleUseFallible(func(yield func(T) bool) error {
+		for next() {
+			if !yield(value()) {
+				return nil
+			}
+		}
+		return err()
+	})
+}
+
+// NewFromBufioScanner creates a new Fallible collection of the tokens produced by s
+func NewFromBufioScanner(s *bufio.Scanner) *Fallible[string] {
+	return NewFromScanner(s.Scan, s.Text, s.Err)
+}
+
+// NewFromRows creates a new Fallible collection which lazily scans database rows, closing them once enumeration ends.
+// The rows can only be read once, so the collection can only be enumerated once
+func NewFromRows[T any](rows *sql.Rows, scan func(*sql.Rows) (T, error)) *Fallible[T] {
+	return newSingleUseFallible(func(yield func(T) bool) (err error) {
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}()
+
+		for i := 0; rows.Next(); i++ {
+			v, err := scan(rows)
+			if err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+			if !yield(v) {
+				return nil
+			}
+		}
+		return rows.Err()
+	})
+}
+
 // Where filters the collection to only elements satisfying the predicate function
 func (c *Collection[T]) Where(f func(x T) bool) *Collection[T] {
 	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		FilterSeq(iter.Seq[T](*c), f)(yield)
+	}))
+}
+
+// WhereIndexed filters the collection to only elements satisfying the predicate function, which is given each
+// element's position in the source collection
+func (c *Collection[T]) WhereIndexed(f func(i int, x T) bool) *Collection[T] {
+	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		i := 0
 		for v := range *c {
-			if f(v) && !yield(v) {
+			if f(i, v) && !yield(v) {
 				return
 			}
+			i++
 		}
 	}))
 }
 
+// WhereOrError eagerly filters the collection to only elements satisfying the predicate function, stopping at the
+// first error, which is returned wrapped with the index of the failing element
+func (c *Collection[T]) WhereOrError(f func(x T) (bool, error)) (*Collection[T], error) {
+	var results []T
+	i := 0
+	for v := range *c {
+		ok, err := f(v)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		if ok {
+			results = append(results, v)
+		}
+		i++
+	}
+
+	return NewFromSlice(results), nil
+}
+
 // Reject filters the collection to only elements not satisfying the predicate function
 func (c *Collection[T]) Reject(f func(x T) bool) *Collection[T] {
 	return c.Where(func(x T) bool {
@@ -113,6 +572,38 @@ func (c *Collection[T]) Reject(f func(x T) bool) *Collection[T] {
 	})
 }
 
+// WhereFollowedBy filters the collection to only elements immediately followed by an element satisfying the
+// predicate function. The last element has no follower, so is never included
+func (c *Collection[T]) WhereFollowedBy(f func(next T) bool) *Collection[T] {
+	return c.whereFollower(f, true)
+}
+
+// WhereNotFollowedBy filters the collection to only elements not immediately followed by an element satisfying
+// the predicate function. The last element has no follower, so is always included
+func (c *Collection[T]) WhereNotFollowedBy(f func(next T) bool) *Collection[T] {
+	return c.whereFollower(f, false)
+}
+
+func (c *Collection[T]) whereFollower(f func(next T) bool, followed bool) *Collection[T] {
+	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		var previous T
+		hasPrevious := false
+		for v := range *c {
+			if hasPrevious && f(v) == followed {
+				if !yield(previous) {
+					return
+				}
+			}
+			previous = v
+			hasPrevious = true
+		}
+
+		if hasPrevious && !followed {
+			yield(previous)
+		}
+	}))
+}
+
 // Find returns the first element that matches the given predicate.
 // If no element matches, it returns the zero value and false.
 func (c *Collection[T]) Find(f func(T) bool) (v T, ok bool) {
@@ -124,11 +615,45 @@ func (c *Collection[T]) Find(f func(T) bool) (v T, ok bool) {
 	return
 }
 
+// FindOrError returns the first element that matches the given predicate, or ErrNoElement if none match
+func (c *Collection[T]) FindOrError(f func(T) bool) (v T, err error) {
+	v, ok := c.Find(f)
+	if !ok {
+		return v, ErrNoElement
+	}
+
+	return
+}
+
 // Select transforms each element in the collection using the selector function
 func (c *Collection[T]) Select(f func(x T) any) *Collection[any] {
 	return Select(c, f)
 }
 
+// SelectIndexed transforms each element in the collection using the selector function, which is given each
+// element's position in the source collection
+func (c *Collection[T]) SelectIndexed(f func(i int, x T) any) *Collection[any] {
+	return SelectIndexed(c, f)
+}
+
+// SelectSafe transforms each element in the collection using the selector function, skipping elements for which
+// the selector returns false
+func (c *Collection[T]) SelectSafe(f func(x T) (any, bool)) *Collection[any] {
+	return SelectSafe(c, f)
+}
+
+// SelectOrZero transforms each element in the collection using the selector function, substituting the zero value
+// on error
+func (c *Collection[T]) SelectOrZero(f func(x T) (any, error)) *Fallible[any] {
+	return SelectOrZero(c, f)
+}
+
+// SelectOrError eagerly transforms each element in the collection using the selector function, stopping at the
+// first error, which is returned wrapped with the index of the failing element
+func (c *Collection[T]) SelectOrError(f func(x T) (any, error)) (*Collection[any], error) {
+	return SelectOrError(c, f)
+}
+
 // SelectMany projects each element of the collection to a new collection and flattens the resulting collections into one
 func (c *Collection[T]) SelectMany(f func(x T) *Collection[any]) *Collection[any] {
 	return SelectMany(c, f)
@@ -163,6 +688,20 @@ func (c *Collection[T]) FirstOrError() (first T, err error) {
 	return
 }
 
+// FirstWhere returns the first element satisfying the predicate and a boolean indicating if an element was found
+func (c *Collection[T]) FirstWhere(f func(x T) bool) (first T, ok bool) {
+	return c.Find(f)
+}
+
+// FirstOr returns the first element in the collection, or def if the collection is empty
+func (c *Collection[T]) FirstOr(def T) T {
+	if first, ok := c.First(); ok {
+		return first
+	}
+
+	return def
+}
+
 // Last returns the last element in the collection and a boolean indicating if an element was found
 func (c *Collection[T]) Last() (last T, ok bool) {
 	for t := range *c {
@@ -211,6 +750,35 @@ func (c *Collection[T]) SingleOrError() (element T, err error) {
 	return
 }
 
+// SingleWhere returns the only element satisfying the predicate and a boolean indicating if exactly one element
+// matched. Enumeration stops as soon as a second match is seen
+func (c *Collection[T]) SingleWhere(f func(x T) bool) (element T, ok bool) {
+	element, err := c.SingleWhereOrError(f)
+	return element, err == nil
+}
+
+// SingleWhereOrError returns the only element satisfying the predicate, ErrNoElement if none match or
+// ErrNotExactlyOneElement if more than one matches. Enumeration stops as soon as a second match is seen
+func (c *Collection[T]) SingleWhereOrError(f func(x T) bool) (element T, err error) {
+	found := false
+	for v := range *c {
+		if !f(v) {
+			continue
+		}
+		if found {
+			var zero T
+			return zero, ErrNotExactlyOneElement
+		}
+		element, found = v, true
+	}
+
+	if !found {
+		return element, ErrNoElement
+	}
+
+	return
+}
+
 // Len returns the number of elements in the collection
 func (c *Collection[T]) Len() int {
 	count := 0
@@ -242,14 +810,46 @@ func (c *Collection[T]) IsEmpty() bool {
 	return true
 }
 
+// Shuffle returns a collection containing the elements in random order, using the global math/rand source
 func (c *Collection[T]) Shuffle() *Collection[T] {
+	return c.ShuffleWithSource(nil)
+}
+
+// ShuffleWithSource returns a collection containing the elements in an order determined by r, so the same seed
+// always produces the same permutation. If r is nil the global math/rand source is used
+func (c *Collection[T]) ShuffleWithSource(r *rand.Rand) *Collection[T] {
+	shuffle := rand.Shuffle
+	if r != nil {
+		shuffle = r.Shuffle
+	}
+
 	slice := c.ToSlice()
-	rand.Shuffle(len(slice), func(i, j int) {
+	shuffle(len(slice), func(i, j int) {
 		slice[i], slice[j] = slice[j], slice[i]
 	})
 	return NewFromSlice(slice)
 }
 
+// ShuffleCrypto returns a collection containing the elements in an order drawn from crypto/rand, for shuffles that
+// must not be predictable. Returns an error if the entropy source fails
+func (c *Collection[T]) ShuffleCrypto() (*Collection[T], error) {
+	return c.ShuffleCryptoWithReader(cryptorand.Reader)
+}
+
+// ShuffleCryptoWithReader is like ShuffleCrypto but draws randomness from r. Returns an error if reading from r fails
+func (c *Collection[T]) ShuffleCryptoWithReader(r io.Reader) (*Collection[T], error) {
+	slice := c.ToSlice()
+	for i := len(slice) - 1; i > 0; i-- {
+		j, err := cryptorand.Int(r, big.NewInt(int64(i+1)))
+		if err != nil {
+			return nil, err
+		}
+		k := int(j.Int64())
+		slice[i], slice[k] = slice[k], slice[i]
+	}
+	return NewFromSlice(slice), nil
+}
+
 // Distinct returns a collection containing only distinct elements based on the provided equality function
 func (c *Collection[T]) Distinct(equals func(a, b T) bool) *Collection[T] {
 	return New[T](iter.Seq[T](func(yield func(T) bool) {
@@ -272,6 +872,25 @@ func (c *Collection[T]) Distinct(equals func(a, b T) bool) *Collection[T] {
 	}))
 }
 
+// DedupConsecutive lazily collapses each run of adjacent equal elements to its first element, like uniq. Unlike
+// Distinct only the previous element is retained, so duplicates which are not adjacent are kept
+func (c *Collection[T]) DedupConsecutive(equals func(a, b T) bool) *Collection[T] {
+	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		var prev T
+		first := true
+		for v := range *c {
+			if !first && equals(prev, v) {
+				continue
+			}
+			first = false
+			prev = v
+			if !yield(v) {
+				return
+			}
+		}
+	}))
+}
+
 // Skip returns a collection that skips the first n elements
 func (c *Collection[T]) Skip(n int) *Collection[T] {
 	return New[T](iter.Seq[T](func(yield func(T) bool) {
@@ -316,33 +935,255 @@ func (c *Collection[T]) SkipWhile(f func(x T) bool) *Collection[T] {
 	}))
 }
 
-// SkipLast returns a collection that skips the last n elements
+// SkipLast returns a collection that skips the last n elements, buffering at most n elements at a time
 func (c *Collection[T]) SkipLast(n int) *Collection[T] {
 	return New[T](iter.Seq[T](func(yield func(T) bool) {
-		slice := c.ToSlice()
-		for i := range len(slice) - n {
-			if !yield(slice[i]) {
-				return
+		if n <= 0 {
+			for v := range *c {
+				if !yield(v) {
+					return
+				}
+			}
+			return
+		}
+
+		buffer := make([]T, 0, n)
+		next := 0
+		for v := range *c {
+			if len(buffer) < n {
+				buffer = append(buffer, v)
+				continue
+			}
+
+			oldest := buffer[next]
+			buffer[next] = v
+			next = (next + 1) % n
+			if !yield(oldest) {
+				return
+			}
+		}
+	}))
+}
+
+// SplitAt returns the first n elements and the remaining elements, enumerating the source only once. The head is
+// buffered when either collection is first enumerated, so the tail can be read before the head. The first
+// enumeration of the tail streams the rest of that pass over the source, which is held open until it has been
+// enumerated (or broken out of); use SplitAtStop to release it without enumerating the tail. Later enumerations of
+// the tail enumerate the source again, skipping the head
+func (c *Collection[T]) SplitAt(n int) (*Collection[T], *Collection[T]) {
+	head, tail, _ := c.SplitAtStop(n)
+	return head, tail
+}
+
+// SplitAtStop is SplitAt, also returning a function which stops the pass over the source if it is held open, such
+// as when the tail is abandoned. It waits for any read from the source in progress. Once it has been called, the
+// head yields only the elements it has already buffered and the tail yields nothing
+func (c *Collection[T]) SplitAtStop(n int) (*Collection[T], *Collection[T], func()) {
+	s := &splitSource[T]{source: c, limit: max(n, 0), inHead: func(T) bool { return true }}
+	return s.headCollection(), s.tailCollection(), s.release
+}
+
+// Span returns the longest prefix of elements satisfying f and the remaining elements, enumerating the source
+// and applying f only once per element. As with SplitAt the prefix is buffered, so either collection can be read
+// first, and later enumerations of the remainder enumerate the source again, skipping the prefix. Use SpanStop to
+// release the source without enumerating the remainder
+func (c *Collection[T]) Span(f func(T) bool) (*Collection[T], *Collection[T]) {
+	prefix, rest, _ := c.SpanStop(f)
+	return prefix, rest
+}
+
+// SpanStop is Span, also returning a function which stops the pass over the source if it is held open, as
+// SplitAtStop does
+func (c *Collection[T]) SpanStop(f func(T) bool) (*Collection[T], *Collection[T], func()) {
+	s := &splitSource[T]{source: c, limit: -1, inHead: f}
+	return s.headCollection(), s.tailCollection(), s.release
+}
+
+// splitSource shares a single pass over a source between a buffered head and a streamed tail. mu guards the pass,
+// which the tail continues after the head has been filled
+type splitSource[T any] struct {
+	source *Collection[T]
+	limit  int
+	inHead func(T) bool
+
+	once     sync.Once
+	mu       sync.Mutex
+	next     func() (T, bool)
+	stop     func()
+	head     []T
+	carry    T
+	hasCarry bool
+	streamed bool
+	stopped  bool
+	released bool
+}
+
+// fill buffers the head, pulling from the source until the limit is reached (if positive or zero), an element
+// fails inHead (which is carried over to the tail), or the source is exhausted
+func (s *splitSource[T]) fill() {
+	s.once.Do(func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if s.released {
+			return
+		}
+
+		s.next, s.stop = iter.Pull(iter.Seq[T](*s.source))
+		for s.limit < 0 || len(s.head) < s.limit {
+			v, ok := s.next()
+			if !ok {
+				s.stopPass()
+				return
+			}
+			if !s.inHead(v) {
+				s.carry, s.hasCarry = v, true
+				return
+			}
+			s.head = append(s.head, v)
+		}
+	})
+}
+
+// pull returns the next element of the pass, or false once it has been stopped
+func (s *splitSource[T]) pull() (v T, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return v, false
+	}
+	return s.next()
+}
+
+// stopPass stops the pass over the source if it is still open. s.mu must be held
+func (s *splitSource[T]) stopPass() {
+	if s.stop != nil && !s.stopped {
+		s.stop()
+	}
+	s.stopped = true
+}
+
+// release stops the pass over the source, after which the tail yields nothing
+func (s *splitSource[T]) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.released = true
+	s.stopPass()
+}
+
+func (s *splitSource[T]) headCollection() *Collection[T] {
+	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		s.fill()
+		for _, v := range s.head {
+			if !yield(v) {
+				return
+			}
+		}
+	}))
+}
+
+func (s *splitSource[T]) tailCollection() *Collection[T] {
+	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		s.fill()
+
+		s.mu.Lock()
+		if s.released {
+			s.mu.Unlock()
+			return
+		}
+		if s.streamed {
+			s.mu.Unlock()
+			s.replayTail(yield)
+			return
+		}
+		s.streamed = true
+		s.mu.Unlock()
+		defer func() {
+			s.mu.Lock()
+			s.stopPass()
+			s.mu.Unlock()
+		}()
+
+		if s.hasCarry && !yield(s.carry) {
+			return
+		}
+
+		for {
+			v, ok := s.pull()
+			if !ok || !yield(v) {
+				return
 			}
 		}
 	}))
 }
 
+// replayTail enumerates the source again, yielding the elements after the head
+func (s *splitSource[T]) replayTail(yield func(T) bool) {
+	skipped := 0
+	for v := range *s.source {
+		if skipped < len(s.head) {
+			skipped++
+			continue
+		}
+		if !yield(v) {
+			return
+		}
+	}
+}
+
 // Take returns a collection of only the first n elements
 func (c *Collection[T]) Take(n int) *Collection[T] {
 	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		TakeSeq(iter.Seq[T](*c), n)(yield)
+	}))
+}
+
+// TakeWithOverflow returns a collection of only the first n elements, and a function reporting how many further
+// elements were not taken. Once the first n elements have been yielded, a complete enumeration continues counting
+// the remaining elements without retaining them, so the source must be finite. If the most recent enumeration
+// stopped early, is still in progress, or there has been none, the overflow function counts by enumerating the
+// source again, which is only accurate for sources which can be enumerated more than once. The overflow function is
+// safe to call while the collection is being enumerated
+func (c *Collection[T]) TakeWithOverflow(n int) (*Collection[T], func() int) {
+	var mu sync.Mutex
+	overflow := 0
+	counted := false
+
+	taken := New[T](iter.Seq[T](func(yield func(T) bool) {
+		mu.Lock()
+		overflow, counted = 0, false
+		mu.Unlock()
+
 		count := 0
+		remaining := 0
 		for v := range *c {
 			if count < n {
 				if !yield(v) {
 					return
 				}
 				count++
-			} else {
-				return
+				continue
 			}
+			remaining++
 		}
+
+		mu.Lock()
+		overflow, counted = remaining, true
+		mu.Unlock()
 	}))
+
+	return taken, func() int {
+		mu.Lock()
+		value, ok := overflow, counted
+		mu.Unlock()
+
+		if !ok {
+			return c.Skip(n).Len()
+		}
+		return value
+	}
 }
 
 // TakeUntil returns a collection of elements until the predicate is satisfied
@@ -359,6 +1200,18 @@ func (c *Collection[T]) TakeUntil(f func(x T) bool) *Collection[T] {
 	}))
 }
 
+// TakeUntilInclusive returns a collection of elements up to and including the first element satisfying the
+// predicate, and stops enumerating the source after that element
+func (c *Collection[T]) TakeUntilInclusive(f func(x T) bool) *Collection[T] {
+	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		for v := range *c {
+			if !yield(v) || f(v) {
+				return
+			}
+		}
+	}))
+}
+
 func (c *Collection[T]) TakeWhile(f func(x T) bool) *Collection[T] {
 	return New[T](iter.Seq[T](func(yield func(T) bool) {
 		for v := range *c {
@@ -372,6 +1225,52 @@ func (c *Collection[T]) TakeWhile(f func(x T) bool) *Collection[T] {
 	}))
 }
 
+// Stride returns a collection of every n-th element, starting with the first. A stride of 1 returns every
+// element. Panics if n is not positive
+func (c *Collection[T]) Stride(n int) *Collection[T] {
+	return c.StrideFrom(0, n)
+}
+
+// StrideFrom returns a collection of every n-th element, starting with the element at offset. An offset of zero
+// or less starts at the first element. Panics if n is not positive
+func (c *Collection[T]) StrideFrom(offset, n int) *Collection[T] {
+	if n <= 0 {
+		panic("collection: stride must be positive")
+	}
+
+	offset = max(offset, 0)
+	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		i := 0
+		for v := range *c {
+			if i >= offset && (i-offset)%n == 0 && !yield(v) {
+				return
+			}
+			i++
+		}
+	}))
+}
+
+// SampleFraction returns a collection that lazily keeps each element independently with probability p, drawing
+// random numbers from r, or from the global math/rand source if r is nil. Panics if p is outside [0, 1]
+func (c *Collection[T]) SampleFraction(p float64, r *rand.Rand) *Collection[T] {
+	if !(p >= 0 && p <= 1) {
+		panic("collection: sample fraction must be between 0 and 1")
+	}
+
+	float64n := rand.Float64
+	if r != nil {
+		float64n = r.Float64
+	}
+
+	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		for v := range *c {
+			if float64n() < p && !yield(v) {
+				return
+			}
+		}
+	}))
+}
+
 // TakeLast returns a collection of only the last n elements
 func (c *Collection[T]) TakeLast(n int) *Collection[T] {
 	return New[T](iter.Seq[T](func(yield func(T) bool) {
@@ -470,18 +1369,24 @@ func (c *Collection[T]) OrderBy(f func(x T) any, ascending bool) *Collection[T]
 	return NewFromSlice(slice)
 }
 
-// Concat combines two collections into one
+// Concat combines two collections into one. Nil collections are treated as empty
 func (c *Collection[T]) Concat(other *Collection[T]) *Collection[T] {
+	return c.ConcatAll(other)
+}
+
+// ConcatAll combines the collection with each of others in order into one, without nesting a layer of Concat per
+// collection. Nil collections are treated as empty
+func (c *Collection[T]) ConcatAll(others ...*Collection[T]) *Collection[T] {
+	cs := append([]*Collection[T]{c}, others...)
 	return New[T](iter.Seq[T](func(yield func(T) bool) {
-		for v := range *c {
-			if !yield(v) {
-				return
+		for _, c := range cs {
+			if c == nil {
+				continue
 			}
-		}
-
-		for v := range *other {
-			if !yield(v) {
-				return
+			for v := range *c {
+				if !yield(v) {
+					return
+				}
 			}
 		}
 	}))
@@ -489,22 +1394,7 @@ func (c *Collection[T]) Concat(other *Collection[T]) *Collection[T] {
 
 // GroupBy groups elements by a key selector
 func (c *Collection[T]) GroupBy(keySelector func(x T) any) map[any]*Collection[T] {
-	groups := make(map[any]*Collection[T])
-
-	for v := range *c {
-		key := keySelector(v)
-		if group, exists := groups[key]; exists {
-			// Add to existing group
-			current := group.ToSlice()
-			current = append(current, v)
-			groups[key] = NewFromSlice(current)
-		} else {
-			// Create new group
-			groups[key] = NewFromSlice([]T{v})
-		}
-	}
-
-	return groups
+	return GroupByKey(c, keySelector)
 }
 
 // Union returns a collection of distinct elements from both collections
@@ -554,22 +1444,28 @@ func (c *Collection[T]) Except(other *Collection[T], equals func(a, b T) bool) *
 	}))
 }
 
-// Equals compares collection with another to determine if they are equal
+// Equals compares collection with another to determine if they are equal, advancing both in lockstep and stopping
+// at the first mismatch or difference in length without buffering either. Elements pulled before a mismatch are
+// consumed, so a single-use collection such as one backed by a channel cannot be enumerated again from the start
 func (c *Collection[T]) Equals(other *Collection[T], equals func(a, b T) bool) bool {
-	iter1 := c.ToSlice()
-	iter2 := other.ToSlice()
-
-	if len(iter1) != len(iter2) {
-		return false
-	}
-
-	for i := range iter1 {
-		if !equals(iter1[i], iter2[i]) {
+	next1, stop1 := iter.Pull(iter.Seq[T](*c))
+	defer stop1()
+	next2, stop2 := iter.Pull(iter.Seq[T](*other))
+	defer stop2()
+
+	for {
+		v1, ok1 := next1()
+		v2, ok2 := next2()
+		if ok1 != ok2 {
+			return false
+		}
+		if !ok1 {
+			return true
+		}
+		if !equals(v1, v2) {
 			return false
 		}
 	}
-
-	return true
 }
 
 // Reverse returns a collection with the elements in reverse order
@@ -607,6 +1503,75 @@ func (c *Collection[T]) Prepend(e T) *Collection[T] {
 	}))
 }
 
+// Insert lazily yields the elements with values spliced in before the element at index. If index is equal to or
+// beyond the number of elements the values are appended. Panics if index is negative
+func (c *Collection[T]) Insert(index int, values ...T) *Collection[T] {
+	if index < 0 {
+		panic("collection: insert index must not be negative")
+	}
+
+	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		i := 0
+		for v := range *c {
+			if i == index {
+				for _, value := range values {
+					if !yield(value) {
+						return
+					}
+				}
+			}
+			if !yield(v) {
+				return
+			}
+			i++
+		}
+		if i <= index {
+			for _, value := range values {
+				if !yield(value) {
+					return
+				}
+			}
+		}
+	}))
+}
+
+// RemoveAt lazily yields every element except the one at index. A negative or out of range index removes nothing
+func (c *Collection[T]) RemoveAt(index int) *Collection[T] {
+	return c.RemoveRange(index, 1)
+}
+
+// RemoveRange lazily yields every element except the count elements starting at start. A range extending past the
+// end removes the elements up to the end, and a negative start or a count less than one removes nothing
+func (c *Collection[T]) RemoveRange(start, count int) *Collection[T] {
+	if start < 0 || count < 1 {
+		start, count = 0, 0
+	}
+
+	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		i := 0
+		for v := range *c {
+			if (i < start || i-start >= count) && !yield(v) {
+				return
+			}
+			i++
+		}
+	}))
+}
+
+// ReplaceWhere lazily yields replacement in place of each element satisfying the predicate
+func (c *Collection[T]) ReplaceWhere(pred func(T) bool, replacement T) *Collection[T] {
+	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		for v := range *c {
+			if pred(v) {
+				v = replacement
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}))
+}
+
 // Chunk splits the collection into chunks of the specified size
 func (c *Collection[T]) Chunk(size int) []*Collection[T] {
 	chunks := make([]*Collection[T], 0)
@@ -629,11 +1594,13 @@ func (c *Collection[T]) Chunk(size int) []*Collection[T] {
 
 // Aggregate applies an accumulator function over collection
 func (c *Collection[T]) Aggregate(seed any, accumulator func(result any, item T) any) any {
-	result := seed
-	for item := range *c {
-		result = accumulator(result, item)
-	}
-	return result
+	return Fold(c, seed, accumulator)
+}
+
+// AggregateOrError applies an accumulator function over collection, stopping at the first error, which is
+// returned wrapped with the index of the failing element
+func (c *Collection[T]) AggregateOrError(seed any, accumulator func(result any, item T) (any, error)) (any, error) {
+	return FoldOrError(c, seed, accumulator)
 }
 
 // ForEach executes an action for each element in the collection
@@ -646,70 +1613,435 @@ func (c *Collection[T]) ForEach(action func(v T)) {
 // Each is an alias for ForEach
 func (c *Collection[T]) Each(action func(v T)) { c.ForEach(action) }
 
-// ParallelForEach executes an action for each element in the collection in parallel
-func (c *Collection[T]) ParallelForEach(ctx context.Context, action func(ctx context.Context, v T) error, concurrency int) error {
-	if concurrency <= 0 {
-		concurrency = runtime.NumCPU()
-	}
+// ForEachCtx executes an action for each element in the collection in turn, stopping at the first error from the
+// action or once the context is done, in which case the context's error is returned. The context is checked before
+// each element is read, so no further elements are read from the source once it is done
+func (c *Collection[T]) ForEachCtx(ctx context.Context, action func(ctx context.Context, v T) error) error {
+	next, stop := iter.Pull(iter.Seq[T](*c))
+	defer stop()
 
-	g, ctx := errgroup.WithContext(ctx)
-	g.SetLimit(concurrency)
-	for item := range *c {
-		currentItem := item
-		g.Go(func() error {
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			default:
-				return action(ctx, currentItem)
-			}
-		})
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		v, ok := next()
+		if !ok {
+			return nil
+		}
+		if err := action(ctx, v); err != nil {
+			return err
+		}
 	}
+}
 
-	return g.Wait()
+// Limiter paces actions, blocking in Wait until the next action may run or the context is done. It is satisfied
+// by *rate.Limiter from golang.org/x/time/rate
+type Limiter interface {
+	Wait(ctx context.Context) error
 }
 
-// Peek executes an action for each element in the collection and returns the collection
-func (c *Collection[T]) Peek(action func(T)) *Collection[T] {
-	return New[T](iter.Seq[T](func(yield func(T) bool) {
-		for v := range *c {
-			action(v)
-			if !yield(v) {
-				return
-			}
+// ForEachRate executes an action for each element in the collection in turn, waiting on the limiter before each
+// action. Stops at the first error from the limiter or action, or once the context is done
+func (c *Collection[T]) ForEachRate(ctx context.Context, action func(ctx context.Context, v T) error, limiter Limiter) error {
+	return c.ForEachCtx(ctx, func(ctx context.Context, v T) error {
+		if err := limiter.Wait(ctx); err != nil {
+			return err
 		}
-	}))
+		return action(ctx, v)
+	})
 }
 
-// ElementAt returns the element at the specified index or a default value if index is out of range
-func (c *Collection[T]) ElementAt(index int) (v T, ok bool) {
-	if index < 0 {
-		return
-	}
+// ParallelForEachRate executes an action for each element in the collection in parallel, with every worker
+// waiting on the shared limiter before each action
+func (c *Collection[T]) ParallelForEachRate(ctx context.Context, action func(ctx context.Context, v T) error, limiter Limiter, concurrency int) error {
+	return c.ParallelForEach(ctx, func(ctx context.Context, v T) error {
+		if err := limiter.Wait(ctx); err != nil {
+			return err
+		}
+		return action(ctx, v)
+	}, concurrency)
+}
 
-	count := 0
+// RetryForEach executes an action for each element in the collection in turn, retrying a failing action up to
+// attempts times in total and sleeping for backoff(attempt) after each failed attempt, where attempt starts at 1.
+// Elements which still fail do not stop the remaining elements; their errors are wrapped with the element index and
+// attempt count and returned joined. Stops once the context is done, including while sleeping
+func (c *Collection[T]) RetryForEach(ctx context.Context, action func(ctx context.Context, v T) error, attempts int, backoff func(attempt int) time.Duration) error {
+	return c.RetryForEachIf(ctx, action, attempts, backoff, func(error) bool { return true })
+}
+
+// RetryForEachIf behaves as RetryForEach, but only retries errors for which isRetryable returns true
+func (c *Collection[T]) RetryForEachIf(ctx context.Context, action func(ctx context.Context, v T) error, attempts int, backoff func(attempt int) time.Duration, isRetryable func(error) bool) error {
+	var errs []error
+	index := 0
 	for v := range *c {
-		if count == index {
-			return v, true
+		for attempt := 1; ; attempt++ {
+			if err := ctx.Err(); err != nil {
+				return errors.Join(append(errs, err)...)
+			}
+
+			err := action(ctx, v)
+			if err == nil {
+				break
+			}
+			if attempt >= attempts || !isRetryable(err) {
+				errs = append(errs, fmt.Errorf("element %d: failed after %d attempts: %w", index, attempt, err))
+				break
+			}
+
+			timer := time.NewTimer(backoff(attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return errors.Join(append(errs, ctx.Err())...)
+			case <-timer.C:
+			}
 		}
-		count++
+		index++
 	}
-	return
+	return errors.Join(errs...)
 }
 
-// ElementAtOrError returns the element at the specified index or an error if index is out of range
-func (c *Collection[T]) ElementAtOrError(index int) (T, error) {
-	val, ok := c.ElementAt(index)
-	if !ok {
-		return val, ErrIndexOutOfRange
-	}
-	return val, nil
+// ParallelForEachOptions configures ParallelForEachWithOptions
+type ParallelForEachOptions struct {
+	// Concurrency is the maximum number of actions run at once, defaulting to the number of CPUs if not positive
+	Concurrency int
+	// DisablePanicRecovery allows a panicking action to crash the process, rather than the panic being returned
+	// as a *PanicError
+	DisablePanicRecovery bool
+	// OnProgress is called after each action completes, successfully or not, with the number of completed actions
+	// and the total number of elements. The total is Total if positive, otherwise -1 until the source has been
+	// enumerated to the end. Calls are serialised, so OnProgress need not be safe for concurrent use, but should
+	// return quickly
+	OnProgress func(done, total int)
+	// Total is the number of elements in the collection, if known upfront, for reporting to OnProgress
+	Total int
+	// ItemTimeout limits each action if positive, by passing it a context with the timeout applied. An action
+	// which fails once its timeout expires is reported as an error wrapping context.DeadlineExceeded and the element
+	// index, and stops the remaining actions as any other error does. Actions must observe their context for the
+	// timeout to interrupt them
+	ItemTimeout time.Duration
+	// ContinueOnError runs every action even once one has failed, returning all errors joined in element order,
+	// each wrapped with its element index. By default the first error cancels the context passed to actions already
+	// running and no further actions are started
+	ContinueOnError bool
 }
 
-// Random returns a random element from the collection and true, or a default value and false if collection is empty
-func (c *Collection[T]) Random() (v T, ok bool) {
-	slice := c.ToSlice()
-	if len(slice) == 0 {
+// PanicError is returned by ParallelForEach when an action panics, holding the panic value and the stack trace of
+// the panicking goroutine
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v\n%s", e.Value, e.Stack)
+}
+
+// ParallelForEach executes an action for each element in the collection in parallel, stopping at the first error.
+// A panicking action is recovered and returned as a *PanicError, see ParallelForEachWithOptions
+func (c *Collection[T]) ParallelForEach(ctx context.Context, action func(ctx context.Context, v T) error, concurrency int) error {
+	return c.ParallelForEachWithOptions(ctx, action, ParallelForEachOptions{Concurrency: concurrency})
+}
+
+// ParallelForEachWithOptions executes an action for each element in the collection in parallel. The first error
+// cancels the context passed to actions already running and prevents further actions from starting, and is
+// returned once running actions finish, unless opts.ContinueOnError is set. A panicking action is treated as an
+// error, wrapped with the element index, unless opts.DisablePanicRecovery is set. Once ctx is done no further
+// elements are read from the source
+func (c *Collection[T]) ParallelForEachWithOptions(ctx context.Context, action func(ctx context.Context, v T) error, opts ParallelForEachOptions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+
+	var progressMu sync.Mutex
+	done, total := 0, -1
+	if opts.Total > 0 {
+		total = opts.Total
+	}
+
+	var errsMu sync.Mutex
+	var errs []Pair[int, error]
+	parent := ctx
+	g := &errgroup.Group{}
+	if !opts.ContinueOnError {
+		g, ctx = errgroup.WithContext(ctx)
+	}
+	g.SetLimit(concurrency)
+	index := 0
+	// stopped records that elements were left unstarted because the context was done
+	var stopped atomic.Bool
+	for item := range *c {
+		if ctx.Err() != nil {
+			stopped.Store(true)
+			break
+		}
+		currentIndex, currentItem := index, item
+		index++
+		g.Go(func() (err error) {
+			// Panics and timeouts always identify their element, and with ContinueOnError so does every error
+			annotate := opts.ContinueOnError
+			defer func() {
+				if err == nil {
+					return
+				}
+				if annotate {
+					err = fmt.Errorf("element %d: %w", currentIndex, err)
+				}
+				if opts.ContinueOnError {
+					errsMu.Lock()
+					defer errsMu.Unlock()
+					errs = append(errs, Pair[int, error]{First: currentIndex, Second: err})
+					err = nil
+				}
+			}()
+			if err := ctx.Err(); err != nil {
+				if opts.ContinueOnError {
+					stopped.Store(true)
+					return nil
+				}
+				return err
+			}
+
+			if opts.OnProgress != nil {
+				defer func() {
+					progressMu.Lock()
+					defer progressMu.Unlock()
+					done++
+					opts.OnProgress(done, total)
+				}()
+			}
+			if !opts.DisablePanicRecovery {
+				defer func() {
+					if r := recover(); r != nil {
+						annotate = true
+						err = &PanicError{Value: r, Stack: debug.Stack()}
+					}
+				}()
+			}
+			if opts.ItemTimeout <= 0 {
+				return action(ctx, currentItem)
+			}
+
+			itemCtx, cancel := context.WithTimeout(ctx, opts.ItemTimeout)
+			defer cancel()
+			err = action(itemCtx, currentItem)
+			if err != nil && ctx.Err() == nil && errors.Is(itemCtx.Err(), context.DeadlineExceeded) {
+				annotate = true
+				if errors.Is(err, context.DeadlineExceeded) {
+					return fmt.Errorf("timed out after %s: %w", opts.ItemTimeout, err)
+				}
+				return fmt.Errorf("timed out after %s: %w: %w", opts.ItemTimeout, context.DeadlineExceeded, err)
+			}
+			return err
+		})
+	}
+
+	if !stopped.Load() {
+		progressMu.Lock()
+		total = index
+		progressMu.Unlock()
+	}
+
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	slices.SortFunc(errs, func(a, b Pair[int, error]) int { return a.First - b.First })
+	result := Select(NewFromSlice(errs), func(p Pair[int, error]) error { return p.Second }).ToSlice()
+	if stopped.Load() {
+		result = append(result, parent.Err())
+	}
+	return errors.Join(result...)
+}
+
+// Peek executes an action for each element in the collection and returns the collection
+func (c *Collection[T]) Peek(action func(T)) *Collection[T] {
+	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		for v := range *c {
+			action(v)
+			if !yield(v) {
+				return
+			}
+		}
+	}))
+}
+
+// Prefetch returns a collection which enumerates the source in a goroutine, reading up to n elements ahead of the
+// consumer while preserving order. The goroutine is stopped when the result ends or is broken out of, once its
+// current element is received. If n is not positive nothing is read ahead, and the source is enumerated directly
+func (c *Collection[T]) Prefetch(n int) *Collection[T] {
+	if n <= 0 {
+		return New[T](iter.Seq[T](func(yield func(T) bool) {
+			for v := range *c {
+				if !yield(v) {
+					return
+				}
+			}
+		}))
+	}
+
+	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		for v := range c.ToChannelCtx(ctx, n) {
+			if !yield(v) {
+				return
+			}
+		}
+	}))
+}
+
+// Cache returns a collection which records elements as they are first enumerated from the source and replays them
+// on later enumerations, pulling from the source only as far as needed. This allows single-use sources such as
+// channels to be enumerated repeatedly. Concurrent enumerations are safe, and replaying buffered elements does not
+// wait on another enumeration pulling from the source. The source is held open until it has been enumerated to the
+// end; use CacheStop to release it sooner
+func (c *Collection[T]) Cache() *Collection[T] {
+	cached, _ := c.CacheStop()
+	return cached
+}
+
+// CacheStop is Cache, also returning a function which stops the source if it is held open, such as when no
+// enumeration has reached its end. It waits for any pull from the source in progress. Once it has been called, the
+// collection replays only the elements it has already buffered
+func (c *Collection[T]) CacheStop() (*Collection[T], func()) {
+	s := &cacheSource[T]{source: c}
+	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		for i := 0; ; i++ {
+			v, ok := s.at(i)
+			if !ok || !yield(v) {
+				return
+			}
+		}
+	})), s.release
+}
+
+// Cycle returns an infinite collection which repeats the elements of the source, intended to be bounded with
+// operations such as Take or TakeWhile. The source is enumerated once and cached, so single-use sources such as
+// channels repeat correctly. Cycle of an empty collection is empty
+func (c *Collection[T]) Cycle() *Collection[T] {
+	cached := c.Cache()
+	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		for {
+			empty := true
+			for v := range *cached {
+				empty = false
+				if !yield(v) {
+					return
+				}
+			}
+			if empty {
+				return
+			}
+		}
+	}))
+}
+
+// cacheSource lazily buffers the elements of a source for replay. mu guards the buffer and pullMu serialises pulls
+// from the source, so that replaying buffered elements never waits on a source which is slow to yield
+type cacheSource[T any] struct {
+	source *Collection[T]
+
+	pullMu sync.Mutex
+	next   func() (T, bool)
+	stop   func()
+
+	mu     sync.Mutex
+	buffer []T
+	done   bool
+}
+
+// at returns the element at index i, pulling from the source if it has not yet been buffered
+func (s *cacheSource[T]) at(i int) (v T, ok bool) {
+	if v, ok, buffered := s.buffered(i); buffered {
+		return v, ok
+	}
+
+	s.pullMu.Lock()
+	defer s.pullMu.Unlock()
+
+	for {
+		// Another enumeration may have pulled the element while this one waited
+		if v, ok, buffered := s.buffered(i); buffered {
+			return v, ok
+		}
+		if s.next == nil {
+			s.next, s.stop = iter.Pull(iter.Seq[T](*s.source))
+		}
+
+		v, ok := s.next()
+		s.mu.Lock()
+		if !ok {
+			s.done = true
+			s.stop()
+		} else {
+			s.buffer = append(s.buffer, v)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// release stops the source if it is held open, settling the buffer as it stands
+func (s *cacheSource[T]) release() {
+	s.pullMu.Lock()
+	defer s.pullMu.Unlock()
+
+	if s.stop != nil {
+		s.stop()
+	}
+
+	s.mu.Lock()
+	s.done = true
+	s.mu.Unlock()
+}
+
+// buffered returns the element at index i if it has been buffered. The last result reports whether i is settled
+// without pulling from the source, which it is once buffered or once the source has ended
+func (s *cacheSource[T]) buffered(i int) (v T, ok bool, buffered bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if i < len(s.buffer) {
+		return s.buffer[i], true, true
+	}
+	return v, false, s.done
+}
+
+// ElementAt returns the element at the specified index or a default value if index is out of range
+func (c *Collection[T]) ElementAt(index int) (v T, ok bool) {
+	if index < 0 {
+		return
+	}
+
+	count := 0
+	for v := range *c {
+		if count == index {
+			return v, true
+		}
+		count++
+	}
+	return
+}
+
+// ElementAtOrError returns the element at the specified index or an error if index is out of range
+func (c *Collection[T]) ElementAtOrError(index int) (T, error) {
+	val, ok := c.ElementAt(index)
+	if !ok {
+		return val, ErrIndexOutOfRange
+	}
+	return val, nil
+}
+
+// Random returns a random element from the collection and true, or a default value and false if collection is empty
+func (c *Collection[T]) Random() (v T, ok bool) {
+	slice := c.ToSlice()
+	if len(slice) == 0 {
 		return
 	}
 
@@ -721,22 +2053,26 @@ func (c *Collection[T]) Random() (v T, ok bool) {
 	return slice[i.Int64()], true
 }
 
-// RandomN returns n random elements from the collection and true, or an empty slice and false if not enough elements
+// RandomN returns n distinct elements chosen uniformly at random from the collection and true, or an empty slice and
+// false if n is not positive or exceeds the number of elements. Elements are sampled without replacement, so no
+// element is returned more than once
 func (c *Collection[T]) RandomN(n int) (v []T, ok bool) {
 	slice := c.ToSlice()
-	if n <= 1 || len(slice) < n {
+	if n <= 0 || len(slice) < n {
 		return
 	}
 
-	for range n {
-		i, err := cryptorand.Int(cryptorand.Reader, big.NewInt(int64(len(slice))))
+	// Partial Fisher-Yates: only the first n positions need to be settled
+	for i := range n {
+		j, err := cryptorand.Int(cryptorand.Reader, big.NewInt(int64(len(slice)-i)))
 		if err != nil {
-			return
+			return nil, false
 		}
-		v = append(v, slice[i.Int64()])
+		k := i + int(j.Int64())
+		slice[i], slice[k] = slice[k], slice[i]
 	}
 
-	return v, true
+	return slice[:n:n], true
 }
 
 // IndexOf returns the index of the first element that satisfies the predicate
@@ -751,6 +2087,19 @@ func (c *Collection[T]) IndexOf(predicate func(x T) bool) int {
 	return -1
 }
 
+// LastIndexOf returns the index of the last element that satisfies the predicate, or -1 if none do
+func (c *Collection[T]) LastIndexOf(predicate func(x T) bool) int {
+	last := -1
+	index := 0
+	for item := range *c {
+		if predicate(item) {
+			last = index
+		}
+		index++
+	}
+	return last
+}
+
 // Partition divides the collection into two collections based on a predicate function.
 // The first collection contains elements that satisfy the predicate, the second contains elements that don't.
 func (c *Collection[T]) Partition(predicate func(x T) bool) (*Collection[T], *Collection[T]) {
@@ -776,6 +2125,77 @@ func (c *Collection[T]) Partition(predicate func(x T) bool) (*Collection[T], *Co
 	return NewFromSlice(matches), NewFromSlice(nonMatches)
 }
 
+// EvictOlderThan removes elements with a timestamp before the cutoff, returning the kept elements
+// and the number of elements evicted. Elements with a timestamp equal to the cutoff are kept
+func (c *Collection[T]) EvictOlderThan(timestamp func(x T) time.Time, cutoff time.Time) (kept *Collection[T], evicted int) {
+	var values []T
+	for v := range *c {
+		if timestamp(v).Before(cutoff) {
+			evicted++
+			continue
+		}
+		values = append(values, v)
+	}
+
+	return NewFromSlice(values), evicted
+}
+
+// IntervalCount is the number of elements within the interval beginning at Start
+type IntervalCount struct {
+	Start time.Time
+	Count int
+}
+
+// CountPerInterval counts the elements within each interval of the given width, with intervals aligned using
+// time.Truncate. Elements must be ordered by timestamp, otherwise an error wrapping ErrNotOrdered is returned, and
+// the width must be positive, otherwise an error wrapping ErrInvalidInterval is returned. If fillGaps is true,
+// intervals containing no elements between the first and last are included with a zero count
+func (c *Collection[T]) CountPerInterval(timestamp func(x T) time.Time, width time.Duration, fillGaps bool) (*Collection[IntervalCount], error) {
+	if width <= 0 {
+		return nil, fmt.Errorf("%w: width %s is not positive", ErrInvalidInterval, width)
+	}
+
+	var counts []IntervalCount
+	var last time.Time
+	for v := range *c {
+		ts := timestamp(v)
+		if len(counts) > 0 && ts.Before(last) {
+			return nil, fmt.Errorf("%w: %s is before %s", ErrNotOrdered, ts, last)
+		}
+		last = ts
+
+		start := ts.Truncate(width)
+		if len(counts) > 0 {
+			current := &counts[len(counts)-1]
+			if current.Start.Equal(start) {
+				current.Count++
+				continue
+			}
+			if fillGaps {
+				for gap := current.Start.Add(width); gap.Before(start); gap = gap.Add(width) {
+					counts = append(counts, IntervalCount{Start: gap})
+				}
+			}
+		}
+		counts = append(counts, IntervalCount{Start: start, Count: 1})
+	}
+
+	return NewFromSlice(counts), nil
+}
+
+// Enumerate returns an iterator of each element paired with its zero-based position, for use with range
+func (c *Collection[T]) Enumerate() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		i := 0
+		for v := range *c {
+			if !yield(i, v) {
+				return
+			}
+			i++
+		}
+	}
+}
+
 // ToSlice converts the collection to a slice
 func (c *Collection[T]) ToSlice() []T {
 	var val []T
@@ -790,7 +2210,8 @@ func (c *Collection[T]) ToMap(keySelector func(x T) any) map[any]T {
 	return ToMap(c, keySelector)
 }
 
-// ToChannel converts the collection to a channel
+// ToChannel converts the collection to a channel. The channel must be read until it is closed, otherwise the
+// producing goroutine blocks forever; use ToChannelStop or ToChannelCtx when the reader may stop early
 func (c *Collection[T]) ToChannel() <-chan T {
 	ch := make(chan T)
 	go func() {
@@ -802,143 +2223,2027 @@ func (c *Collection[T]) ToChannel() <-chan T {
 	return ch
 }
 
+// ToChannelCtx converts the collection to a channel with the given buffer size. The channel is closed once the
+// collection has been enumerated or the context is done, at which point the producing goroutine exits and stops
+// enumerating the collection
+func (c *Collection[T]) ToChannelCtx(ctx context.Context, buffer int) <-chan T {
+	ch := make(chan T, buffer)
+	go func() {
+		defer close(ch)
+		for item := range *c {
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- item:
+			}
+		}
+	}()
+	return ch
+}
+
+// ToChannelStop converts the collection to a channel, returning a function which stops the producing goroutine
+// and closes the channel. The stop function must be called if the channel is not read until it is closed, and may
+// be called more than once
+func (c *Collection[T]) ToChannelStop() (<-chan T, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	return c.ToChannelCtx(ctx, 0), cancel
+}
+
+// FanOut distributes the elements of the collection across n channels, each with the given buffer size. Each
+// element is delivered to exactly one channel: whichever is next available to receive it, so a slow consumer does
+// not hold up the others. All channels are closed once the collection has been enumerated or the context is done;
+// the context must be cancelled if the channels are not all read until closed. Panics if n is not positive
+func (c *Collection[T]) FanOut(ctx context.Context, n int, buffer int) []<-chan T {
+	if n <= 0 {
+		panic("collection: fan out count must be positive")
+	}
+
+	source := c.ToChannelCtx(ctx, 0)
+	outs := make([]<-chan T, n)
+	for i := range outs {
+		out := make(chan T, buffer)
+		outs[i] = out
+		go func() {
+			defer close(out)
+			for v := range source {
+				select {
+				case <-ctx.Done():
+					return
+				case out <- v:
+				}
+			}
+		}()
+	}
+	return outs
+}
+
 // ToJSON serializes the collection to JSON
 func (c *Collection[T]) ToJSON() ([]byte, error) {
 	return json.Marshal(c.ToSlice())
 }
 
-// Pop removes the last element from collection and returns it
-func (c *Collection[T]) Pop() (v T, err error) {
-	s := c.ToSlice()
-	if len(s) == 0 {
-		return v, ErrEmptyCollection
+// WriteJSON streams the collection to w as a JSON array, encoding one element at a time with a json.Encoder
+func (c *Collection[T]) WriteJSON(w io.Writer) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
 	}
-	last := s[len(s)-1]
-	*c = *NewFromSlice(s[:len(s)-1])
-	return last, nil
+
+	encoder := json.NewEncoder(w)
+	i := 0
+	for v := range *c {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if err := encoder.Encode(v); err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+		i++
+	}
+
+	_, err := io.WriteString(w, "]")
+	return err
 }
 
-// Shift removes the first element from collection and returns it
-func (c *Collection[T]) Shift() (v T, err error) {
-	s := c.ToSlice()
-	if len(s) == 0 {
-		return v, ErrEmptyCollection
+// WriteNDJSON streams the collection to w as newline-delimited JSON, writing each element on its own line
+func (c *Collection[T]) WriteNDJSON(w io.Writer) error {
+	i := 0
+	for v := range *c {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+		if _, err := w.Write(append(data, '\n')); err != nil {
+			return err
+		}
+		i++
 	}
-	first := s[0]
-	*c = *NewFromSlice(s[1:])
-	return first, nil
+	return nil
 }
 
-// Zip combines two collections into one by applying a function pairwise
-func Zip[T1, T2, TResult any](c1 *Collection[T1], c2 *Collection[T2], zipper func(T1, T2) TResult) *Collection[TResult] {
-	return New[TResult](iter.Seq[TResult](func(yield func(TResult) bool) {
-		iter1 := make(chan T1)
-		iter2 := make(chan T2)
+// EncodeGob streams the collection to w with encoding/gob, one element at a time, for reading back with NewFromGob
+func (c *Collection[T]) EncodeGob(w io.Writer) error {
+	encoder := gob.NewEncoder(w)
+	i := 0
+	for v := range *c {
+		if err := encoder.Encode(true); err != nil {
+			return err
+		}
+		if err := encoder.Encode(v); err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+		i++
+	}
+	return encoder.Encode(false)
+}
+
+// GobEncode implements gob.GobEncoder, encoding the elements as a slice. encoding/gob never sends struct fields of
+// function type or pointers to them, so a collection held in a struct field must be wrapped in a GobCollection
+func (c *Collection[T]) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(c.ToSlice()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode implements gob.GobDecoder, replacing the collection with the elements encoded by GobEncode
+func (c *Collection[T]) GobDecode(data []byte) error {
+	var items []T
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&items); err != nil {
+		return err
+	}
+	*c = *NewFromSlice(items)
+	return nil
+}
+
+// GobCollection holds a collection in a struct field which encoding/gob sends, encoding it with GobEncode. Like
+// other zero values, a GobCollection holding no collection is left out of the encoded struct and decodes as nil
+type GobCollection[T any] struct {
+	*Collection[T]
+}
+
+// GobEncode implements gob.GobEncoder
+func (g GobCollection[T]) GobEncode() ([]byte, error) {
+	if g.Collection == nil {
+		return Empty[T]().GobEncode()
+	}
+	return g.Collection.GobEncode()
+}
+
+// GobDecode implements gob.GobDecoder, replacing the held collection with the decoded elements
+func (g *GobCollection[T]) GobDecode(data []byte) error {
+	c := Empty[T]()
+	if err := c.GobDecode(data); err != nil {
+		return err
+	}
+	g.Collection = c
+	return nil
+}
+
+// Snapshot writes the collection to w in the versioned snapshot format, encoding each element with encode.
+// The format is the magic bytes "GCOL", a big-endian uint16 version, a big-endian uint64 element count, then for
+// each element a big-endian uint32 length followed by the encoded bytes. The collection is materialized to
+// determine the element count
+func (c *Collection[T]) Snapshot(w io.Writer, encode func(T) ([]byte, error)) error {
+	slice := c.ToSlice()
+
+	header := make([]byte, 0, snapshotHeaderSize)
+	header = append(header, snapshotMagic...)
+	header = binary.BigEndian.AppendUint16(header, snapshotVersion)
+	header = binary.BigEndian.AppendUint64(header, uint64(len(slice)))
+	if _, err := w.Write(header); err != nil {
+		return fmt.Errorf("failed to write snapshot header: %w", err)
+	}
+
+	for i, v := range slice {
+		data, err := encode(v)
+		if err != nil {
+			return fmt.Errorf("failed to encode element %d: %w", i, err)
+		}
+		if uint64(len(data)) > math.MaxUint32 {
+			return fmt.Errorf("failed to encode element %d: encoded length %d exceeds maximum", i, len(data))
+		}
+
+		frame := binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(data)), uint32(len(data)))
+		frame = append(frame, data...)
+		if _, err := w.Write(frame); err != nil {
+			return fmt.Errorf("failed to write element %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// ToWriter renders each element with render and writes the bytes to w in the order of the collection, returning
+// the number of bytes written. It stops at the first render or write error, which is wrapped with the index of
+// the element
+func (c *Collection[T]) ToWriter(w io.Writer, render func(T) ([]byte, error)) (int64, error) {
+	var written int64
+	i := 0
+	for v := range *c {
+		data, err := render(v)
+		if err != nil {
+			return written, fmt.Errorf("failed to render element %d: %w", i, err)
+		}
+		n, err := w.Write(data)
+		written += int64(n)
+		if err != nil {
+			return written, fmt.Errorf("failed to write element %d: %w", i, err)
+		}
+		i++
+	}
+	return written, nil
+}
+
+// ToWriterParallel renders elements concurrently using up to workers goroutines, writing the rendered bytes to w
+// strictly in the order of the collection. Rendering runs at most workers elements ahead of writing, bounding the
+// number of rendered elements buffered for reordering. The first render or write error stops further elements from
+// being dispatched and is returned once in-flight renders have finished
+func (c *Collection[T]) ToWriterParallel(ctx context.Context, w io.Writer, render func(T) ([]byte, error), workers int) error {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	dispatchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type rendered struct {
+		data []byte
+		err  error
+	}
+
+	// pending holds the result channel of each dispatched element in input order, and slots limits concurrent renders
+	pending := make(chan chan rendered, workers)
+	slots := make(chan struct{}, workers)
+
+	var wg sync.WaitGroup
+	stopped := false
+	dispatched := make(chan struct{})
+	go func() {
+		defer close(dispatched)
+		defer close(pending)
+		for v := range *c {
+			select {
+			case slots <- struct{}{}:
+			case <-dispatchCtx.Done():
+				stopped = true
+				return
+			}
+
+			result := make(chan rendered, 1)
+			select {
+			case pending <- result:
+			case <-dispatchCtx.Done():
+				<-slots
+				stopped = true
+				return
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				data, err := render(v)
+				<-slots
+				result <- rendered{data: data, err: err}
+			}()
+		}
+	}()
+
+	var err error
+	index := 0
+	for result := range pending {
+		r := <-result
+		if err == nil {
+			if r.err != nil {
+				err = fmt.Errorf("failed to render element %d: %w", index, r.err)
+			} else if _, writeErr := w.Write(r.data); writeErr != nil {
+				err = fmt.Errorf("failed to write element %d: %w", index, writeErr)
+			}
+			if err != nil {
+				cancel()
+			}
+		}
+		index++
+	}
+
+	<-dispatched
+	wg.Wait()
+
+	if err != nil {
+		return err
+	}
+	if stopped {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// Pop removes the last element from collection and returns it
+func (c *Collection[T]) Pop() (v T, err error) {
+	s := c.ToSlice()
+	if len(s) == 0 {
+		return v, ErrEmptyCollection
+	}
+	last := s[len(s)-1]
+	*c = *NewFromSlice(s[:len(s)-1])
+	return last, nil
+}
+
+// Shift removes the first element from collection and returns it
+func (c *Collection[T]) Shift() (v T, err error) {
+	s := c.ToSlice()
+	if len(s) == 0 {
+		return v, ErrEmptyCollection
+	}
+	first := s[0]
+	*c = *NewFromSlice(s[1:])
+	return first, nil
+}
+
+// RemoveWhere removes every element satisfying the predicate from the collection in place, returning the number of
+// elements removed. Unlike Reject the collection is materialized and replaced
+func (c *Collection[T]) RemoveWhere(pred func(T) bool) (removed int) {
+	s := c.ToSlice()
+	n := len(s)
+	kept := slices.DeleteFunc(s, pred)
+	*c = *NewFromSlice(kept)
+	return n - len(kept)
+}
+
+// MapInPlace applies f to every element, replacing the collection in place with the results. Unlike Select the
+// collection is materialized and replaced
+func (c *Collection[T]) MapInPlace(f func(T) T) {
+	s := c.ToSlice()
+	for i, v := range s {
+		s[i] = f(v)
+	}
+	*c = *NewFromSlice(s)
+}
+
+const (
+	snapshotMagic      = "GCOL"
+	snapshotVersion    = 1
+	snapshotHeaderSize = len(snapshotMagic) + 2 + 8
+)
+
+// RestoreSnapshot validates the header of a snapshot written by Snapshot, then lazily decodes its elements with decode.
+// Elements are read from r as they are enumerated, so the result can only be enumerated once
+func RestoreSnapshot[T any](r io.Reader, decode func([]byte) (T, error)) (*Fallible[T], error) {
+	header := make([]byte, snapshotHeaderSize)
+	if n, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("%w: truncated header at offset %d: %w", ErrCorruptSnapshot, n, err)
+	}
+	if string(header[:len(snapshotMagic)]) != snapshotMagic {
+		return nil, fmt.Errorf("%w: invalid magic at offset 0", ErrCorruptSnapshot)
+	}
+	if version := binary.BigEndian.Uint16(header[len(snapshotMagic):]); version != snapshotVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedSnapshotVersion, version)
+	}
+	count := binary.BigEndian.Uint64(header[len(snapshotMagic)+2:])
+
+	return newSingleUseFallible(func(yield func(T) bool) error {
+		offset := int64(snapshotHeaderSize)
+		length := make([]byte, 4)
+		for i := uint64(0); i < count; i++ {
+			if n, err := io.ReadFull(r, length); err != nil {
+				return fmt.Errorf("%w: truncated length of element %d at offset %d: %w", ErrCorruptSnapshot, i, offset+int64(n), err)
+			}
+			offset += 4
+
+			// Read through a limit rather than allocating upfront, so a corrupt length can't force a huge allocation
+			size := int(binary.BigEndian.Uint32(length))
+			data, err := io.ReadAll(io.LimitReader(r, int64(size)))
+			if err == nil && len(data) < size {
+				err = io.ErrUnexpectedEOF
+			}
+			if err != nil {
+				return fmt.Errorf("%w: truncated element %d at offset %d: %w", ErrCorruptSnapshot, i, offset+int64(len(data)), err)
+			}
+
+			v, err := decode(data)
+			if err != nil {
+				return fmt.Errorf("%w: failed to decode element %d at offset %d: %w", ErrCorruptSnapshot, i, offset, err)
+			}
+			offset += int64(len(data))
+
+			if !yield(v) {
+				return nil
+			}
+		}
+		return nil
+	}), nil
+}
+
+// Zip combines two collections into one by applying a function pairwise
+func Zip[T1, T2, TResult any](c1 *Collection[T1], c2 *Collection[T2], zipper func(T1, T2) TResult) *Collection[TResult] {
+	return New[TResult](iter.Seq[TResult](func(yield func(TResult) bool) {
+		iter1 := make(chan T1)
+		iter2 := make(chan T2)
+
+		// Start goroutines to generate values
+		go func() {
+			defer close(iter1)
+			for v := range *c1 {
+				iter1 <- v
+			}
+		}()
+
+		go func() {
+			defer close(iter2)
+			for v := range *c2 {
+				iter2 <- v
+			}
+		}()
+
+		// Zip elements together
+		for {
+			v1, ok1 := <-iter1
+			if !ok1 {
+				break
+			}
+
+			v2, ok2 := <-iter2
+			if !ok2 {
+				break
+			}
+
+			if !yield(zipper(v1, v2)) {
+				return
+			}
+		}
+	}))
+}
+
+// Zip3 combines three collections into one by applying a function to the elements at each position, stopping at the
+// end of the shortest collection
+func Zip3[A, B, C, R any](a *Collection[A], b *Collection[B], c *Collection[C], f func(A, B, C) R) *Collection[R] {
+	return New[R](iter.Seq[R](func(yield func(R) bool) {
+		nextA, stopA := iter.Pull(iter.Seq[A](*a))
+		defer stopA()
+		nextB, stopB := iter.Pull(iter.Seq[B](*b))
+		defer stopB()
+		nextC, stopC := iter.Pull(iter.Seq[C](*c))
+		defer stopC()
+
+		for {
+			va, ok := nextA()
+			if !ok {
+				return
+			}
+			vb, ok := nextB()
+			if !ok {
+				return
+			}
+			vc, ok := nextC()
+			if !ok {
+				return
+			}
+			if !yield(f(va, vb, vc)) {
+				return
+			}
+		}
+	}))
+}
+
+// maxReportedKeys is the maximum number of keys listed in key mismatch errors
+const maxReportedKeys = 10
+
+// formatKeys formats keys for inclusion in an error, truncating the list after maxReportedKeys
+func formatKeys[K any](keys []K) string {
+	var sb strings.Builder
+	sb.WriteString("[")
+	for i, key := range keys {
+		if i == maxReportedKeys {
+			fmt.Fprintf(&sb, " ... and %d more", len(keys)-maxReportedKeys)
+			break
+		}
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		fmt.Fprint(&sb, key)
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
+
+// Interleave lazily yields one element from each collection in turn, continuing with the remaining collections as
+// shorter ones are exhausted. Unlike Concat the collections are read alternately, and unlike MergeConcurrent the
+// order is deterministic
+func Interleave[T any](cs ...*Collection[T]) *Collection[T] {
+	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		nexts := make([]func() (T, bool), 0, len(cs))
+		for _, c := range cs {
+			next, stop := iter.Pull(iter.Seq[T](*c))
+			defer stop()
+			nexts = append(nexts, next)
+		}
+
+		for len(nexts) > 0 {
+			active := nexts[:0]
+			for _, next := range nexts {
+				v, ok := next()
+				if !ok {
+					continue
+				}
+				if !yield(v) {
+					return
+				}
+				active = append(active, next)
+			}
+			nexts = active
+		}
+	}))
+}
+
+// RoundRobin lazily yields one element from each group per round, skipping groups once they are exhausted, for
+// distributing work fairly across groups such as those produced by GroupBy. It is equivalent to Interleave
+func RoundRobin[T any](groups []*Collection[T]) *Collection[T] {
+	return Interleave(groups...)
+}
+
+// ZipByKeyStrict pairs elements of two collections with matching keys, applying a function to each pair
+// in the order of the first collection. An error wrapping ErrDuplicateKey is returned if a key occurs
+// more than once in either collection, and an error wrapping ErrKeyMismatch listing the unmatched keys
+// is returned if either collection contains a key the other does not
+func ZipByKeyStrict[A, B any, K comparable, R any](a *Collection[A], b *Collection[B], ka func(A) K, kb func(B) K, f func(A, B) R) (*Collection[R], error) {
+	bIndex := make(map[K]B)
+	var bKeys []K
+	for v := range *b {
+		key := kb(v)
+		if _, exists := bIndex[key]; exists {
+			return nil, fmt.Errorf("%w in second collection: %v", ErrDuplicateKey, key)
+		}
+		bIndex[key] = v
+		bKeys = append(bKeys, key)
+	}
+
+	seen := make(map[K]struct{})
+	var missing []K
+	var results []R
+	for v := range *a {
+		key := ka(v)
+		if _, exists := seen[key]; exists {
+			return nil, fmt.Errorf("%w in first collection: %v", ErrDuplicateKey, key)
+		}
+		seen[key] = struct{}{}
+
+		match, ok := bIndex[key]
+		if !ok {
+			missing = append(missing, key)
+			continue
+		}
+		results = append(results, f(v, match))
+	}
+
+	var extra []K
+	for _, key := range bKeys {
+		if _, ok := seen[key]; !ok {
+			extra = append(extra, key)
+		}
+	}
+
+	if len(missing) > 0 || len(extra) > 0 {
+		return nil, fmt.Errorf("%w: keys only in first collection %s, keys only in second collection %s", ErrKeyMismatch, formatKeys(missing), formatKeys(extra))
+	}
+
+	return NewFromSlice(results), nil
+}
+
+// indexBy groups the elements of the collection by key, preserving encounter order within each key
+func indexBy[T any, K comparable](c *Collection[T], keySelector func(T) K) map[K][]T {
+	index := make(map[K][]T)
+	for v := range *c {
+		key := keySelector(v)
+		index[key] = append(index[key], v)
+	}
+	return index
+}
+
+// Join performs an inner join on two collections based on matching keys
+func Join[TOuter, TInner, TKey comparable, TResult any](outer *Collection[TOuter], inner *Collection[TInner], outerKeySelector func(TOuter) TKey, innerKeySelector func(TInner) TKey, resultSelector func(TOuter, TInner) TResult) *Collection[TResult] {
+	return New[TResult](iter.Seq[TResult](func(yield func(TResult) bool) {
+		index := indexBy(inner, innerKeySelector)
+
+		for outerItem := range *outer {
+			for _, innerItem := range index[outerKeySelector(outerItem)] {
+				if !yield(resultSelector(outerItem, innerItem)) {
+					return
+				}
+			}
+		}
+	}))
+}
+
+// LeftJoin performs a left outer join on two collections based on matching keys. Outer elements
+// without a matching inner element are passed to the result selector once, with the zero value
+// of TInner and matched set to false
+func LeftJoin[TOuter, TInner any, TKey comparable, TResult any](outer *Collection[TOuter], inner *Collection[TInner], outerKeySelector func(TOuter) TKey, innerKeySelector func(TInner) TKey, resultSelector func(outer TOuter, inner TInner, matched bool) TResult) *Collection[TResult] {
+	return New[TResult](iter.Seq[TResult](func(yield func(TResult) bool) {
+		index := indexBy(inner, innerKeySelector)
+
+		for outerItem := range *outer {
+			matches := index[outerKeySelector(outerItem)]
+			if len(matches) == 0 {
+				var innerItem TInner
+				if !yield(resultSelector(outerItem, innerItem, false)) {
+					return
+				}
+				continue
+			}
+
+			for _, innerItem := range matches {
+				if !yield(resultSelector(outerItem, innerItem, true)) {
+					return
+				}
+			}
+		}
+	}))
+}
+
+// ChunkSeq lazily splits the collection into chunks of the specified size, yielding each chunk as soon as it is
+// filled. The final chunk may contain fewer elements. Panics if size is not positive
+func ChunkSeq[T any](c *Collection[T], size int) *Collection[*Collection[T]] {
+	if size <= 0 {
+		panic("collection: chunk size must be positive")
+	}
+
+	return New[*Collection[T]](iter.Seq[*Collection[T]](func(yield func(*Collection[T]) bool) {
+		chunk := make([]T, 0, size)
+		for v := range *c {
+			chunk = append(chunk, v)
+			if len(chunk) == size {
+				if !yield(NewFromSlice(chunk)) {
+					return
+				}
+				chunk = make([]T, 0, size)
+			}
+		}
+
+		if len(chunk) > 0 {
+			yield(NewFromSlice(chunk))
+		}
+	}))
+}
+
+// Windowed lazily yields each overlapping window of n consecutive elements, for example [1,2], [2,3], [3,4]
+// for [1,2,3,4] with n of 2. Collections with fewer than n elements yield no windows. Panics if n is not positive
+func Windowed[T any](c *Collection[T], n int) *Collection[*Collection[T]] {
+	if n <= 0 {
+		panic("collection: window size must be positive")
+	}
+
+	return New[*Collection[T]](iter.Seq[*Collection[T]](func(yield func(*Collection[T]) bool) {
+		buffer := make([]T, n)
+		count := 0
+		for v := range *c {
+			buffer[count%n] = v
+			count++
+			if count < n {
+				continue
+			}
+
+			window := make([]T, 0, n)
+			start := count % n
+			window = append(window, buffer[start:]...)
+			window = append(window, buffer[:start]...)
+			if !yield(NewFromSlice(window)) {
+				return
+			}
+		}
+	}))
+}
+
+// Permutations lazily yields every ordering of the elements of c using Heap's algorithm, each as a new slice that
+// the caller may retain. The source is materialized when enumeration starts. A collection of n elements has n!
+// permutations, so large inputs should be bounded with Take or an early break. An empty collection yields a single
+// empty permutation
+func Permutations[T any](c *Collection[T]) *Collection[[]T] {
+	return New[[]T](iter.Seq[[]T](func(yield func([]T) bool) {
+		a := c.ToSlice()
+		if a == nil {
+			a = []T{}
+		}
+		if !yield(slices.Clone(a)) {
+			return
+		}
+
+		counters := make([]int, len(a))
+		for i := 1; i < len(a); {
+			if counters[i] >= i {
+				counters[i] = 0
+				i++
+				continue
+			}
+			if i%2 == 0 {
+				a[0], a[i] = a[i], a[0]
+			} else {
+				a[counters[i]], a[i] = a[i], a[counters[i]]
+			}
+			if !yield(slices.Clone(a)) {
+				return
+			}
+			counters[i]++
+			i = 1
+		}
+	}))
+}
+
+// Combinations lazily yields every k-element subset of the elements of c in lexicographic index order, each as a
+// new slice that the caller may retain. The source is materialized when enumeration starts. k == 0 yields a single
+// empty combination, and k greater than the number of elements or negative yields nothing
+func Combinations[T any](c *Collection[T], k int) *Collection[[]T] {
+	return New[[]T](iter.Seq[[]T](func(yield func([]T) bool) {
+		if k < 0 {
+			return
+		}
+		s := c.ToSlice()
+		n := len(s)
+		if k > n {
+			return
+		}
+
+		indices := make([]int, k)
+		for i := range indices {
+			indices[i] = i
+		}
+		for {
+			combination := make([]T, k)
+			for i, idx := range indices {
+				combination[i] = s[idx]
+			}
+			if !yield(combination) {
+				return
+			}
+
+			// Advance the rightmost index that has not reached its final position, then reset those after it
+			i := k - 1
+			for i >= 0 && indices[i] == n-k+i {
+				i--
+			}
+			if i < 0 {
+				return
+			}
+			indices[i]++
+			for j := i + 1; j < k; j++ {
+				indices[j] = indices[j-1] + 1
+			}
+		}
+	}))
+}
+
+// SplitWhen lazily splits the collection into segments at each element satisfying f, dropping the delimiter
+// elements as strings.Split does. Consecutive, leading and trailing delimiters produce empty segments, and an
+// empty collection yields no segments
+func SplitWhen[T any](c *Collection[T], f func(T) bool) *Collection[*Collection[T]] {
+	return New[*Collection[T]](iter.Seq[*Collection[T]](func(yield func(*Collection[T]) bool) {
+		var segment []T
+		seen := false
+		for v := range *c {
+			seen = true
+			if !f(v) {
+				segment = append(segment, v)
+				continue
+			}
+
+			if !yield(NewFromSlice(segment)) {
+				return
+			}
+			segment = nil
+		}
+
+		if seen {
+			yield(NewFromSlice(segment))
+		}
+	}))
+}
+
+// Tee returns two collections which each yield every element of the source, enumerating the source only once.
+// Elements read by one output are buffered until the other output reads them, so the buffer grows with the
+// distance between the two consumers. An output which is broken out of stops buffering, but an output which is
+// never enumerated buffers every element read by the other. Reading buffered elements never waits on the other
+// output pulling from the source. Each output may only be enumerated once, and the source is held open until both
+// outputs have finished; use TeeStop to release it when an output is abandoned
+func Tee[T any](c *Collection[T]) (*Collection[T], *Collection[T]) {
+	a, b, _ := TeeStop(c)
+	return a, b
+}
+
+// TeeStop is Tee, also returning a function which stops the source if it is held open, such as when an output is
+// abandoned without being enumerated. It waits for any pull from the source in progress. Once it has been called,
+// each output yields only the elements already buffered for it
+func TeeStop[T any](c *Collection[T]) (*Collection[T], *Collection[T], func()) {
+	s := &teeSource[T]{source: c}
+	return s.output(0), s.output(1), s.release
+}
+
+// teeSource shares a single pass over a source between two outputs, each with its own queue of pending elements.
+// mu guards the queues and pullMu serialises pulls from the source, so that an output with pending elements never
+// waits on a source which is slow to yield to the other
+type teeSource[T any] struct {
+	source *Collection[T]
+
+	pullMu sync.Mutex
+	next   func() (T, bool)
+	stop   func()
+
+	mu       sync.Mutex
+	queues   [2][]T
+	started  [2]bool
+	finished [2]bool
+	done     bool
+}
+
+func (s *teeSource[T]) output(i int) *Collection[T] {
+	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		s.mu.Lock()
+		started := s.started[i]
+		s.started[i] = true
+		s.mu.Unlock()
+		if started {
+			return
+		}
+		defer s.finish(i)
+
+		for {
+			v, ok := s.read(i)
+			if !ok || !yield(v) {
+				return
+			}
+		}
+	}))
+}
+
+// read returns the next element for output i, pulling from the source and queueing the element for the other
+// output if output i has no pending elements
+func (s *teeSource[T]) read(i int) (v T, ok bool) {
+	if v, ok, settled := s.queued(i); settled {
+		return v, ok
+	}
+
+	s.pullMu.Lock()
+	defer s.pullMu.Unlock()
+
+	// The other output may have pulled an element for this one while it waited
+	if v, ok, settled := s.queued(i); settled {
+		return v, ok
+	}
+	if s.next == nil {
+		s.next, s.stop = iter.Pull(iter.Seq[T](*s.source))
+	}
+
+	v, ok = s.next()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !ok {
+		s.done = true
+		return
+	}
+	if other := 1 - i; !s.finished[other] {
+		s.queues[other] = append(s.queues[other], v)
+	}
+	return v, true
+}
+
+// queued returns the next pending element for output i. The last result reports whether the read is settled
+// without pulling from the source, which it is once an element is pending or once the source has ended
+func (s *teeSource[T]) queued(i int) (v T, ok bool, settled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.queues[i]) > 0 {
+		v = s.queues[i][0]
+		s.queues[i] = s.queues[i][1:]
+		return v, true, true
+	}
+	return v, false, s.done
+}
+
+// finish releases output i's queue, stopping the source once both outputs have finished
+func (s *teeSource[T]) finish(i int) {
+	s.mu.Lock()
+	s.finished[i] = true
+	s.queues[i] = nil
+	both := s.finished[1-i]
+	s.mu.Unlock()
+
+	if both {
+		s.release()
+	}
+}
+
+// release stops the source if it is held open, leaving the pending elements of each output to be read
+func (s *teeSource[T]) release() {
+	s.pullMu.Lock()
+	defer s.pullMu.Unlock()
+
+	if s.stop != nil {
+		s.stop()
+	}
+
+	s.mu.Lock()
+	s.done = true
+	s.mu.Unlock()
+}
+
+// MergeConcurrent enumerates each collection in its own goroutine and yields elements in the order they arrive, so
+// the order of the result is nondeterministic. Breaking out of the result or the context being done signals all
+// producers to stop, which they do before sending their next element. A producer blocked inside its own source,
+// such as one receiving from an idle channel, cannot be interrupted and its goroutine remains until the source
+// yields or ends; use NewFromChannelCtx or NewFromChannels for such sources
+func MergeConcurrent[T any](ctx context.Context, cs ...*Collection[T]) *Collection[T] {
+	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		out := make(chan T)
+		var wg sync.WaitGroup
+		for _, c := range cs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for v := range *c {
+					select {
+					case <-ctx.Done():
+						return
+					case out <- v:
+					}
+				}
+			}()
+		}
+		go func() {
+			wg.Wait()
+			close(out)
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case v, ok := <-out:
+				if !ok || !yield(v) {
+					return
+				}
+			}
+		}
+	}))
+}
+
+// ParallelMap applies f to each element of the collection in parallel, returning a slice in which each result is
+// at the index of its source element. On error the slice has an entry for each element enumerated before
+// ParallelMap stopped, holding the result for elements which completed successfully and the zero value otherwise,
+// and the first error is returned wrapped with the index of the failing element
+func ParallelMap[T, E any](ctx context.Context, c *Collection[T], f func(ctx context.Context, v T) (E, error), concurrency int) ([]E, error) {
+	var mu sync.Mutex
+	var zero E
+	results := []E{}
+	indexed := SelectIndexed(c, func(i int, v T) Pair[int, T] {
+		mu.Lock()
+		results = append(results, zero)
+		mu.Unlock()
+		return Pair[int, T]{First: i, Second: v}
+	})
+
+	err := indexed.ParallelForEach(ctx, func(ctx context.Context, p Pair[int, T]) error {
+		e, err := f(ctx, p.Second)
+		if err != nil {
+			return fmt.Errorf("element %d: %w", p.First, err)
+		}
+		mu.Lock()
+		results[p.First] = e
+		mu.Unlock()
+		return nil
+	}, concurrency)
+
+	return results, err
+}
+
+// ParallelAggregate folds the collection across concurrency workers, each with a private accumulator created by
+// newAcc, then merges the worker accumulators into a fresh accumulator in worker order. Elements are shared between
+// workers as they become free, so fold and merge should not depend on element order. Concurrency defaults to the
+// number of CPUs if not positive. Returns the context's error if it is done before every element has been read
+// from the collection; a context which is done only after that does not fail the result
+func ParallelAggregate[T, A any](ctx context.Context, c *Collection[T], newAcc func() A, fold func(A, T) A, merge func(A, A) A, concurrency int) (A, error) {
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+
+	source := make(chan T, concurrency)
+	complete := false
+	go func() {
+		defer close(source)
+		for v := range *c {
+			select {
+			case <-ctx.Done():
+				return
+			case source <- v:
+			}
+		}
+		complete = true
+	}()
+
+	accs := make([]A, concurrency)
+	var wg sync.WaitGroup
+	for i := range accs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			acc := newAcc()
+			for v := range source {
+				acc = fold(acc, v)
+			}
+			accs[i] = acc
+		}()
+	}
+	wg.Wait()
+
+	// The workers only finish once the source is closed, after complete has been set
+	if !complete {
+		var zero A
+		return zero, ctx.Err()
+	}
+
+	result := newAcc()
+	for _, acc := range accs {
+		result = merge(result, acc)
+	}
+	return result, nil
+}
+
+// BufferByTime groups the elements of the collection into a batch per window of time, yielding each batch when its
+// window ends. Batches are yielded for every window, so a window in which no elements arrive yields an empty batch.
+// A final partial batch is yielded when the source ends, unless it is empty. Intended for sources such as channels
+// which produce elements over time. The source is enumerated in a goroutine which is stopped when the result ends,
+// is broken out of or the context is done, once its current element is received
+func BufferByTime[T any](ctx context.Context, c *Collection[T], window time.Duration) *Collection[[]T] {
+	return New[[]T](iter.Seq[[]T](func(yield func([]T) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		source := c.ToChannelCtx(ctx, 0)
+		ticker := time.NewTicker(window)
+		defer ticker.Stop()
+
+		batch := []T{}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if !yield(batch) {
+					return
+				}
+				batch = []T{}
+			case v, ok := <-source:
+				if !ok {
+					if len(batch) > 0 {
+						yield(batch)
+					}
+					return
+				}
+				batch = append(batch, v)
+			}
+		}
+	}))
+}
+
+// Batch groups the elements of the collection into batches, yielding a batch once it holds maxSize elements or
+// maxWait has passed since its first element arrived, whichever is first. Empty batches are never yielded, and the
+// final partial batch is yielded when the source ends. The source is enumerated in a goroutine which is stopped
+// when the result ends, is broken out of or the context is done, once its current element is received. Panics if
+// maxSize is not positive
+func Batch[T any](ctx context.Context, c *Collection[T], maxSize int, maxWait time.Duration) *Collection[[]T] {
+	if maxSize <= 0 {
+		panic("collection: batch size must be positive")
+	}
+
+	return New[[]T](iter.Seq[[]T](func(yield func([]T) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		source := c.ToChannelCtx(ctx, 0)
+		timer := time.NewTimer(maxWait)
+		timer.Stop()
+		defer timer.Stop()
+
+		var batch []T
+		flush := func() bool {
+			timer.Stop()
+			b := batch
+			batch = nil
+			return yield(b)
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+				if len(batch) > 0 && !flush() {
+					return
+				}
+			case v, ok := <-source:
+				if !ok {
+					if len(batch) > 0 {
+						flush()
+					}
+					return
+				}
+				batch = append(batch, v)
+				if len(batch) == 1 {
+					timer.Reset(maxWait)
+				}
+				if len(batch) == maxSize && !flush() {
+					return
+				}
+			}
+		}
+	}))
+}
+
+// Pairwise lazily yields each pair of consecutive elements, yielding nothing for collections with fewer
+// than two elements
+func Pairwise[T any](c *Collection[T]) *Collection[Pair[T, T]] {
+	return PairwiseWith(c, func(prev, cur T) Pair[T, T] {
+		return Pair[T, T]{First: prev, Second: cur}
+	})
+}
+
+// PairwiseWith lazily applies a function to each pair of consecutive elements, yielding nothing for collections
+// with fewer than two elements
+func PairwiseWith[T, R any](c *Collection[T], f func(prev, cur T) R) *Collection[R] {
+	return New[R](iter.Seq[R](func(yield func(R) bool) {
+		var prev T
+		first := true
+		for v := range *c {
+			if first {
+				first = false
+			} else if !yield(f(prev, v)) {
+				return
+			}
+			prev = v
+		}
+	}))
+}
+
+// SkipNil lazily filters the collection to only non-nil pointers
+func SkipNil[T any](c *Collection[*T]) *Collection[*T] {
+	return c.Where(func(x *T) bool { return x != nil })
+}
+
+// Deref lazily dereferences each pointer in the collection, skipping nil pointers
+func Deref[T any](c *Collection[*T]) *Collection[T] {
+	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		for v := range *c {
+			if v != nil && !yield(*v) {
+				return
+			}
+		}
+	}))
+}
+
+// Compact lazily removes elements equal to the zero value, such as empty strings, zeros and nil interfaces
+func Compact[T comparable](c *Collection[T]) *Collection[T] {
+	var zero T
+	return CompactBy(c, func(x T) bool { return x == zero })
+}
+
+// CompactBy lazily removes elements for which isEmpty returns true, for element types which are not comparable
+func CompactBy[T any](c *Collection[T], isEmpty func(x T) bool) *Collection[T] {
+	return c.Reject(isEmpty)
+}
+
+// DedupConsecutiveBy lazily collapses each run of adjacent elements with equal keys to its first element
+func DedupConsecutiveBy[T any, K comparable](c *Collection[T], key func(x T) K) *Collection[T] {
+	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		var prev K
+		first := true
+		for v := range *c {
+			k := key(v)
+			if !first && k == prev {
+				continue
+			}
+			first = false
+			prev = k
+			if !yield(v) {
+				return
+			}
+		}
+	}))
+}
+
+// CastOrError eagerly converts each element to E, returning ErrInvalidCast identifying the index and type of the
+// first element which is not an E
+func CastOrError[E any](c *Collection[any]) (*Collection[E], error) {
+	var results []E
+	i := 0
+	for v := range *c {
+		e, ok := v.(E)
+		if !ok {
+			return nil, fmt.Errorf("%w: element %d is %T, not %v", ErrInvalidCast, i, v, reflect.TypeFor[E]())
+		}
+		results = append(results, e)
+		i++
+	}
+
+	return NewFromSlice(results), nil
+}
+
+// Flatten flattens a collection of collections into a single collection
+func Flatten[T any](c *Collection[*Collection[T]]) *Collection[T] {
+	return New[T](iter.Seq[T](func(yield func(T) bool) {
+		for innerCollection := range *c {
+			for v := range *innerCollection {
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}))
+}
+
+// ConcatCollections lazily combines a collection of collections into one, in order. Unlike Flatten, nil collections
+// are skipped rather than causing a panic
+func ConcatCollections[T any](cs *Collection[*Collection[T]]) *Collection[T] {
+	return Flatten(cs.Where(func(c *Collection[T]) bool { return c != nil }))
+}
+
+// Fold applies a typed accumulator function over the collection, returning the final accumulated value
+func Fold[T, A any](c *Collection[T], seed A, f func(acc A, item T) A) A {
+	acc := seed
+	for v := range *c {
+		acc = f(acc, v)
+	}
+
+	return acc
+}
+
+// FoldOrError applies a typed accumulator function over the collection, stopping at the first error, which is
+// returned wrapped with the index of the failing element alongside the accumulated value so far
+func FoldOrError[T, A any](c *Collection[T], seed A, f func(acc A, item T) (A, error)) (A, error) {
+	acc := seed
+	i := 0
+	for v := range *c {
+		next, err := f(acc, v)
+		if err != nil {
+			return acc, fmt.Errorf("element %d: %w", i, err)
+		}
+		acc = next
+		i++
+	}
+
+	return acc, nil
+}
+
+// Scan lazily applies an accumulator function over the collection, yielding the accumulated value after each
+// element. The seed itself is not yielded
+func Scan[T, A any](c *Collection[T], seed A, f func(acc A, item T) A) *Collection[A] {
+	return New[A](iter.Seq[A](func(yield func(A) bool) {
+		acc := seed
+		for v := range *c {
+			acc = f(acc, v)
+			if !yield(acc) {
+				return
+			}
+		}
+	}))
+}
+
+// GenCollection generates a collection of size elements using gen, drawing randomness from r so that
+// collections can be reproduced from a seed
+func GenCollection[T any](r *rand.Rand, size int, gen func(*rand.Rand) T) *Collection[T] {
+	values := make([]T, 0, max(size, 0))
+	for range size {
+		values = append(values, gen(r))
+	}
+	return NewFromSlice(values)
+}
+
+// GenSlicePermutations generates count random permutations of s, each as a new slice
+func GenSlicePermutations[T any](r *rand.Rand, s []T, count int) *Collection[[]T] {
+	permutations := make([][]T, 0, max(count, 0))
+	for range count {
+		permutation := slices.Clone(s)
+		r.Shuffle(len(permutation), func(i, j int) {
+			permutation[i], permutation[j] = permutation[j], permutation[i]
+		})
+		permutations = append(permutations, permutation)
+	}
+	return NewFromSlice(permutations)
+}
+
+// GenWithDuplicates generates a collection of size elements where, after the first, each element is a copy of a
+// randomly chosen earlier element with probability dupRate, and otherwise generated using gen.
+// Panics if dupRate is outside [0, 1]
+func GenWithDuplicates[T any](r *rand.Rand, size int, dupRate float64, gen func(*rand.Rand) T) *Collection[T] {
+	if dupRate < 0 || dupRate > 1 || math.IsNaN(dupRate) {
+		panic("collection: duplicate rate must be within [0, 1]")
+	}
+
+	values := make([]T, 0, max(size, 0))
+	for i := range size {
+		if i > 0 && r.Float64() < dupRate {
+			values = append(values, values[r.Intn(i)])
+			continue
+		}
+		values = append(values, gen(r))
+	}
+	return NewFromSlice(values)
+}
+
+// IndexOfValue returns the index of the first element equal to v, or -1 if there is none
+func IndexOfValue[T comparable](c *Collection[T], v T) int {
+	return c.IndexOf(func(x T) bool { return x == v })
+}
+
+// LastIndexOfValue returns the index of the last element equal to v, or -1 if there is none
+func LastIndexOfValue[T comparable](c *Collection[T], v T) int {
+	return c.LastIndexOf(func(x T) bool { return x == v })
+}
+
+// EqualsUnordered returns true if a and b contain the same elements with the same number of occurrences, in any
+// order
+func EqualsUnordered[T comparable](a, b *Collection[T]) bool {
+	return EqualsUnorderedBy(a, b, func(x T) T { return x })
+}
+
+// EqualsUnorderedBy returns true if a and b contain elements with the same keys with the same number of
+// occurrences, in any order
+func EqualsUnorderedBy[T any, K comparable](a, b *Collection[T], key func(x T) K) bool {
+	counts := make(map[K]int)
+	for v := range *a {
+		counts[key(v)]++
+	}
+	for v := range *b {
+		k := key(v)
+		if counts[k] == 0 {
+			return false
+		}
+		counts[k]--
+	}
+	for _, n := range counts {
+		if n != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Compare compares a and b element by element like slices.CompareFunc, advancing both in lockstep without
+// buffering. The result is the first non-zero result of cmp, or if one collection is a prefix of the other, -1 if a
+// is shorter, +1 if b is shorter and 0 if they have the same length
+func Compare[T any](a, b *Collection[T], cmp func(x, y T) int) int {
+	nextA, stopA := iter.Pull(iter.Seq[T](*a))
+	defer stopA()
+	nextB, stopB := iter.Pull(iter.Seq[T](*b))
+	defer stopB()
+
+	for {
+		va, okA := nextA()
+		vb, okB := nextB()
+		switch {
+		case !okA && !okB:
+			return 0
+		case !okA:
+			return -1
+		case !okB:
+			return 1
+		}
+		if c := cmp(va, vb); c != 0 {
+			return c
+		}
+	}
+}
+
+// Diff compares before and after by key in one pass over each, returning the elements of after whose keys are not
+// in before, the elements of before whose keys are not in after, and the elements of after whose keys are in both.
+// Only the first element with each key in each input is considered; later elements with a repeated key are ignored.
+// added and unchanged are in the order of after and removed is in the order of before
+func Diff[T any, K comparable](before, after *Collection[T], key func(x T) K) (added, removed, unchanged *Collection[T]) {
+	added, removed, _, unchanged = DiffFunc(before, after, key, func(a, b T) bool { return true })
+	return added, removed, unchanged
+}
+
+// DiffFunc is like Diff but additionally uses equal to split the elements whose keys are in both inputs into those
+// which have changed and those which have not. changed and unchanged hold the elements from after
+func DiffFunc[T any, K comparable](before, after *Collection[T], key func(x T) K, equal func(before, after T) bool) (added, removed, changed, unchanged *Collection[T]) {
+	var beforeKeys []K
+	beforeByKey := make(map[K]T)
+	for v := range *before {
+		k := key(v)
+		if _, ok := beforeByKey[k]; !ok {
+			beforeKeys = append(beforeKeys, k)
+			beforeByKey[k] = v
+		}
+	}
+
+	var addedItems, changedItems, unchangedItems []T
+	seen := make(map[K]bool)
+	for v := range *after {
+		k := key(v)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+
+		old, ok := beforeByKey[k]
+		switch {
+		case !ok:
+			addedItems = append(addedItems, v)
+		case equal(old, v):
+			unchangedItems = append(unchangedItems, v)
+		default:
+			changedItems = append(changedItems, v)
+		}
+	}
+
+	var removedItems []T
+	for _, k := range beforeKeys {
+		if !seen[k] {
+			removedItems = append(removedItems, beforeByKey[k])
+		}
+	}
+
+	return NewFromSlice(addedItems), NewFromSlice(removedItems), NewFromSlice(changedItems), NewFromSlice(unchangedItems)
+}
+
+// ReservoirSample returns up to k elements chosen uniformly at random from c in a single pass, holding at most k
+// elements in memory, so it works on streams too large or too long-lived to collect. If c has k or fewer elements
+// all of them are returned. Random numbers are drawn from r, or from the global math/rand source if r is nil
+func ReservoirSample[T any](c *Collection[T], k int, r *rand.Rand) []T {
+	if k <= 0 {
+		return []T{}
+	}
+
+	intn := rand.Intn
+	if r != nil {
+		intn = r.Intn
+	}
+
+	sample := make([]T, 0, k)
+	seen := 0
+	for v := range *c {
+		seen++
+		if len(sample) < k {
+			sample = append(sample, v)
+			continue
+		}
+		if j := intn(seen); j < k {
+			sample[j] = v
+		}
+	}
+
+	return sample
+}
+
+// Mode returns the most frequently occurring element in the collection.
+// If multiple values have the same frequency, the first one is returned
+func Mode[T comparable](c *Collection[T]) (mode T, err error) {
+	slice := c.ToSlice()
+	if len(slice) == 0 {
+		return mode, ErrEmptyCollection
+	}
+
+	freq := make(map[T]int)
+	var keys []T
+	for _, v := range slice {
+		if _, ok := freq[v]; !ok {
+			keys = append(keys, v)
+		}
+		freq[v]++
+	}
+
+	var maxCount int
+	for _, key := range keys {
+		count := freq[key]
+		if count > maxCount {
+			mode = key
+			maxCount = count
+		}
+	}
+
+	return
+}
+
+// ExternalOptions configures operations which spill elements to temporary files
+type ExternalOptions struct {
+	// MemoryLimit is the maximum number of elements held in memory before spilling to disk. Defaults to 100000
+	MemoryLimit int
+	// TempDir is the directory in which temporary files are created. Defaults to os.TempDir()
+	TempDir string
+}
+
+func (o ExternalOptions) memoryLimit() int {
+	if o.MemoryLimit <= 0 {
+		return 100000
+	}
+	return o.MemoryLimit
+}
+
+// externalRecord is a single element of a spilled run, holding its key, its position in the source and its
+// encoded value
+type externalRecord struct {
+	Key  string
+	Seq  int
+	Data []byte
+}
+
+func compareRecordKeys(a, b externalRecord) int {
+	if c := strings.Compare(a.Key, b.Key); c != 0 {
+		return c
+	}
+	return a.Seq - b.Seq
+}
+
+func compareRecordSeqs(a, b externalRecord) int {
+	return a.Seq - b.Seq
+}
+
+// writeRun writes records to a new file at path. Each record is written as a big-endian uint32 key length, the key,
+// a big-endian uint64 sequence number, a big-endian uint32 data length and the data
+func writeRun(path string, records []externalRecord) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	// Write errors are retained by the bufio.Writer and returned by Flush
+	w := bufio.NewWriter(f)
+	header := make([]byte, 8)
+	for _, record := range records {
+		w.Write(binary.BigEndian.AppendUint32(header[:0], uint32(len(record.Key))))
+		w.WriteString(record.Key)
+		w.Write(binary.BigEndian.AppendUint64(header[:0], uint64(record.Seq)))
+		w.Write(binary.BigEndian.AppendUint32(header[:0], uint32(len(record.Data))))
+		w.Write(record.Data)
+	}
+	return w.Flush()
+}
+
+// readRecord reads the next record written by writeRun, returning io.EOF if there are no more
+func readRecord(r io.Reader) (externalRecord, error) {
+	var record externalRecord
+	key, err := readRunField(r)
+	if err != nil {
+		return record, err
+	}
+	seq := make([]byte, 8)
+	if _, err := io.ReadFull(r, seq); err != nil {
+		return record, io.ErrUnexpectedEOF
+	}
+	data, err := readRunField(r)
+	if err != nil {
+		return record, io.ErrUnexpectedEOF
+	}
+	return externalRecord{Key: string(key), Seq: int(binary.BigEndian.Uint64(seq)), Data: data}, nil
+}
+
+// readRunField reads a length-prefixed field of a run, returning io.EOF if the run ends before it
+func readRunField(r io.Reader) ([]byte, error) {
+	length := make([]byte, 4)
+	if _, err := io.ReadFull(r, length); err != nil {
+		return nil, err
+	}
+
+	// Read through a limit rather than allocating upfront, so a corrupt length can't force a huge allocation
+	size := int(binary.BigEndian.Uint32(length))
+	data, err := io.ReadAll(io.LimitReader(r, int64(size)))
+	if err == nil && len(data) < size {
+		err = io.ErrUnexpectedEOF
+	}
+	return data, err
+}
+
+// runCursor tracks the current record of a spilled run during a merge
+type runCursor struct {
+	reader  *bufio.Reader
+	current externalRecord
+}
+
+// runHeap orders the cursors of the runs being merged by their current record
+type runHeap struct {
+	cursors []*runCursor
+	compare func(a, b externalRecord) int
+}
+
+func (h *runHeap) Len() int { return len(h.cursors) }
+func (h *runHeap) Less(i, j int) bool {
+	return h.compare(h.cursors[i].current, h.cursors[j].current) < 0
+}
+func (h *runHeap) Swap(i, j int) { h.cursors[i], h.cursors[j] = h.cursors[j], h.cursors[i] }
+func (h *runHeap) Push(x any)    { h.cursors = append(h.cursors, x.(*runCursor)) }
+func (h *runHeap) Pop() any {
+	x := h.cursors[len(h.cursors)-1]
+	h.cursors = h.cursors[:len(h.cursors)-1]
+	return x
+}
+
+// mergeRuns merges the runs at paths, each sorted by compare, yielding their records in order
+func mergeRuns(paths []string, compare func(a, b externalRecord) int, yield func(externalRecord) bool) error {
+	h := &runHeap{compare: compare}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open run: %w", err)
+		}
+		defer f.Close()
+
+		cursor := &runCursor{reader: bufio.NewReader(f)}
+		if cursor.current, err = readRecord(cursor.reader); err != nil {
+			if err == io.EOF {
+				continue
+			}
+			return fmt.Errorf("failed to read run: %w", err)
+		}
+		h.cursors = append(h.cursors, cursor)
+	}
+	heap.Init(h)
+
+	for h.Len() > 0 {
+		cursor := h.cursors[0]
+		if !yield(cursor.current) {
+			return nil
+		}
+
+		var err error
+		if cursor.current, err = readRecord(cursor.reader); err != nil {
+			if err != io.EOF {
+				return fmt.Errorf("failed to read run: %w", err)
+			}
+			heap.Pop(h)
+			continue
+		}
+		heap.Fix(h, 0)
+	}
+	return nil
+}
+
+// External is a Fallible collection backed by temporary files, which remain until Close is called
+type External[T any] struct {
+	*Fallible[T]
+	dir string
+}
+
+// Close removes the temporary files backing the collection. Enumerating the collection afterwards reports an error
+func (e *External[T]) Close() error {
+	if e.dir == "" {
+		return nil
+	}
+	return os.RemoveAll(e.dir)
+}
+
+// DistinctExternal returns the first element for each key in order of first occurrence, like Distinct, spilling
+// elements encoded with encode to temporary files once opts.MemoryLimit are buffered and reading them back with decode
+func DistinctExternal[T any](c *Collection[T], key func(x T) string, encode func(T) ([]byte, error), decode func([]byte) (T, error), opts ExternalOptions) (*External[T], error) {
+	limit := opts.memoryLimit()
+
+	var dir string
+	var keyRuns []string
+	spill := func(records []externalRecord, compare func(a, b externalRecord) int, name string) (string, error) {
+		if dir == "" {
+			var err error
+			if dir, err = os.MkdirTemp(opts.TempDir, "collection-distinct-*"); err != nil {
+				return "", fmt.Errorf("failed to create temporary directory: %w", err)
+			}
+		}
+		slices.SortFunc(records, compare)
+		path := filepath.Join(dir, name)
+		if err := writeRun(path, records); err != nil {
+			return "", fmt.Errorf("failed to spill run: %w", err)
+		}
+		return path, nil
+	}
+	fail := func(err error) (*External[T], error) {
+		if dir != "" {
+			os.RemoveAll(dir)
+		}
+		return nil, err
+	}
+
+	// Deduplicate each run in memory, keeping the first occurrence of each key, before spilling it sorted by key
+	var values []T
+	seen := make(map[string]int)
+	seq := 0
+	for v := range *c {
+		k := key(v)
+		if _, ok := seen[k]; !ok {
+			seen[k] = seq
+			values = append(values, v)
+		}
+		seq++
+		if len(seen) < limit {
+			continue
+		}
+
+		records, err := encodeRecords(values, seen, key, encode)
+		if err != nil {
+			return fail(err)
+		}
+		path, err := spill(records, compareRecordKeys, fmt.Sprintf("key-%d.run", len(keyRuns)))
+		if err != nil {
+			return fail(err)
+		}
+		keyRuns = append(keyRuns, path)
+		values, seen = values[:0], make(map[string]int)
+	}
+
+	if len(keyRuns) == 0 {
+		return &External[T]{Fallible: newFallible(func(yield func(T) bool) error {
+			for _, v := range values {
+				if !yield(v) {
+					return nil
+				}
+			}
+			return nil
+		})}, nil
+	}
+
+	records, err := encodeRecords(values, seen, key, encode)
+	if err != nil {
+		return fail(err)
+	}
+	path, err := spill(records, compareRecordKeys, fmt.Sprintf("key-%d.run", len(keyRuns)))
+	if err != nil {
+		return fail(err)
+	}
+	keyRuns = append(keyRuns, path)
+
+	// Merge the runs by key, keeping the first occurrence of each, into runs sorted by seq to restore source order
+	var seqRuns []string
+	var buffer []externalRecord
+	var spillErr error
+	first, lastKey := true, ""
+	err = mergeRuns(keyRuns, compareRecordKeys, func(record externalRecord) bool {
+		if !first && record.Key == lastKey {
+			return true
+		}
+		first, lastKey = false, record.Key
+		if buffer = append(buffer, record); len(buffer) < limit {
+			return true
+		}
+
+		var path string
+		if path, spillErr = spill(buffer, compareRecordSeqs, fmt.Sprintf("seq-%d.run", len(seqRuns))); spillErr != nil {
+			return false
+		}
+		seqRuns, buffer = append(seqRuns, path), buffer[:0]
+		return true
+	})
+	if err = cmp.Or(err, spillErr); err != nil {
+		return fail(err)
+	}
+	if len(buffer) > 0 {
+		if path, err = spill(buffer, compareRecordSeqs, fmt.Sprintf("seq-%d.run", len(seqRuns))); err != nil {
+			return fail(err)
+		}
+		seqRuns = append(seqRuns, path)
+	}
+	for _, path := range keyRuns {
+		os.Remove(path)
+	}
+
+	return &External[T]{Fallible: newFallible(func(yield func(T) bool) error {
+		var decodeErr error
+		err := mergeRuns(seqRuns, compareRecordSeqs, func(record externalRecord) bool {
+			v, err := decode(record.Data)
+			if err != nil {
+				decodeErr = fmt.Errorf("failed to decode element %d: %w", record.Seq, err)
+				return false
+			}
+			return yield(v)
+		})
+		if err != nil {
+			return err
+		}
+		return decodeErr
+	}), dir: dir}, nil
+}
+
+// encodeRecords encodes values, the first occurrences of the keys in seen, as records for spilling
+func encodeRecords[T any](values []T, seen map[string]int, key func(x T) string, encode func(T) ([]byte, error)) ([]externalRecord, error) {
+	records := make([]externalRecord, 0, len(values))
+	for _, v := range values {
+		k := key(v)
+		data, err := encode(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode element %d: %w", seen[k], err)
+		}
+		records = append(records, externalRecord{Key: k, Seq: seen[k], Data: data})
+	}
+	return records, nil
+}
+
+// KeepLatestBy returns a collection containing only the newest element for each key, ordered by
+// the first occurrence of each key. Where timestamps are equal, the later element is kept
+func KeepLatestBy[T any, K comparable](c *Collection[T], key func(x T) K, timestamp func(x T) time.Time) *Collection[T] {
+	index := make(map[K]int)
+	var values []T
+	var timestamps []time.Time
+	for v := range *c {
+		k, ts := key(v), timestamp(v)
+		i, ok := index[k]
+		if !ok {
+			index[k] = len(values)
+			values = append(values, v)
+			timestamps = append(timestamps, ts)
+			continue
+		}
+		if !ts.Before(timestamps[i]) {
+			values[i] = v
+			timestamps[i] = ts
+		}
+	}
+
+	return NewFromSlice(values)
+}
+
+// GroupByKey groups elements by a typed key selector
+func GroupByKey[T any, K comparable](c *Collection[T], key func(x T) K) map[K]*Collection[T] {
+	buckets := indexBy(c, key)
+	groups := make(map[K]*Collection[T], len(buckets))
+	for k, bucket := range buckets {
+		groups[k] = NewFromSlice(bucket)
+	}
+
+	return groups
+}
+
+// Map converts the collection to a map
+func ToMap[T any, K comparable](c *Collection[T], keySelector func(x T) K) map[K]T {
+	m := make(map[K]T)
+	for v := range *c {
+		m[keySelector(v)] = v
+	}
+	return m
+}
+
+// ToSeq2 converts a collection of pairs to a key-value iterator
+func ToSeq2[K, V any](c *Collection[Pair[K, V]]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for p := range *c {
+			if !yield(p.First, p.Second) {
+				return
+			}
+		}
+	}
+}
+
+// Keys lazily returns the first value of each pair in the collection
+func Keys[K, V any](c *Collection[Pair[K, V]]) *Collection[K] {
+	return Select(c, func(p Pair[K, V]) K { return p.First })
+}
+
+// Values lazily returns the second value of each pair in the collection
+func Values[K, V any](c *Collection[Pair[K, V]]) *Collection[V] {
+	return Select(c, func(p Pair[K, V]) V { return p.Second })
+}
+
+// ToMultiMap converts the collection to a map of all elements with each key, preserving the order in which
+// elements were encountered
+func ToMultiMap[T any, K comparable](c *Collection[T], key func(x T) K) map[K][]T {
+	return ToMultiMapSelect(c, key, func(x T) T { return x })
+}
+
+// ToMultiMapSelect converts the collection to a map of the projected values of all elements with each key,
+// preserving the order in which elements were encountered
+func ToMultiMapSelect[T any, K comparable, V any](c *Collection[T], key func(x T) K, value func(x T) V) map[K][]V {
+	m := make(map[K][]V)
+	for v := range *c {
+		k := key(v)
+		m[k] = append(m[k], value(v))
+	}
+	return m
+}
+
+// ToSortedSlice converts the collection to a slice sorted in ascending order
+func ToSortedSlice[T cmp.Ordered](c *Collection[T]) []T {
+	return ToSortedSliceFunc(c, cmp.Compare[T])
+}
+
+// ToSortedSliceFunc converts the collection to a slice sorted using the comparison function. The sort is stable,
+// so equal elements keep their original order
+func ToSortedSliceFunc[T any](c *Collection[T], cmp func(a, b T) int) []T {
+	s := c.ToSlice()
+	slices.SortStableFunc(s, cmp)
+	return s
+}
+
+// WriteLines writes each element of c to w followed by a newline. Writing stops at the first error
+func WriteLines[T ~string](c *Collection[T], w io.Writer) error {
+	for v := range *c {
+		if _, err := io.WriteString(w, string(v)+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Lookup is a grouping of elements by key which preserves the order in which keys were first encountered
+type Lookup[K comparable, T any] struct {
+	keys   []K
+	groups map[K][]T
+}
 
-		// Start goroutines to generate values
-		go func() {
-			defer close(iter1)
-			for v := range *c1 {
-				iter1 <- v
-			}
-		}()
+// ToLookup groups elements by key into a Lookup, preserving the order in which keys were first encountered
+func ToLookup[T any, K comparable](c *Collection[T], key func(x T) K) *Lookup[K, T] {
+	l := &Lookup[K, T]{groups: make(map[K][]T)}
+	for v := range *c {
+		k := key(v)
+		if _, exists := l.groups[k]; !exists {
+			l.keys = append(l.keys, k)
+		}
+		l.groups[k] = append(l.groups[k], v)
+	}
+	return l
+}
 
-		go func() {
-			defer close(iter2)
-			for v := range *c2 {
-				iter2 <- v
-			}
-		}()
+// Keys returns the keys of the lookup in the order they were first encountered
+func (l *Lookup[K, T]) Keys() *Collection[K] {
+	return NewFromSlice(l.keys)
+}
 
-		// Zip elements together
-		for {
-			v1, ok1 := <-iter1
-			if !ok1 {
-				break
-			}
+// Get returns the elements with the given key, or an empty collection if the key is not present
+func (l *Lookup[K, T]) Get(k K) *Collection[T] {
+	return NewFromSlice(l.groups[k])
+}
 
-			v2, ok2 := <-iter2
-			if !ok2 {
-				break
-			}
+// Len returns the number of keys in the lookup
+func (l *Lookup[K, T]) Len() int {
+	return len(l.keys)
+}
 
-			if !yield(zipper(v1, v2)) {
+// All returns an iterator over each key and its elements, in the order keys were first encountered
+func (l *Lookup[K, T]) All() iter.Seq2[K, *Collection[T]] {
+	return func(yield func(K, *Collection[T]) bool) {
+		for _, k := range l.keys {
+			if !yield(k, l.Get(k)) {
 				return
 			}
 		}
-	}))
+	}
 }
 
-// Join performs an inner join on two collections based on matching keys
-func Join[TOuter, TInner, TKey comparable, TResult any](outer *Collection[TOuter], inner *Collection[TInner], outerKeySelector func(TOuter) TKey, innerKeySelector func(TInner) TKey, resultSelector func(TOuter, TInner) TResult) *Collection[TResult] {
-	return New[TResult](iter.Seq[TResult](func(yield func(TResult) bool) {
-		for outerItem := range *outer {
-			outerKey := outerKeySelector(outerItem)
+// RoundRobin lazily yields one element from each group in turn, in the order keys were first encountered, skipping
+// groups once they are exhausted
+func (l *Lookup[K, T]) RoundRobin() *Collection[T] {
+	groups := make([]*Collection[T], 0, len(l.keys))
+	for _, k := range l.keys {
+		groups = append(groups, l.Get(k))
+	}
+	return RoundRobin(groups)
+}
 
-			for innerItem := range *inner {
-				innerKey := innerKeySelector(innerItem)
+// Collection2 is a key-value collection, such as the entries of a map
+type Collection2[K comparable, V any] func(yield func(K, V) bool)
 
-				if outerKey == innerKey {
-					if !yield(resultSelector(outerItem, innerItem)) {
-						return
-					}
-				}
+// NewFromMapEntries creates a new Collection2 from the entries of a map
+func NewFromMapEntries[K comparable, V any](m map[K]V) *Collection2[K, V] {
+	d := Collection2[K, V](func(yield func(K, V) bool) {
+		for k, v := range m {
+			if !yield(k, v) {
+				return
 			}
 		}
-	}))
+	})
+	return &d
 }
 
-// Flatten flattens a collection of collections into a single collection
-func Flatten[T any](c *Collection[*Collection[T]]) *Collection[T] {
-	return New[T](iter.Seq[T](func(yield func(T) bool) {
-		for innerCollection := range *c {
-			for v := range *innerCollection {
-				if !yield(v) {
-					return
-				}
+// Where filters the collection to only entries satisfying the predicate function
+func (c *Collection2[K, V]) Where(f func(k K, v V) bool) *Collection2[K, V] {
+	d := Collection2[K, V](func(yield func(K, V) bool) {
+		for k, v := range *c {
+			if f(k, v) && !yield(k, v) {
+				return
 			}
 		}
-	}))
+	})
+	return &d
 }
 
-// Mode returns the most frequently occurring element in the collection.
-// If multiple values have the same frequency, the first one is returned
-func Mode[T comparable](c *Collection[T]) (mode T, err error) {
-	slice := c.ToSlice()
-	if len(slice) == 0 {
-		return mode, ErrEmptyCollection
-	}
-
-	freq := make(map[T]int)
-	var keys []T
-	for _, v := range slice {
-		if _, ok := freq[v]; !ok {
-			keys = append(keys, v)
-		}
-		freq[v]++
-	}
+// Keys returns a collection of the keys of each entry
+func (c *Collection2[K, V]) Keys() *Collection[K] {
+	return NewFromSeq2Keys(iter.Seq2[K, V](*c))
+}
 
-	var maxCount int
-	for _, key := range keys {
-		count := freq[key]
-		if count > maxCount {
-			mode = key
-			maxCount = count
-		}
-	}
+// Values returns a collection of the values of each entry
+func (c *Collection2[K, V]) Values() *Collection[V] {
+	return NewFromSeq2Values(iter.Seq2[K, V](*c))
+}
 
-	return
+// Entries returns a collection of each entry as a pair
+func (c *Collection2[K, V]) Entries() *Collection[Pair[K, V]] {
+	return NewFromSeq2(iter.Seq2[K, V](*c))
 }
 
-// Map converts the collection to a map
-func ToMap[T any, K comparable](c *Collection[T], keySelector func(x T) K) map[K]T {
-	m := make(map[K]T)
-	for v := range *c {
-		m[keySelector(v)] = v
+// ToMap converts the collection to a map. Later entries overwrite earlier entries with the same key
+func (c *Collection2[K, V]) ToMap() map[K]V {
+	m := make(map[K]V)
+	for k, v := range *c {
+		m[k] = v
 	}
 	return m
 }
 
+// Select2 transforms the value of each entry using the selector function, keeping the keys
+func Select2[K comparable, V any, E any](c *Collection2[K, V], f func(k K, v V) E) *Collection2[K, E] {
+	d := Collection2[K, E](func(yield func(K, E) bool) {
+		for k, v := range *c {
+			if !yield(k, f(k, v)) {
+				return
+			}
+		}
+	})
+	return &d
+}
+
 // AverageOrError calculates the average or returns an error if empty
 func AverageOrError[T NumericalTypes](c *Collection[T]) (*big.Float, error) {
 	sum := float64(0)
@@ -1004,17 +4309,310 @@ func Median[T NumericalTypes](c *Collection[T]) (*big.Float, error) {
 	return big.NewFloat(float64(slice[mid])), nil
 }
 
+// elementwise applies op to each pair of elements at the same position in a and b, pulling from both in lockstep
+func elementwise[T NumericalTypes](a, b *Collection[T], op func(x, y T) T) (*Collection[T], error) {
+	nextA, stopA := iter.Pull(iter.Seq[T](*a))
+	defer stopA()
+	nextB, stopB := iter.Pull(iter.Seq[T](*b))
+	defer stopB()
+
+	var results []T
+	for {
+		x, okA := nextA()
+		y, okB := nextB()
+		if okA != okB {
+			return nil, fmt.Errorf("%w: collections diverge at index %d", ErrLengthMismatch, len(results))
+		}
+		if !okA {
+			return NewFromSlice(results), nil
+		}
+		results = append(results, op(x, y))
+	}
+}
+
+// AddElementwise adds the elements at each position of two collections, returning an error wrapping
+// ErrLengthMismatch if the collections differ in length. Integer results wrap on overflow
+func AddElementwise[T NumericalTypes](a, b *Collection[T]) (*Collection[T], error) {
+	return elementwise(a, b, func(x, y T) T { return x + y })
+}
+
+// SubElementwise subtracts the elements of b from the elements at each position of a, returning an error wrapping
+// ErrLengthMismatch if the collections differ in length. Integer results wrap on overflow
+func SubElementwise[T NumericalTypes](a, b *Collection[T]) (*Collection[T], error) {
+	return elementwise(a, b, func(x, y T) T { return x - y })
+}
+
+// MulElementwise multiplies the elements at each position of two collections, returning an error wrapping
+// ErrLengthMismatch if the collections differ in length. Integer results wrap on overflow
+func MulElementwise[T NumericalTypes](a, b *Collection[T]) (*Collection[T], error) {
+	return elementwise(a, b, func(x, y T) T { return x * y })
+}
+
+// Scale multiplies each element of the collection by k. Integer results wrap on overflow
+func Scale[T NumericalTypes](c *Collection[T], k T) *Collection[T] {
+	return Select(c, func(x T) T { return x * k })
+}
+
+// MovingAverage lazily yields the mean of each window of consecutive elements, maintaining a running sum over a
+// ring buffer. No value is yielded until the first window is full, so collections with fewer than window
+// elements yield nothing. Panics if window is not positive
+func MovingAverage[T NumericalTypes](c *Collection[T], window int) *Collection[float64] {
+	if window <= 0 {
+		panic("collection: window size must be positive")
+	}
+
+	return New[float64](iter.Seq[float64](func(yield func(float64) bool) {
+		buffer := make([]float64, window)
+		sum := float64(0)
+		count := 0
+		for v := range *c {
+			i := count % window
+			sum += float64(v) - buffer[i]
+			buffer[i] = float64(v)
+			count++
+			if count < window {
+				continue
+			}
+			if !yield(sum / float64(window)) {
+				return
+			}
+		}
+	}))
+}
+
+// p2Estimator estimates a single quantile using the P² algorithm (Jain & Chlamtac, 1985), which tracks
+// five markers rather than storing observations
+type p2Estimator struct {
+	p       float64
+	count   int
+	heights [5]float64
+	pos     [5]float64
+	desired [5]float64
+	incr    [5]float64
+}
+
+func newP2Estimator(p float64) *p2Estimator {
+	return &p2Estimator{
+		p:       p,
+		pos:     [5]float64{0, 1, 2, 3, 4},
+		desired: [5]float64{0, 2 * p, 4 * p, 2 + 2*p, 4},
+		incr:    [5]float64{0, p / 2, p, (1 + p) / 2, 1},
+	}
+}
+
+func (e *p2Estimator) add(x float64) {
+	if e.count < 5 {
+		e.heights[e.count] = x
+		e.count++
+		if e.count == 5 {
+			slices.Sort(e.heights[:])
+		}
+		return
+	}
+	e.count++
+
+	var k int
+	switch {
+	case x < e.heights[0]:
+		e.heights[0] = x
+		k = 0
+	case x >= e.heights[4]:
+		e.heights[4] = x
+		k = 3
+	default:
+		for k = 0; k < 3; k++ {
+			if x < e.heights[k+1] {
+				break
+			}
+		}
+	}
+
+	for i := k + 1; i < 5; i++ {
+		e.pos[i]++
+	}
+	for i := range e.desired {
+		e.desired[i] += e.incr[i]
+	}
+
+	for i := 1; i < 4; i++ {
+		d := e.desired[i] - e.pos[i]
+		if (d >= 1 && e.pos[i+1]-e.pos[i] > 1) || (d <= -1 && e.pos[i-1]-e.pos[i] < -1) {
+			sign := 1.0
+			if d < 0 {
+				sign = -1
+			}
+
+			q := e.parabolic(i, sign)
+			if e.heights[i-1] < q && q < e.heights[i+1] {
+				e.heights[i] = q
+			} else {
+				e.heights[i] = e.linear(i, int(sign))
+			}
+			e.pos[i] += sign
+		}
+	}
+}
+
+func (e *p2Estimator) parabolic(i int, d float64) float64 {
+	h, n := e.heights, e.pos
+	return h[i] + d/(n[i+1]-n[i-1])*((n[i]-n[i-1]+d)*(h[i+1]-h[i])/(n[i+1]-n[i])+(n[i+1]-n[i]-d)*(h[i]-h[i-1])/(n[i]-n[i-1]))
+}
+
+func (e *p2Estimator) linear(i int, d int) float64 {
+	return e.heights[i] + float64(d)*(e.heights[i+d]-e.heights[i])/(e.pos[i+d]-e.pos[i])
+}
+
+func (e *p2Estimator) value() float64 {
+	if e.count >= 5 {
+		return e.heights[2]
+	}
+
+	// Too few observations for the markers, so interpolate between the closest ranks
+	observed := slices.Clone(e.heights[:e.count])
+	slices.Sort(observed)
+	rank := e.p * float64(len(observed)-1)
+	lower := int(rank)
+	if lower+1 >= len(observed) {
+		return observed[lower]
+	}
+	return observed[lower] + (rank-float64(lower))*(observed[lower+1]-observed[lower])
+}
+
+// PercentileSketch estimates the given percentiles (0-100) of the collection in a single pass with constant memory,
+// returning a map of each target to its estimate. Percentiles other than 0 and 100 (which are exact) are estimated
+// with the P² algorithm, which is typically within a few percent of the exact value for large, smoothly distributed
+// samples, but less accurate for small samples or extreme percentiles of heavy-tailed distributions.
+// ErrEmptyCollection is returned for an empty collection, and ErrInvalidPercentile for targets outside 0-100
+func PercentileSketch(c *Collection[float64], targets ...float64) (map[float64]float64, error) {
+	estimators := make(map[float64]*p2Estimator, len(targets))
+	for _, target := range targets {
+		if target < 0 || target > 100 || math.IsNaN(target) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPercentile, target)
+		}
+		estimators[target] = newP2Estimator(target / 100)
+	}
+
+	count := 0
+	minimum, maximum := math.Inf(1), math.Inf(-1)
+	for v := range *c {
+		count++
+		minimum = min(minimum, v)
+		maximum = max(maximum, v)
+		for _, e := range estimators {
+			e.add(v)
+		}
+	}
+
+	if count == 0 {
+		return nil, ErrEmptyCollection
+	}
+
+	results := make(map[float64]float64, len(estimators))
+	for target, e := range estimators {
+		switch target {
+		case 0:
+			results[target] = minimum
+		case 100:
+			results[target] = maximum
+		default:
+			results[target] = e.value()
+		}
+	}
+
+	return results, nil
+}
+
 // Select transforms each element in the collection using the selector function
 func Select[T any, E any](c *Collection[T], f func(x T) E) *Collection[E] {
 	return New[E](iter.Seq[E](func(yield func(E) bool) {
+		MapSeq(iter.Seq[T](*c), f)(yield)
+	}))
+}
+
+// SelectIndexed transforms each element in the collection using the selector function, which is given each
+// element's position in the source collection
+func SelectIndexed[T any, E any](c *Collection[T], f func(i int, x T) E) *Collection[E] {
+	return New[E](iter.Seq[E](func(yield func(E) bool) {
+		i := 0
 		for v := range *c {
-			if !yield(f(v)) {
+			if !yield(f(i, v)) {
+				return
+			}
+			i++
+		}
+	}))
+}
+
+// SelectSafe transforms each element in the collection using the selector function, skipping elements for which
+// the selector returns false
+func SelectSafe[T any, E any](c *Collection[T], f func(x T) (E, bool)) *Collection[E] {
+	return New[E](iter.Seq[E](func(yield func(E) bool) {
+		for v := range *c {
+			if e, ok := f(v); ok && !yield(e) {
 				return
 			}
 		}
 	}))
 }
 
+// ProjectionError summarises the elements which failed to project during an enumeration of SelectOrZero
+type ProjectionError struct {
+	Failed int
+	Total  int
+	Errs   []error
+}
+
+func (e *ProjectionError) Error() string {
+	return fmt.Sprintf("%d of %d elements failed to project: %v", e.Failed, e.Total, errors.Join(e.Errs...))
+}
+
+func (e *ProjectionError) Unwrap() []error {
+	return e.Errs
+}
+
+// SelectOrZero transforms each element in the collection using the selector function, substituting the zero value
+// on error. Err reports a *ProjectionError summarising the failures of the most recent enumeration
+func SelectOrZero[T any, E any](c *Collection[T], f func(x T) (E, error)) *Fallible[E] {
+	return newFallible(func(yield func(E) bool) error {
+		var zero E
+		summary := &ProjectionError{}
+		for v := range *c {
+			e, err := f(v)
+			if err != nil {
+				summary.Errs = append(summary.Errs, fmt.Errorf("element %d: %w", summary.Total, err))
+				summary.Failed++
+				e = zero
+			}
+			summary.Total++
+			if !yield(e) {
+				break
+			}
+		}
+
+		if summary.Failed == 0 {
+			return nil
+		}
+		return summary
+	})
+}
+
+// SelectOrError eagerly transforms each element in the collection using the selector function, stopping at the
+// first error, which is returned wrapped with the index of the failing element
+func SelectOrError[T any, E any](c *Collection[T], f func(x T) (E, error)) (*Collection[E], error) {
+	var results []E
+	i := 0
+	for v := range *c {
+		e, err := f(v)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		results = append(results, e)
+		i++
+	}
+
+	return NewFromSlice(results), nil
+}
+
 // SelectMany projects each element of the collection to a new collection and flattens the resulting collections into one
 func SelectMany[T any, E any](c *Collection[T], f func(x T) *Collection[E]) *Collection[E] {
 	return New[E](iter.Seq[E](func(yield func(E) bool) {
@@ -1028,3 +4626,71 @@ func SelectMany[T any, E any](c *Collection[T], f func(x T) *Collection[E]) *Col
 		}
 	}))
 }
+
+// SelectManySlice projects each element of the collection to a slice and flattens the resulting slices into one
+func SelectManySlice[T any, E any](c *Collection[T], f func(x T) []E) *Collection[E] {
+	return New[E](iter.Seq[E](func(yield func(E) bool) {
+		for v := range *c {
+			for _, innerValue := range f(v) {
+				if !yield(innerValue) {
+					return
+				}
+			}
+		}
+	}))
+}
+
+// FilterSeq returns an iterator yielding only elements of seq satisfying the predicate function
+func FilterSeq[T any](seq iter.Seq[T], f func(x T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range seq {
+			if f(v) && !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// MapSeq returns an iterator yielding each element of seq transformed by the selector function
+func MapSeq[T any, E any](seq iter.Seq[T], f func(x T) E) iter.Seq[E] {
+	return func(yield func(E) bool) {
+		for v := range seq {
+			if !yield(f(v)) {
+				return
+			}
+		}
+	}
+}
+
+// TakeSeq returns an iterator yielding only the first n elements of seq
+func TakeSeq[T any](seq iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if n <= 0 {
+			return
+		}
+
+		count := 0
+		for v := range seq {
+			if !yield(v) {
+				return
+			}
+			count++
+			if count >= n {
+				return
+			}
+		}
+	}
+}
+
+// ConcatSeq returns an iterator yielding the elements of each of seqs in turn
+func ConcatSeq[T any](seqs ...iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, seq := range seqs {
+			for v := range seq {
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -1,14 +1,32 @@
 package collection_test
 
 import (
+	"bufio"
+	"bytes"
+	"cmp"
 	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/gob"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"math"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
 	"slices"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	collection "github.com/0x4c6565/go-collection"
@@ -75,2608 +93,9160 @@ func TestNewFromChannel(t *testing.T) {
 	assert.Equal(t, "a", v)
 }
 
-func TestNewFromRange(t *testing.T) {
-	c := collection.NewFromRange(1, 5)
-	f, _ := c.First()
-	l, _ := c.Last()
-
-	assert.Equal(t, 1, f)
-	assert.Equal(t, 5, l)
-	assert.Equal(t, c.Len(), 5)
-}
-
-func TestNewFromJSON(t *testing.T) {
-	t.Run("Valid", func(t *testing.T) {
-		data := []byte(`["a", "b", "c"]`)
-		c, err := collection.NewFromJSON[string](data)
-
-		assert.Nil(t, err)
-		assert.Equal(t, 3, c.Count())
-	})
+func TestNewFromChannelCtx(t *testing.T) {
+	t.Run("Cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch := make(chan int)
+		go func() {
+			ch <- 1
+			ch <- 2
+		}()
 
-	t.Run("InvalidJSON", func(t *testing.T) {
-		data := []byte(`invalid`)
-		_, err := collection.NewFromJSON[string](data)
+		var result []int
+		for v := range *collection.NewFromChannelCtx(ctx, ch) {
+			result = append(result, v)
+			if v == 2 {
+				cancel()
+			}
+		}
 
-		assert.NotNil(t, err)
+		assert.Equal(t, []int{1, 2}, result)
 	})
-}
 
-func TestWhere(t *testing.T) {
-	c := collection.NewFromSlice([]string{"a", "b", "c"})
-	t.Run("Elements", func(t *testing.T) {
-		v := c.Where(func(x string) bool {
-			return x == "a"
-		}).ToSlice()
+	t.Run("Deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		ch := make(chan int)
 
-		assert.Len(t, v, 1)
-		assert.Equal(t, "a", v[0])
+		assert.True(t, collection.NewFromChannelCtx(ctx, ch).IsEmpty())
 	})
 
-	t.Run("NoElements", func(t *testing.T) {
-		v := c.Where(func(x string) bool {
-			return x == "z"
-		}).ToSlice()
+	t.Run("Closed", func(t *testing.T) {
+		ch := make(chan int, 3)
+		ch <- 1
+		ch <- 2
+		ch <- 3
+		close(ch)
 
-		assert.Len(t, v, 0)
+		assert.Equal(t, []int{1, 2, 3}, collection.NewFromChannelCtx(context.Background(), ch).ToSlice())
 	})
 
 	t.Run("Break", func(t *testing.T) {
-		for range *c.Where(func(x string) bool {
-			return x == "a"
-		}) {
+		ch := make(chan int, 3)
+		ch <- 1
+		ch <- 2
+		ch <- 3
+
+		for range *collection.NewFromChannelCtx(context.Background(), ch) {
 			break
 		}
+
+		assert.Equal(t, 2, len(ch))
 	})
 }
 
-func TestReject(t *testing.T) {
-	c := collection.NewFromSlice([]string{"a", "b", "c"})
-	t.Run("Elements", func(t *testing.T) {
-		v := c.Reject(func(x string) bool {
-			return x == "a"
-		}).ToSlice()
+func TestNewFromChannels(t *testing.T) {
+	t.Run("ClosedAtDifferentTimes", func(t *testing.T) {
+		chs := make([]chan int, 3)
+		for i := range chs {
+			chs[i] = make(chan int)
+			go func() {
+				for j := range 5 {
+					chs[i] <- i*10 + j
+				}
+				time.Sleep(time.Duration(i) * 5 * time.Millisecond)
+				close(chs[i])
+			}()
+		}
 
-		assert.Len(t, v, 2)
-		assert.Equal(t, "b", v[0])
-		assert.Equal(t, "c", v[1])
+		result := collection.NewFromChannels[int](chs[0], chs[1], chs[2]).ToSlice()
+
+		assert.ElementsMatch(t, []int{0, 1, 2, 3, 4, 10, 11, 12, 13, 14, 20, 21, 22, 23, 24}, result)
 	})
 
-	t.Run("NoElements", func(t *testing.T) {
-		v := c.Reject(func(x string) bool {
-			return x == "z"
-		}).ToSlice()
+	t.Run("EmptyClosedChannel", func(t *testing.T) {
+		empty := make(chan int)
+		close(empty)
+		values := make(chan int, 2)
+		values <- 1
+		values <- 2
+		close(values)
 
-		assert.Len(t, v, 3)
+		result := collection.NewFromChannels[int](empty, values).ToSlice()
+
+		assert.ElementsMatch(t, []int{1, 2}, result)
 	})
 
 	t.Run("Break", func(t *testing.T) {
-		for range *c.Reject(func(x string) bool {
-			return x == "a"
-		}) {
+		ch1 := make(chan int, 10)
+		ch2 := make(chan int)
+		for i := range 10 {
+			ch1 <- i
+		}
+
+		for range *collection.NewFromChannels[int](ch1, ch2) {
 			break
 		}
+		close(ch1)
+		close(ch2)
 	})
-}
-
-func TestFind(t *testing.T) {
-	c := collection.NewFromSlice([]string{"a", "b", "c"})
-	t.Run("Element", func(t *testing.T) {
-		v, ok := c.Find(func(x string) bool {
-			return x == "a"
-		})
 
-		assert.True(t, ok)
-		assert.Equal(t, "a", v)
-	})
+	t.Run("NoLeakOnBreakWithIdleChannel", func(t *testing.T) {
+		before := runtime.NumGoroutine()
+		ch1 := make(chan int, 1)
+		ch1 <- 1
+		idle := make(chan int)
+		defer close(idle)
 
-	t.Run("NoElement", func(t *testing.T) {
-		v, ok := c.Find(func(x string) bool {
-			return x == "z"
-		})
+		for range *collection.NewFromChannels[int](ch1, idle) {
+			break
+		}
 
-		assert.False(t, ok)
-		assert.Equal(t, "", v)
+		for range 100 {
+			if runtime.NumGoroutine() <= before {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		assert.LessOrEqual(t, runtime.NumGoroutine(), before)
 	})
 }
 
-func TestSelect(t *testing.T) {
-	type teststruct struct {
-		Property1 string
-		Property2 int
-	}
-	var teststructs []teststruct
-	teststructs = append(teststructs, teststruct{
-		Property1: "s1",
-		Property2: 1,
-	}, teststruct{
-		Property1: "s2",
-		Property2: 2,
-	})
-	c := collection.New[teststruct](teststructs)
+func TestNewFromChannelReplay(t *testing.T) {
+	t.Run("IterateTwice", func(t *testing.T) {
+		ch := make(chan int, 3)
+		ch <- 1
+		ch <- 2
+		ch <- 3
+		close(ch)
 
-	t.Run("ReturnProperty", func(t *testing.T) {
-		var results []int
-		for _, val := range c.Select(func(x teststruct) any {
-			return x.Property2
-		}).ToSlice() {
-			results = append(results, val.(int))
-		}
+		c := collection.NewFromChannelReplay(ch)
 
-		assert.Equal(t, 1, results[0])
-		assert.Equal(t, 2, results[1])
+		assert.Equal(t, 3, c.Count())
+		assert.Equal(t, []int{1, 2, 3}, c.ToSlice())
 	})
 
-	t.Run("Break", func(t *testing.T) {
-		for range *c.Select(func(x teststruct) any {
-			return x.Property2
-		}) {
-			break
+	t.Run("InterleavedPartial", func(t *testing.T) {
+		ch := make(chan int, 4)
+		for i := range 4 {
+			ch <- i
 		}
-	})
-}
+		close(ch)
 
-func TestSelectMany(t *testing.T) {
-	type teststruct struct {
-		Property1 string
-		Property2 int
-		Children  []string
-	}
+		c := collection.NewFromChannelReplay(ch)
+		next1, stop1 := iter.Pull(iter.Seq[int](*c))
+		defer stop1()
+		next2, stop2 := iter.Pull(iter.Seq[int](*c))
+		defer stop2()
 
-	var teststructs []teststruct
-	teststructs = append(teststructs, teststruct{
-		Property1: "s1",
-		Property2: 1,
-		Children:  []string{"child1", "child2"},
-	}, teststruct{
-		Property1: "s2",
-		Property2: 2,
-		Children:  []string{"child3", "child4", "child5"},
+		v, _ := next1()
+		assert.Equal(t, 0, v)
+		v, _ = next1()
+		assert.Equal(t, 1, v)
+		v, _ = next2()
+		assert.Equal(t, 0, v)
+		v, _ = next2()
+		assert.Equal(t, 1, v)
+		v, _ = next2()
+		assert.Equal(t, 2, v)
+		v, _ = next1()
+		assert.Equal(t, 2, v)
 	})
 
-	c := collection.New[teststruct](teststructs)
+	t.Run("OpenChannel", func(t *testing.T) {
+		ch := make(chan int, 4)
+		ch <- 1
+		ch <- 2
 
-	t.Run("FlattenChildren", func(t *testing.T) {
-		var results []string
-		for _, val := range c.SelectMany(func(x teststruct) *collection.Collection[any] {
-			childrenAny := make([]any, len(x.Children))
-			for i, child := range x.Children {
-				childrenAny[i] = child
-			}
-			return collection.New[any](childrenAny)
-		}).ToSlice() {
-			results = append(results, val.(string))
-		}
+		c := collection.NewFromChannelReplay(ch)
 
-		assert.Equal(t, 5, len(results))
-		assert.Equal(t, "child1", results[0])
-		assert.Equal(t, "child2", results[1])
-		assert.Equal(t, "child3", results[2])
-		assert.Equal(t, "child4", results[3])
-		assert.Equal(t, "child5", results[4])
+		assert.Equal(t, []int{1, 2}, c.Take(2).ToSlice())
+
+		ch <- 3
+		ch <- 4
+		close(ch)
+
+		assert.Equal(t, []int{1, 2, 3, 4}, c.ToSlice())
+		assert.Equal(t, []int{1, 2, 3, 4}, c.ToSlice())
 	})
 
-	t.Run("Break", func(t *testing.T) {
-		for range *c.SelectMany(func(x teststruct) *collection.Collection[any] {
-			childrenAny := make([]any, len(x.Children))
-			for i, child := range x.Children {
-				childrenAny[i] = child
+	t.Run("ReplayWhileChannelIdle", func(t *testing.T) {
+		ch := make(chan int, 2)
+		ch <- 1
+		ch <- 2
+		c := collection.NewFromChannelReplay(ch)
+
+		received := make(chan int)
+		finished := make(chan struct{})
+		go func() {
+			defer close(finished)
+			for v := range *c {
+				received <- v
 			}
-			return collection.New[any](childrenAny)
-		}) {
-			break
+		}()
+		<-received
+		<-received
+
+		// The live enumeration goes on to wait on the open, idle channel
+		replayed := make(chan []int)
+		go func() { replayed <- c.Take(2).ToSlice() }()
+
+		select {
+		case result := <-replayed:
+			assert.Equal(t, []int{1, 2}, result)
+		case <-time.After(time.Second):
+			t.Error("replay blocked while the channel was idle")
 		}
+		close(ch)
+		<-finished
 	})
+}
 
-	t.Run("EmptyChildren", func(t *testing.T) {
-		var emptyTeststructs []teststruct
-		emptyTeststructs = append(emptyTeststructs, teststruct{
-			Property1: "s1",
-			Property2: 1,
-			Children:  []string{},
-		}, teststruct{
-			Property1: "s2",
-			Property2: 2,
-			Children:  []string{},
-		})
+func TestNewFromSeq2Values(t *testing.T) {
+	t.Run("Logic", func(t *testing.T) {
+		c := collection.NewFromSeq2Values(slices.All([]string{"a", "b", "c"}))
 
-		emptyC := collection.New[teststruct](emptyTeststructs)
+		assert.Equal(t, []string{"a", "b", "c"}, c.ToSlice())
+	})
 
-		var results []string
-		for _, val := range emptyC.SelectMany(func(x teststruct) *collection.Collection[any] {
-			childrenAny := make([]any, len(x.Children))
-			for i, child := range x.Children {
-				childrenAny[i] = child
-			}
-			return collection.New[any](childrenAny)
-		}).ToSlice() {
-			results = append(results, val.(string))
-		}
+	t.Run("Break", func(t *testing.T) {
+		c := collection.NewFromSeq2Values(collection.NewFromRange(0, 10).Enumerate())
 
-		assert.Equal(t, 0, len(results))
+		assert.Equal(t, []int{0, 1}, c.Take(2).ToSlice())
 	})
+}
 
-	t.Run("MixedTypes", func(t *testing.T) {
-		var results []any
-		results = append(results, c.SelectMany(func(x teststruct) *collection.Collection[any] {
-			return collection.New[any]([]any{x.Property1, x.Property2})
-		}).ToSlice()...)
+func TestNewFromSeq2Keys(t *testing.T) {
+	t.Run("Logic", func(t *testing.T) {
+		c := collection.NewFromSeq2Keys(slices.All([]string{"a", "b", "c"}))
 
-		assert.Equal(t, 4, len(results))
-		assert.Equal(t, "s1", results[0])
-		assert.Equal(t, 1, results[1])
-		assert.Equal(t, "s2", results[2])
-		assert.Equal(t, 2, results[3])
+		assert.Equal(t, []int{0, 1, 2}, c.ToSlice())
 	})
-}
 
-func TestToMap(t *testing.T) {
-	t.Run("Ints", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"1", "2", "3"})
-		v := collection.ToMap(c, func(x string) int {
-			i, _ := strconv.Atoi(x)
-			return i
-		})
+	t.Run("Break", func(t *testing.T) {
+		c := collection.NewFromSeq2Keys(slices.All([]string{"a", "b", "c"}))
 
-		assert.Len(t, v, 3)
-		assert.Equal(t, "1", v[1])
-		assert.Equal(t, "2", v[2])
-		assert.Equal(t, "3", v[3])
+		assert.Equal(t, []int{0}, c.Take(1).ToSlice())
 	})
+}
 
-	t.Run("Structs", func(t *testing.T) {
-		type teststruct struct {
-			Animal string
-			Name   string
-		}
+func TestNewFromSeq2(t *testing.T) {
+	t.Run("Logic", func(t *testing.T) {
+		c := collection.NewFromSeq2(slices.All([]string{"a", "b"}))
 
-		c := collection.NewFromSlice([]teststruct{
-			{Animal: "Cow", Name: "Daisy"},
-			{Animal: "Chicken", Name: "Bert"},
-			{Animal: "Goose", Name: "Bruce"},
-		})
+		assert.Equal(t, []collection.Pair[int, string]{{First: 0, Second: "a"}, {First: 1, Second: "b"}}, c.ToSlice())
+	})
 
-		v := collection.ToMap(c, func(x teststruct) string {
-			return x.Animal
-		})
+	t.Run("MapRoundTrip", func(t *testing.T) {
+		m := map[string]int{"a": 1, "b": 2, "c": 3}
 
-		assert.Len(t, v, 3)
-		assert.Equal(t, "Daisy", v["Cow"].Name)
-		assert.Equal(t, "Bert", v["Chicken"].Name)
-		assert.Equal(t, "Bruce", v["Goose"].Name)
+		c := collection.NewFromSeq2(maps.All(m))
+
+		assert.Equal(t, m, maps.Collect(collection.ToSeq2(c)))
 	})
-}
 
-func TestAll(t *testing.T) {
-	t.Run("True", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		v := c.All(func(x string) bool {
-			return x != ""
-		})
+	t.Run("Break", func(t *testing.T) {
+		c := collection.NewFromSeq2(slices.All([]string{"a", "b", "c"}))
 
-		assert.True(t, v)
+		assert.Equal(t, 1, c.Take(1).Len())
 	})
+}
 
-	t.Run("False", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		v := c.All(func(x string) bool {
-			return x == "a"
-		})
+func TestNewFromRange(t *testing.T) {
+	c := collection.NewFromRange(1, 5)
+	f, _ := c.First()
+	l, _ := c.Last()
 
-		assert.False(t, v)
-	})
+	assert.Equal(t, 1, f)
+	assert.Equal(t, 5, l)
+	assert.Equal(t, c.Len(), 5)
 }
 
-func TestFirst(t *testing.T) {
-	t.Run("NoElement_OkFalse", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{})
-		_, ok := c.First()
+func TestNewFromRangeStep(t *testing.T) {
+	t.Run("Ascending", func(t *testing.T) {
+		assert.Equal(t, []int{0, 2, 4, 6, 8}, collection.NewFromRangeStep(0, 10, 2).ToSlice())
+	})
 
-		assert.False(t, ok)
+	t.Run("Descending", func(t *testing.T) {
+		assert.Equal(t, []int{10, 7, 4, 1}, collection.NewFromRangeStep(10, 0, -3).ToSlice())
 	})
-	t.Run("Element_OkTrue", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		v, ok := c.First()
 
-		assert.True(t, ok)
-		assert.Equal(t, "a", v)
+	t.Run("UnevenStep", func(t *testing.T) {
+		assert.Equal(t, []int{1, 4, 7}, collection.NewFromRangeStep(1, 8, 3).ToSlice())
 	})
-}
 
-func TestFirstOrError(t *testing.T) {
-	t.Run("Element", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		v, err := c.FirstOrError()
+	t.Run("EmptyRange", func(t *testing.T) {
+		assert.True(t, collection.NewFromRangeStep(5, 5, 1).IsEmpty())
+		assert.True(t, collection.NewFromRangeStep(0, 5, -1).IsEmpty())
+	})
 
-		assert.Nil(t, err)
-		assert.Equal(t, "a", v)
+	t.Run("InvalidStep", func(t *testing.T) {
+		assert.Panics(t, func() { collection.NewFromRangeStep(0, 10, 0) })
 	})
 
-	t.Run("NoElement_Error", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{})
-		_, err := c.FirstOrError()
+	t.Run("NoOverflow", func(t *testing.T) {
+		result := collection.NewFromRangeStep(math.MaxInt-5, math.MaxInt, 4).ToSlice()
 
-		assert.NotNil(t, err)
-		assert.Equal(t, collection.ErrNoElement, err)
+		assert.Equal(t, []int{math.MaxInt - 5, math.MaxInt - 1}, result)
 	})
 }
 
-func TestLast(t *testing.T) {
-	t.Run("NoElement_OkFalse", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{})
-		_, ok := c.Last()
+func TestNewFromRangeStep64(t *testing.T) {
+	t.Run("Ascending", func(t *testing.T) {
+		start := int64(math.MaxInt32)
 
-		assert.False(t, ok)
+		assert.Equal(t, []int64{start, start + 5, start + 10}, collection.NewFromRangeStep64(start, start+15, 5).ToSlice())
 	})
-	t.Run("Element_OkTrue", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		v, ok := c.Last()
 
-		assert.True(t, ok)
-		assert.Equal(t, "c", v)
+	t.Run("Descending", func(t *testing.T) {
+		result := collection.NewFromRangeStep64(math.MinInt64+10, math.MinInt64, -6).ToSlice()
+
+		assert.Equal(t, []int64{math.MinInt64 + 10, math.MinInt64 + 4}, result)
+	})
+
+	t.Run("InvalidStep", func(t *testing.T) {
+		assert.Panics(t, func() { collection.NewFromRangeStep64(0, 10, 0) })
 	})
 }
 
-func TestLastOrError(t *testing.T) {
-	t.Run("Element", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		v, err := c.LastOrError()
+func TestNewFromFloatRange(t *testing.T) {
+	t.Run("Ascending", func(t *testing.T) {
+		result := collection.NewFromFloatRange(0, 1, 0.1).ToSlice()
 
-		assert.Nil(t, err)
-		assert.Equal(t, "c", v)
+		assert.Len(t, result, 10)
+		assert.Equal(t, 0.0, result[0])
+		assert.InDelta(t, 0.9, result[9], 1e-12)
 	})
 
-	t.Run("NoElement_Error", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{})
-		_, err := c.LastOrError()
+	t.Run("NoAccumulatedError", func(t *testing.T) {
+		result := collection.NewFromFloatRange(0, 100, 0.1).ToSlice()
 
-		assert.NotNil(t, err)
-		assert.Equal(t, collection.ErrNoElement, err)
+		assert.Len(t, result, 1000)
+		assert.Equal(t, 999*0.1, result[999])
 	})
-}
 
-func TestLen(t *testing.T) {
-	t.Run("NonEmptyCollection", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		v := c.Len()
+	t.Run("LargeSpan", func(t *testing.T) {
+		// 20552700/0.7 rounds to just above 29361000, beyond what an absolute tolerance absorbs
+		c := collection.NewFromFloatRange(0, 20552700, 0.7)
 
-		assert.Equal(t, 3, v)
+		assert.Equal(t, 29361000, c.Count())
 	})
 
-	t.Run("EmptyCollection", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{})
-		v := c.Len()
+	t.Run("Descending", func(t *testing.T) {
+		result := collection.NewFromFloatRange(1, 0, -0.25).ToSlice()
 
-		assert.Equal(t, 0, v)
+		assert.Equal(t, []float64{1, 0.75, 0.5, 0.25}, result)
 	})
-}
 
-func TestCount(t *testing.T) {
-	t.Run("NonEmptyCollection", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		v := c.Count()
+	t.Run("EmptyRange", func(t *testing.T) {
+		assert.True(t, collection.NewFromFloatRange(1, 1, 0.1).IsEmpty())
+		assert.True(t, collection.NewFromFloatRange(0, 1, -0.1).IsEmpty())
+	})
 
-		assert.Equal(t, 3, v)
+	t.Run("InvalidStep", func(t *testing.T) {
+		assert.PanicsWithValue(t, "collection: range step must not be zero or NaN", func() { collection.NewFromFloatRange(0, 1, 0) })
+		assert.PanicsWithValue(t, "collection: range step must not be zero or NaN", func() { collection.NewFromFloatRange(0, 1, math.NaN()) })
 	})
+}
 
-	t.Run("EmptyCollection", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{})
-		v := c.Count()
+func TestUnfold(t *testing.T) {
+	t.Run("Fibonacci", func(t *testing.T) {
+		fib := collection.Unfold([2]int{0, 1}, func(s [2]int) (int, [2]int, bool) {
+			return s[0], [2]int{s[1], s[0] + s[1]}, true
+		})
 
-		assert.Equal(t, 0, v)
+		assert.Equal(t, []int{0, 1, 1, 2, 3, 5, 8, 13}, fib.Take(8).ToSlice())
 	})
-}
 
-func TestContains(t *testing.T) {
-	c := collection.NewFromSlice([]string{"a", "b", "c"})
-	t.Run("True", func(t *testing.T) {
-		v := c.Contains(func(x string) bool {
-			return x == "a"
+	t.Run("Finite", func(t *testing.T) {
+		countdown := collection.Unfold(3, func(n int) (int, int, bool) {
+			return n, n - 1, n > 0
 		})
 
-		assert.True(t, v)
+		assert.Equal(t, []int{3, 2, 1}, countdown.ToSlice())
 	})
 
-	t.Run("False", func(t *testing.T) {
-		v := c.Contains(func(x string) bool {
-			return x == "z"
+	t.Run("Break", func(t *testing.T) {
+		calls := 0
+		c := collection.Unfold(0, func(n int) (int, int, bool) {
+			calls++
+			return n, n + 1, true
 		})
 
-		assert.False(t, v)
+		for range *c {
+			break
+		}
+
+		assert.Equal(t, 1, calls)
 	})
 }
 
-func TestIsEmpty(t *testing.T) {
-	t.Run("Empty", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{})
-		v := c.IsEmpty()
+func TestIterate(t *testing.T) {
+	double := func(x int) int { return x * 2 }
 
-		assert.True(t, v)
+	t.Run("PowersOfTwo", func(t *testing.T) {
+		assert.Equal(t, []int{1, 2, 4, 8, 16}, collection.Iterate(1, double).Take(5).ToSlice())
 	})
 
-	t.Run("NotEmpty", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a"})
-		v := c.IsEmpty()
+	t.Run("TakeWhile", func(t *testing.T) {
+		result := collection.Iterate(1, double).TakeWhile(func(x int) bool { return x < 100 }).ToSlice()
 
-		assert.False(t, v)
+		assert.Equal(t, []int{1, 2, 4, 8, 16, 32, 64}, result)
 	})
-}
 
-func TestSingle(t *testing.T) {
-	t.Run("OneElement", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a"})
-		v, ok := c.Single()
+	t.Run("Break", func(t *testing.T) {
+		calls := 0
+		c := collection.Iterate(1, func(x int) int {
+			calls++
+			return x + 1
+		})
 
-		assert.True(t, ok)
-		assert.Equal(t, "a", v)
+		for range *c {
+			break
+		}
+
+		assert.Equal(t, 0, calls)
 	})
+}
 
-	t.Run("MultipleElements", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b"})
-		_, ok := c.Single()
+func TestNewFromJSON(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		data := []byte(`["a", "b", "c"]`)
+		c, err := collection.NewFromJSON[string](data)
 
-		assert.False(t, ok)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, c.Count())
 	})
 
-	t.Run("NoElements", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{})
-		_, ok := c.Single()
+	t.Run("InvalidJSON", func(t *testing.T) {
+		data := []byte(`invalid`)
+		_, err := collection.NewFromJSON[string](data)
 
-		assert.False(t, ok)
+		assert.NotNil(t, err)
 	})
 }
 
-func TestSingleOrError(t *testing.T) {
-	t.Run("OneElement", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a"})
-		v, err := c.SingleOrError()
+func TestNewFromJSONReader(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		c := collection.NewFromJSONReader[string](strings.NewReader(`["a", "b", "c"]`))
 
-		assert.Nil(t, err)
-		assert.Equal(t, "a", v)
+		assert.Equal(t, []string{"a", "b", "c"}, c.ToSlice())
+		assert.NoError(t, c.Err())
 	})
 
-	t.Run("MultipleElements", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b"})
-		_, err := c.SingleOrError()
+	t.Run("Structs", func(t *testing.T) {
+		type item struct {
+			Name string `json:"name"`
+		}
+		c := collection.NewFromJSONReader[item](strings.NewReader(`[{"name": "a"}, {"name": "b"}]`))
 
-		assert.NotNil(t, err)
-		assert.Equal(t, collection.ErrNotExactlyOneElement, err)
+		assert.Equal(t, []item{{Name: "a"}, {Name: "b"}}, c.ToSlice())
+		assert.NoError(t, c.Err())
 	})
 
-	t.Run("NoElements", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{})
-		_, err := c.SingleOrError()
+	t.Run("EnumeratedTwice", func(t *testing.T) {
+		c := collection.NewFromJSONReader[int](strings.NewReader(`[1, 2, 3]`))
 
-		assert.NotNil(t, err)
-		assert.Equal(t, collection.ErrNotExactlyOneElement, err)
+		assert.Equal(t, 3, c.Count())
+		assert.NoError(t, c.Err())
+
+		assert.Empty(t, c.ToSlice())
+		assert.ErrorIs(t, c.Err(), collection.ErrConsumed)
 	})
-}
 
-func TestShuffle(t *testing.T) {
-	t.Run("ShuffleElements", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e"})
-		shuffled := c.Shuffle().ToSlice()
+	t.Run("EmptyArray", func(t *testing.T) {
+		c := collection.NewFromJSONReader[int](strings.NewReader(`[]`))
 
-		assert.Equal(t, 5, len(shuffled))
-		assert.Contains(t, shuffled, "a")
-		assert.Contains(t, shuffled, "b")
-		assert.Contains(t, shuffled, "c")
-		assert.Contains(t, shuffled, "d")
-		assert.Contains(t, shuffled, "e")
+		assert.True(t, c.IsEmpty())
+		assert.NoError(t, c.Err())
+	})
 
-		// Check that the order is different
-		if slices.Equal(shuffled, []string{"a", "b", "c", "d", "e"}) {
-			t.Error("Expected the order to be different after shuffle")
+	t.Run("LargeArrayDecodedIncrementally", func(t *testing.T) {
+		var b strings.Builder
+		b.WriteString("[")
+		for i := range 100000 {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			b.WriteString(strconv.Itoa(i))
 		}
+		b.WriteString("]")
+		r := &countingReader{r: strings.NewReader(b.String())}
+		c := collection.NewFromJSONReader[int](r)
+
+		assert.Equal(t, []int{0, 1, 2, 3, 4}, c.Take(5).ToSlice())
+		assert.NoError(t, c.Err())
+		assert.Less(t, r.n, b.Len()/10)
 	})
 
-	t.Run("ShuffleEmptyCollection", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{})
-		shuffled := c.Shuffle().ToSlice()
+	t.Run("MalformedMidArray", func(t *testing.T) {
+		c := collection.NewFromJSONReader[int](strings.NewReader(`[1, 2, "three", 4]`))
 
-		assert.Equal(t, 0, len(shuffled))
+		assert.Equal(t, []int{1, 2}, c.ToSlice())
+		assert.ErrorContains(t, c.Err(), "element 2")
 	})
 
-	t.Run("ShuffleSingleElement", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a"})
-		shuffled := c.Shuffle().ToSlice()
+	t.Run("NotAnArray", func(t *testing.T) {
+		c := collection.NewFromJSONReader[int](strings.NewReader(`{"a": 1}`))
 
-		assert.Equal(t, 1, len(shuffled))
-		assert.Equal(t, "a", shuffled[0])
+		assert.True(t, c.IsEmpty())
+		assert.Error(t, c.Err())
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		c := collection.NewFromJSONReader[int](strings.NewReader(`[1, 2`))
+
+		assert.Equal(t, []int{1, 2}, c.ToSlice())
+		assert.Error(t, c.Err())
 	})
 }
 
-func TestDistinct(t *testing.T) {
-	t.Run("StringsWithDuplicates", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "a", "c", "b"})
-		result := c.Distinct(func(a, b string) bool {
-			return a == b
-		}).ToSlice()
+// countingReader records how many bytes have been read from r
+type countingReader struct {
+	r io.Reader
+	n int
+}
 
-		assert.Equal(t, 3, len(result))
-		assert.Contains(t, result, "a")
-		assert.Contains(t, result, "b")
-		assert.Contains(t, result, "c")
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestNewFromNDJSON(t *testing.T) {
+	type event struct {
+		ID   int    `json:"id"`
+		Kind string `json:"kind"`
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		c := collection.NewFromNDJSON[event](strings.NewReader("{\"id\":1,\"kind\":\"a\"}\n{\"id\":2,\"kind\":\"b\"}\n"))
+
+		assert.Equal(t, []event{{1, "a"}, {2, "b"}}, c.ToSlice())
+		assert.NoError(t, c.Err())
 	})
 
-	t.Run("EmptyCollection", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{})
-		result := c.Distinct(func(a, b string) bool {
-			return a == b
-		}).ToSlice()
+	t.Run("BlankLinesAndNoTrailingNewline", func(t *testing.T) {
+		c := collection.NewFromNDJSON[int](strings.NewReader("\n1\n\n  \r\n2\r\n3"))
 
-		assert.Equal(t, 0, len(result))
+		assert.Equal(t, []int{1, 2, 3}, c.ToSlice())
+		assert.NoError(t, c.Err())
 	})
 
-	t.Run("CustomEquality", func(t *testing.T) {
-		type person struct {
-			ID   int
-			Name string
-		}
+	t.Run("EnumeratedTwice", func(t *testing.T) {
+		c := collection.NewFromNDJSON[int](strings.NewReader("1\n2\n3\n"))
 
-		c := collection.NewFromSlice([]person{
-			{ID: 1, Name: "Alice"},
-			{ID: 2, Name: "Bob"},
-			{ID: 3, Name: "alice"}, // Different case, same name
-		})
+		assert.Equal(t, 3, c.Count())
+		assert.NoError(t, c.Err())
 
-		result := c.Distinct(func(a, b person) bool {
-			return strings.EqualFold(a.Name, b.Name)
-		}).ToSlice()
+		assert.Empty(t, c.ToSlice())
+		assert.ErrorIs(t, c.Err(), collection.ErrConsumed)
+	})
 
-		assert.Equal(t, 2, len(result))
+	t.Run("EmptyInput", func(t *testing.T) {
+		c := collection.NewFromNDJSON[int](strings.NewReader(""))
+
+		assert.True(t, c.IsEmpty())
+		assert.NoError(t, c.Err())
 	})
 
-	t.Run("Break", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "a", "c", "b"})
-		for range *c.Distinct(func(a, b string) bool {
-			return a == b
-		}) {
-			break
-		}
+	t.Run("InvalidLine", func(t *testing.T) {
+		c := collection.NewFromNDJSON[int](strings.NewReader("1\n\n2\nnope\n4\n"))
+
+		assert.Equal(t, []int{1, 2}, c.ToSlice())
+		assert.ErrorContains(t, c.Err(), "line 4")
 	})
-}
 
-func TestSkip(t *testing.T) {
-	t.Run("SkipSome", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e"})
-		result := c.Skip(2).ToSlice()
+	t.Run("ReadError", func(t *testing.T) {
+		readErr := errors.New("read failed")
+		c := collection.NewFromNDJSON[int](iotest.ErrReader(readErr))
 
-		assert.Equal(t, 3, len(result))
-		assert.Equal(t, "c", result[0])
-		assert.Equal(t, "d", result[1])
-		assert.Equal(t, "e", result[2])
+		assert.True(t, c.IsEmpty())
+		assert.ErrorIs(t, c.Err(), readErr)
 	})
+}
 
-	t.Run("SkipAll", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		result := c.Skip(3).ToSlice()
+func TestNewFromLines(t *testing.T) {
+	t.Run("Lines", func(t *testing.T) {
+		c := collection.NewFromLines(strings.NewReader("a\nb\n\nc\n"))
 
-		assert.Equal(t, 0, len(result))
+		assert.Equal(t, []string{"a", "b", "", "c"}, c.ToSlice())
+		assert.NoError(t, c.Err())
 	})
 
-	t.Run("SkipMore", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		result := c.Skip(5).ToSlice()
+	t.Run("CRLF", func(t *testing.T) {
+		c := collection.NewFromLines(strings.NewReader("a\r\nb\r\n"))
 
-		assert.Equal(t, 0, len(result))
+		assert.Equal(t, []string{"a", "b"}, c.ToSlice())
+		assert.NoError(t, c.Err())
 	})
 
-	t.Run("SkipZero", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		result := c.Skip(0).ToSlice()
+	t.Run("FinalLineWithoutNewline", func(t *testing.T) {
+		c := collection.NewFromLines(strings.NewReader("a\nb"))
 
-		assert.Equal(t, 3, len(result))
-		assert.Equal(t, "a", result[0])
-		assert.Equal(t, "b", result[1])
-		assert.Equal(t, "c", result[2])
+		assert.Equal(t, []string{"a", "b"}, c.ToSlice())
+		assert.NoError(t, c.Err())
 	})
 
-	t.Run("Break", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e"})
-		for range *c.Skip(2) {
-			break
-		}
-	})
-}
+	t.Run("EnumeratedTwice", func(t *testing.T) {
+		c := collection.NewFromLines(strings.NewReader("a\nb\n"))
 
-func TestSkipUntil(t *testing.T) {
-	t.Run("SkipUntilSome", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e"})
-		result := c.SkipUntil(func(x string) bool {
-			return x == "d"
-		}).ToSlice()
+		assert.Equal(t, 2, c.Count())
+		assert.NoError(t, c.Err())
 
-		assert.Equal(t, 2, len(result))
-		assert.Equal(t, "d", result[0])
-		assert.Equal(t, "e", result[1])
+		assert.Empty(t, c.ToSlice())
+		assert.ErrorIs(t, c.Err(), collection.ErrConsumed)
 	})
 
-	t.Run("SkipUntilAll", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		result := c.SkipUntil(func(x string) bool {
-			return x == "z"
-		}).ToSlice()
+	t.Run("EmptyInput", func(t *testing.T) {
+		c := collection.NewFromLines(strings.NewReader(""))
 
-		assert.Equal(t, 0, len(result))
+		assert.True(t, c.IsEmpty())
+		assert.NoError(t, c.Err())
 	})
 
-	t.Run("SkipUntilNone", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		result := c.SkipUntil(func(x string) bool {
-			return x == "a"
-		}).ToSlice()
+	t.Run("LineTooLong", func(t *testing.T) {
+		long := strings.Repeat("x", 100)
+		c := collection.NewFromLinesWithLimit(strings.NewReader("short\n"+long+"\nafter\n"), 64)
 
-		assert.Equal(t, 3, len(result))
+		assert.Equal(t, []string{"short"}, c.ToSlice())
+		assert.ErrorIs(t, c.Err(), bufio.ErrTooLong)
 	})
 
-	t.Run("Break", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e"})
-		for range *c.SkipUntil(func(x string) bool {
-			return x == "d"
-		}) {
-			break
+	t.Run("LineAtLimit", func(t *testing.T) {
+		line := strings.Repeat("x", 64)
+
+		for _, ending := range []string{"\n", "\r\n", ""} {
+			c := collection.NewFromLinesWithLimit(strings.NewReader("a\n"+line+ending), 64)
+
+			assert.Equal(t, []string{"a", line}, c.ToSlice(), "ending %q", ending)
+			assert.NoError(t, c.Err(), "ending %q", ending)
 		}
 	})
-}
 
-func TestSkipLast(t *testing.T) {
-	t.Run("SkipSome", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e"})
-		result := c.SkipLast(2).ToSlice()
+	t.Run("LineOneOverLimit", func(t *testing.T) {
+		line := strings.Repeat("x", 65)
 
-		assert.Equal(t, 3, len(result))
-		assert.Equal(t, "a", result[0])
-		assert.Equal(t, "b", result[1])
-		assert.Equal(t, "c", result[2])
+		for _, ending := range []string{"\n", "\r\n", ""} {
+			c := collection.NewFromLinesWithLimit(strings.NewReader("a\n"+line+ending), 64)
+
+			assert.Equal(t, []string{"a"}, c.ToSlice(), "ending %q", ending)
+			assert.ErrorIs(t, c.Err(), bufio.ErrTooLong, "ending %q", ending)
+		}
 	})
 
-	t.Run("SkipAll", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		result := c.SkipLast(3).ToSlice()
+	t.Run("InvalidLimit", func(t *testing.T) {
+		for _, limit := range []int{0, -1} {
+			c := collection.NewFromLinesWithLimit(strings.NewReader("a\n"), limit)
 
-		assert.Equal(t, 0, len(result))
+			assert.Empty(t, c.ToSlice())
+			assert.ErrorIs(t, c.Err(), collection.ErrInvalidLineSize)
+		}
 	})
 
-	t.Run("SkipMore", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		result := c.SkipLast(5).ToSlice()
+	t.Run("LongLineWithinLimit", func(t *testing.T) {
+		long := strings.Repeat("x", 100000)
+		c := collection.NewFromLinesWithLimit(strings.NewReader("a\n"+long+"\nb"), 200000)
 
-		assert.Equal(t, 0, len(result))
+		assert.Equal(t, []string{"a", long, "b"}, c.ToSlice())
+		assert.NoError(t, c.Err())
 	})
 
-	t.Run("SkipZero", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		result := c.SkipLast(0).ToSlice()
+	t.Run("EarlyBreakLeavesReaderOpen", func(t *testing.T) {
+		r := &closeTrackingReader{Reader: strings.NewReader("a\nb\nc\n")}
+		c := collection.NewFromLines(r)
 
-		assert.Equal(t, 3, len(result))
-		assert.Equal(t, "a", result[0])
-		assert.Equal(t, "b", result[1])
-		assert.Equal(t, "c", result[2])
+		assert.Equal(t, []string{"a"}, c.Take(1).ToSlice())
+		assert.NoError(t, c.Err())
+		assert.False(t, r.closed)
 	})
 
-	t.Run("Break", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e"})
-		for range *c.SkipLast(2) {
-			break
-		}
+	t.Run("ReadError", func(t *testing.T) {
+		readErr := errors.New("read failed")
+		c := collection.NewFromLines(io.MultiReader(strings.NewReader("a\n"), iotest.ErrReader(readErr)))
+
+		assert.Equal(t, []string{"a"}, c.ToSlice())
+		assert.ErrorIs(t, c.Err(), readErr)
 	})
 }
 
-func TestTake(t *testing.T) {
-	t.Run("TakeSome", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e"})
-		result := c.Take(3).ToSlice()
+// closeTrackingReader records whether Close has been called
+type closeTrackingReader struct {
+	io.Reader
+	closed bool
+}
 
-		assert.Equal(t, 3, len(result))
-		assert.Equal(t, "a", result[0])
-		assert.Equal(t, "b", result[1])
-		assert.Equal(t, "c", result[2])
+func (r *closeTrackingReader) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestNewFromScanner(t *testing.T) {
+	t.Run("FullConsumption", func(t *testing.T) {
+		i := 0
+		c := collection.NewFromScanner(func() bool { i++; return i <= 3 }, func() int { return i * 10 }, func() error { return nil })
+
+		assert.Equal(t, []int{10, 20, 30}, c.ToSlice())
+		assert.NoError(t, c.Err())
 	})
 
-	t.Run("TakeAll", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		result := c.Take(3).ToSlice()
+	t.Run("EarlyBreakStopsCallingNext", func(t *testing.T) {
+		calls := 0
+		c := collection.NewFromScanner(func() bool { calls++; return true }, func() int { return calls }, func() error { return nil })
 
-		assert.Equal(t, 3, len(result))
+		assert.Equal(t, []int{1, 2}, c.Take(2).ToSlice())
+		assert.Equal(t, 2, calls)
 	})
 
-	t.Run("TakeMore", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		result := c.Take(5).ToSlice()
+	t.Run("TerminalError", func(t *testing.T) {
+		cursorErr := errors.New("cursor failed")
+		i := 0
+		c := collection.NewFromScanner(func() bool { i++; return i <= 2 }, func() int { return i }, func() error { return cursorErr })
 
-		assert.Equal(t, 3, len(result))
+		assert.NoError(t, c.Err())
+		assert.Equal(t, []int{1, 2}, c.ToSlice())
+		assert.ErrorIs(t, c.Err(), cursorErr)
 	})
 
-	t.Run("TakeZero", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		result := c.Take(0).ToSlice()
+	t.Run("EnumeratedTwice", func(t *testing.T) {
+		i := 0
+		c := collection.NewFromScanner(func() bool { i++; return i <= 3 }, func() int { return i }, func() error { return nil })
 
-		assert.Equal(t, 0, len(result))
+		assert.Equal(t, 3, c.Count())
+		assert.NoError(t, c.Err())
+
+		assert.Empty(t, c.ToSlice())
+		assert.ErrorIs(t, c.Err(), collection.ErrConsumed)
+		assert.Equal(t, 4, i)
 	})
 
-	t.Run("Break", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e"})
-		for range *c.Take(3) {
-			break
-		}
+	t.Run("BufioScannerWords", func(t *testing.T) {
+		s := bufio.NewScanner(strings.NewReader("the quick  brown\nfox"))
+		s.Split(bufio.ScanWords)
+		c := collection.NewFromBufioScanner(s)
+
+		assert.Equal(t, []string{"the", "quick", "brown", "fox"}, c.ToSlice())
+		assert.NoError(t, c.Err())
+	})
+
+	t.Run("BufioScannerError", func(t *testing.T) {
+		readErr := errors.New("read failed")
+		s := bufio.NewScanner(io.MultiReader(strings.NewReader("a\nb\n"), iotest.ErrReader(readErr)))
+		c := collection.NewFromBufioScanner(s)
+
+		assert.Equal(t, []string{"a", "b"}, c.ToSlice())
+		assert.ErrorIs(t, c.Err(), readErr)
 	})
 }
 
-func TestTakeUntil(t *testing.T) {
-	t.Run("TakeUntilSome", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e"})
-		result := c.TakeUntil(func(x string) bool {
-			return x == "d"
-		}).ToSlice()
+func TestNewFromRows(t *testing.T) {
+	db, err := sql.Open("collectiontest", "")
+	assert.NoError(t, err)
+	defer db.Close()
 
-		assert.Equal(t, 3, len(result))
-		assert.Equal(t, "a", result[0])
-		assert.Equal(t, "b", result[1])
-		assert.Equal(t, "c", result[2])
+	scanInt := func(rows *sql.Rows) (int, error) {
+		var v int
+		err := rows.Scan(&v)
+		return v, err
+	}
+
+	t.Run("AllRows", func(t *testing.T) {
+		rows, err := db.Query("5")
+		assert.NoError(t, err)
+
+		c := collection.NewFromRows(rows, scanInt)
+
+		assert.Equal(t, []int{0, 1, 2, 3, 4}, c.ToSlice())
+		assert.NoError(t, c.Err())
+		assert.Equal(t, int32(0), openFakeRows.Load())
 	})
 
-	t.Run("TakeUntilAll", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		result := c.TakeUntil(func(x string) bool {
-			return x == "z"
-		}).ToSlice()
+	t.Run("EnumeratedTwice", func(t *testing.T) {
+		rows, err := db.Query("3")
+		assert.NoError(t, err)
 
-		assert.Equal(t, 3, len(result))
+		c := collection.NewFromRows(rows, scanInt)
+
+		assert.Equal(t, 3, c.Count())
+		assert.NoError(t, c.Err())
+
+		assert.Empty(t, c.ToSlice())
+		assert.ErrorIs(t, c.Err(), collection.ErrConsumed)
 	})
 
-	t.Run("TakeUntilNone", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		result := c.TakeUntil(func(x string) bool {
-			return x == "a"
-		}).ToSlice()
+	t.Run("NoRows", func(t *testing.T) {
+		rows, err := db.Query("0")
+		assert.NoError(t, err)
 
-		assert.Equal(t, 0, len(result))
+		c := collection.NewFromRows(rows, scanInt)
+
+		assert.True(t, c.IsEmpty())
+		assert.NoError(t, c.Err())
+		assert.Equal(t, int32(0), openFakeRows.Load())
 	})
 
-	t.Run("Break", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e"})
-		for range *c.TakeUntil(func(x string) bool {
-			return x == "d"
-		}) {
-			break
-		}
+	t.Run("EarlyBreakClosesRows", func(t *testing.T) {
+		rows, err := db.Query("100")
+		assert.NoError(t, err)
+
+		c := collection.NewFromRows(rows, scanInt)
+
+		assert.Equal(t, []int{0, 1, 2}, c.Take(3).ToSlice())
+		assert.NoError(t, c.Err())
+		assert.Equal(t, int32(0), openFakeRows.Load())
 	})
-}
 
-func TestTakeWhile(t *testing.T) {
-	t.Run("TakeWhileSome", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{1, 2, 3, 4})
-		result := c.TakeWhile(func(x int) bool {
-			return x < 3
-		}).ToSlice()
+	t.Run("ScanError", func(t *testing.T) {
+		rows, err := db.Query("5")
+		assert.NoError(t, err)
 
-		assert.Equal(t, 2, len(result))
-		assert.Equal(t, 1, result[0])
-		assert.Equal(t, 2, result[1])
+		scanErr := errors.New("scan failed")
+		c := collection.NewFromRows(rows, func(rows *sql.Rows) (int, error) {
+			v, err := scanInt(rows)
+			if v == 2 {
+				return 0, scanErr
+			}
+			return v, err
+		})
+
+		assert.Equal(t, []int{0, 1}, c.ToSlice())
+		assert.ErrorIs(t, c.Err(), scanErr)
+		assert.ErrorContains(t, c.Err(), "element 2")
+		assert.Equal(t, int32(0), openFakeRows.Load())
 	})
 
-	t.Run("TakeWhileAll", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{1, 2, 3, 4})
-		result := c.TakeWhile(func(x int) bool {
-			return x < 5
+	t.Run("RowsError", func(t *testing.T) {
+		rows, err := db.Query("3 fail")
+		assert.NoError(t, err)
+
+		c := collection.NewFromRows(rows, scanInt)
+
+		assert.Equal(t, []int{0, 1, 2}, c.ToSlice())
+		assert.ErrorIs(t, c.Err(), errFakeRows)
+		assert.Equal(t, int32(0), openFakeRows.Load())
+	})
+}
+
+// fakeDriver is a minimal database/sql driver whose queries return a single integer column. The query text is the
+// number of rows, optionally followed by "fail" to report an error after the last row
+type fakeDriver struct{}
+
+type fakeConn struct{}
+
+type fakeStmt struct {
+	query string
+}
+
+type fakeRows struct {
+	count, next int
+	fail        bool
+	closed      bool
+}
+
+var (
+	openFakeRows atomic.Int32
+	errFakeRows  = errors.New("fake rows failed")
+)
+
+func init() {
+	sql.Register("collectiontest", fakeDriver{})
+}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{query: query}, nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+func (fakeStmt) Close() error  { return nil }
+func (fakeStmt) NumInput() int { return 0 }
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	fields := strings.Fields(s.query)
+	count, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return nil, err
+	}
+	openFakeRows.Add(1)
+	return &fakeRows{count: count, fail: len(fields) > 1 && fields[1] == "fail"}, nil
+}
+
+func (r *fakeRows) Columns() []string { return []string{"v"} }
+
+func (r *fakeRows) Close() error {
+	if !r.closed {
+		r.closed = true
+		openFakeRows.Add(-1)
+	}
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= r.count {
+		if r.fail {
+			return errFakeRows
+		}
+		return io.EOF
+	}
+	dest[0] = int64(r.next)
+	r.next++
+	return nil
+}
+
+func TestWhere(t *testing.T) {
+	c := collection.NewFromSlice([]string{"a", "b", "c"})
+	t.Run("Elements", func(t *testing.T) {
+		v := c.Where(func(x string) bool {
+			return x == "a"
 		}).ToSlice()
 
-		assert.Equal(t, 4, len(result))
-		assert.Equal(t, 1, result[0])
-		assert.Equal(t, 2, result[1])
-		assert.Equal(t, 3, result[2])
-		assert.Equal(t, 4, result[3])
+		assert.Len(t, v, 1)
+		assert.Equal(t, "a", v[0])
 	})
 
-	t.Run("TakeWhileNone", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{})
-		result := c.TakeWhile(func(x int) bool {
-			return x < 5
+	t.Run("NoElements", func(t *testing.T) {
+		v := c.Where(func(x string) bool {
+			return x == "z"
 		}).ToSlice()
 
-		assert.Equal(t, 0, len(result))
+		assert.Len(t, v, 0)
 	})
 
 	t.Run("Break", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{1, 2, 3, 4})
-		for range *c.TakeWhile(func(x int) bool {
-			return x < 5
+		for range *c.Where(func(x string) bool {
+			return x == "a"
 		}) {
 			break
 		}
 	})
+
+	t.Run("ReceiverMutatedAfterBuild", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4})
+		evens := c.Where(func(x int) bool { return x%2 == 0 })
+		c.Pop()
+
+		assert.Equal(t, []int{2}, evens.ToSlice())
+	})
 }
 
-func TestTakeLast(t *testing.T) {
-	t.Run("TakeSome", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e"})
-		result := c.TakeLast(3).ToSlice()
+func TestWhereOrError(t *testing.T) {
+	errLookup := errors.New("lookup failed")
+	isEven := func(x int) (bool, error) {
+		if x < 0 {
+			return false, errLookup
+		}
+		return x%2 == 0, nil
+	}
 
-		assert.Equal(t, 3, len(result))
-		assert.Equal(t, "c", result[0])
-		assert.Equal(t, "d", result[1])
-		assert.Equal(t, "e", result[2])
+	t.Run("NoError", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4})
+		result, err := c.WhereOrError(isEven)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []int{2, 4}, result.ToSlice())
 	})
 
-	t.Run("TakeAll", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		result := c.TakeLast(3).ToSlice()
+	t.Run("ErrorOnFirstElement", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{-1, 2})
+		result, err := c.WhereOrError(isEven)
 
-		assert.Equal(t, 3, len(result))
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, errLookup)
+		assert.ErrorContains(t, err, "element 0")
 	})
 
-	t.Run("TakeMore", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		result := c.TakeLast(5).ToSlice()
+	t.Run("ErrorMidStream", func(t *testing.T) {
+		calls := 0
+		c := collection.NewFromSlice([]int{2, 4, 5, -1, 6})
+		result, err := c.WhereOrError(func(x int) (bool, error) {
+			calls++
+			return isEven(x)
+		})
 
-		assert.Equal(t, 3, len(result))
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, errLookup)
+		assert.ErrorContains(t, err, "element 3")
+		assert.Equal(t, 4, calls)
 	})
+}
 
-	t.Run("TakeZero", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		result := c.TakeLast(0).ToSlice()
+func TestWhereIndexed(t *testing.T) {
+	t.Run("SkipHeader", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"header", "a", "b"})
+		result := c.WhereIndexed(func(i int, x string) bool {
+			return i > 0
+		}).ToSlice()
 
-		assert.Equal(t, 0, len(result))
+		assert.Equal(t, []string{"a", "b"}, result)
 	})
 
-	t.Run("Break", func(t *testing.T) {
+	t.Run("SourceIndex", func(t *testing.T) {
 		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e"})
-		for range *c.TakeLast(3) {
-			break
-		}
-	})
-}
+		result := c.Where(func(x string) bool {
+			return x != "b"
+		}).WhereIndexed(func(i int, x string) bool {
+			return i%2 == 0
+		}).ToSlice()
 
-func TestSkipWhile(t *testing.T) {
-	t.Run("SkipWhileSome", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{1, 2, 3, 4})
-		result := c.SkipWhile(func(x int) bool {
-			return x < 3
+		assert.Equal(t, []string{"a", "d"}, result)
+
+		var indexes []int
+		c.WhereIndexed(func(i int, x string) bool {
+			indexes = append(indexes, i)
+			return x == "c" || x == "e"
 		}).ToSlice()
 
-		assert.Equal(t, 2, len(result))
-		assert.Equal(t, 3, result[0])
-		assert.Equal(t, 4, result[1])
+		assert.Equal(t, []int{0, 1, 2, 3, 4}, indexes)
 	})
 
-	t.Run("SkipWhileAll", func(t *testing.T) {
+	t.Run("Break", func(t *testing.T) {
+		calls := 0
 		c := collection.NewFromSlice([]int{1, 2, 3, 4})
-		result := c.SkipWhile(func(x int) bool {
-			return x < 1
-		}).ToSlice()
+		for range *c.WhereIndexed(func(i int, x int) bool {
+			calls++
+			return true
+		}) {
+			break
+		}
 
-		assert.Equal(t, 4, len(result))
-		assert.Equal(t, 1, result[0])
-		assert.Equal(t, 2, result[1])
-		assert.Equal(t, 3, result[2])
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestReject(t *testing.T) {
+	c := collection.NewFromSlice([]string{"a", "b", "c"})
+	t.Run("Elements", func(t *testing.T) {
+		v := c.Reject(func(x string) bool {
+			return x == "a"
+		}).ToSlice()
 
+		assert.Len(t, v, 2)
+		assert.Equal(t, "b", v[0])
+		assert.Equal(t, "c", v[1])
 	})
 
-	t.Run("SkipWhileNone", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{1, 2, 3, 4})
-		result := c.SkipWhile(func(x int) bool {
-			return x < 5
+	t.Run("NoElements", func(t *testing.T) {
+		v := c.Reject(func(x string) bool {
+			return x == "z"
 		}).ToSlice()
 
-		assert.Equal(t, 0, len(result))
+		assert.Len(t, v, 3)
 	})
 
 	t.Run("Break", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{1, 2, 3, 4})
-		for range *c.SkipWhile(func(x int) bool {
-			return false
+		for range *c.Reject(func(x string) bool {
+			return x == "a"
 		}) {
 			break
 		}
 	})
 }
 
-func TestAny(t *testing.T) {
-	t.Run("True", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		v := c.Any(func(x string) bool {
-			return x == "b"
-		})
-		assert.True(t, v)
-	})
+func TestWhereFollowedBy(t *testing.T) {
+	isContinuation := func(x string) bool { return strings.HasPrefix(x, " ") }
 
-	t.Run("False", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		v := c.Any(func(x string) bool {
-			return x == "d"
-		})
-		assert.False(t, v)
-	})
+	t.Run("Elements", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", " a1", "b", "c", " c1", " c2"})
+		v := c.WhereFollowedBy(isContinuation).ToSlice()
 
-	t.Run("EmptyCollection", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{})
-		v := c.Any(func(x string) bool {
-			return true
-		})
-		assert.False(t, v)
+		assert.Equal(t, []string{"a", "c", " c1"}, v)
 	})
-}
 
-func TestNone(t *testing.T) {
-	t.Run("True", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		v := c.None(func(x string) bool {
-			return x == "d"
-		})
-		assert.True(t, v)
+	t.Run("LastElementNotFollowed", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b"})
+		v := c.WhereFollowedBy(func(x string) bool { return true }).ToSlice()
+
+		assert.Equal(t, []string{"a"}, v)
 	})
 
-	t.Run("False", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		v := c.None(func(x string) bool {
-			return x == "b"
-		})
-		assert.False(t, v)
+	t.Run("SingleElement", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a"})
+		v := c.WhereFollowedBy(func(x string) bool { return true }).ToSlice()
+
+		assert.Len(t, v, 0)
 	})
 
 	t.Run("EmptyCollection", func(t *testing.T) {
 		c := collection.NewFromSlice([]string{})
-		v := c.None(func(x string) bool {
-			return true
-		})
-		assert.True(t, v)
+		v := c.WhereFollowedBy(isContinuation).ToSlice()
+
+		assert.Len(t, v, 0)
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", " a1", "b", " b1"})
+		for range *c.WhereFollowedBy(isContinuation) {
+			break
+		}
 	})
 }
 
-func TestOrderBy(t *testing.T) {
-	t.Run("IntAscending", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{3, 1, 4, 2})
-		result := c.OrderBy(func(x int) any {
-			return x
-		}, true).ToSlice()
+func TestWhereNotFollowedBy(t *testing.T) {
+	isContinuation := func(x string) bool { return strings.HasPrefix(x, " ") }
 
-		assert.Equal(t, 4, len(result))
-		assert.Equal(t, 1, result[0])
-		assert.Equal(t, 2, result[1])
-		assert.Equal(t, 3, result[2])
-		assert.Equal(t, 4, result[3])
+	t.Run("Elements", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", " a1", "b", "c", " c1", " c2"})
+		v := c.WhereNotFollowedBy(isContinuation).ToSlice()
+
+		assert.Equal(t, []string{" a1", "b", " c2"}, v)
 	})
 
-	t.Run("IntDescending", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{3, 1, 4, 2})
-		result := c.OrderBy(func(x int) any {
-			return x
-		}, false).ToSlice()
+	t.Run("LastElementIncluded", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b"})
+		v := c.WhereNotFollowedBy(func(x string) bool { return true }).ToSlice()
 
-		assert.Equal(t, 4, len(result))
-		assert.Equal(t, 4, result[0])
-		assert.Equal(t, 3, result[1])
-		assert.Equal(t, 2, result[2])
-		assert.Equal(t, 1, result[3])
+		assert.Equal(t, []string{"b"}, v)
 	})
 
-	t.Run("Int8Ascending", func(t *testing.T) {
-		c := collection.NewFromSlice([]int8{3, 1, 4, 2})
-		result := c.OrderBy(func(x int8) any {
-			return x
-		}, true).ToSlice()
+	t.Run("SingleElement", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a"})
+		v := c.WhereNotFollowedBy(func(x string) bool { return true }).ToSlice()
 
-		assert.Equal(t, 4, len(result))
-		assert.Equal(t, int8(1), result[0])
-		assert.Equal(t, int8(2), result[1])
-		assert.Equal(t, int8(3), result[2])
-		assert.Equal(t, int8(4), result[3])
+		assert.Equal(t, []string{"a"}, v)
 	})
 
-	t.Run("Int16Ascending", func(t *testing.T) {
-		c := collection.NewFromSlice([]int16{3, 1, 4, 2})
-		result := c.OrderBy(func(x int16) any {
-			return x
-		}, true).ToSlice()
+	t.Run("EmptyCollection", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{})
+		v := c.WhereNotFollowedBy(isContinuation).ToSlice()
 
-		assert.Equal(t, 4, len(result))
-		assert.Equal(t, int16(1), result[0])
-		assert.Equal(t, int16(2), result[1])
-		assert.Equal(t, int16(3), result[2])
-		assert.Equal(t, int16(4), result[3])
+		assert.Len(t, v, 0)
 	})
 
-	t.Run("Int32Ascending", func(t *testing.T) {
-		c := collection.NewFromSlice([]int32{3, 1, 4, 2})
-		result := c.OrderBy(func(x int32) any {
-			return x
-		}, true).ToSlice()
-
-		assert.Equal(t, 4, len(result))
-		assert.Equal(t, int32(1), result[0])
-		assert.Equal(t, int32(2), result[1])
-		assert.Equal(t, int32(3), result[2])
-		assert.Equal(t, int32(4), result[3])
+	t.Run("Break", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		for range *c.WhereNotFollowedBy(isContinuation) {
+			break
+		}
 	})
+}
 
-	t.Run("Int64Ascending", func(t *testing.T) {
-		c := collection.NewFromSlice([]int64{3, 1, 4, 2})
-		result := c.OrderBy(func(x int64) any {
-			return x
-		}, true).ToSlice()
+func TestFind(t *testing.T) {
+	c := collection.NewFromSlice([]string{"a", "b", "c"})
+	t.Run("Element", func(t *testing.T) {
+		v, ok := c.Find(func(x string) bool {
+			return x == "a"
+		})
 
-		assert.Equal(t, 4, len(result))
-		assert.Equal(t, int64(1), result[0])
-		assert.Equal(t, int64(2), result[1])
-		assert.Equal(t, int64(3), result[2])
-		assert.Equal(t, int64(4), result[3])
+		assert.True(t, ok)
+		assert.Equal(t, "a", v)
 	})
 
-	t.Run("UintAscending", func(t *testing.T) {
-		c := collection.NewFromSlice([]uint{3, 1, 4, 2})
-		result := c.OrderBy(func(x uint) any {
-			return x
-		}, true).ToSlice()
+	t.Run("NoElement", func(t *testing.T) {
+		v, ok := c.Find(func(x string) bool {
+			return x == "z"
+		})
 
-		assert.Equal(t, 4, len(result))
-		assert.Equal(t, uint(1), result[0])
-		assert.Equal(t, uint(2), result[1])
-		assert.Equal(t, uint(3), result[2])
-		assert.Equal(t, uint(4), result[3])
+		assert.False(t, ok)
+		assert.Equal(t, "", v)
 	})
+}
 
-	t.Run("Uint8Ascending", func(t *testing.T) {
-		c := collection.NewFromSlice([]uint8{3, 1, 4, 2})
-		result := c.OrderBy(func(x uint8) any {
-			return x
-		}, true).ToSlice()
+func TestFindOrError(t *testing.T) {
+	c := collection.NewFromSlice([]string{"a", "bb", "cc"})
+	t.Run("Element", func(t *testing.T) {
+		v, err := c.FindOrError(func(x string) bool {
+			return x == "a"
+		})
 
-		assert.Equal(t, 4, len(result))
-		assert.Equal(t, uint8(1), result[0])
-		assert.Equal(t, uint8(2), result[1])
-		assert.Equal(t, uint8(3), result[2])
-		assert.Equal(t, uint8(4), result[3])
+		assert.NoError(t, err)
+		assert.Equal(t, "a", v)
 	})
 
-	t.Run("Uint16Ascending", func(t *testing.T) {
-		c := collection.NewFromSlice([]uint16{3, 1, 4, 2})
-		result := c.OrderBy(func(x uint16) any {
-			return x
-		}, true).ToSlice()
+	t.Run("NoElement", func(t *testing.T) {
+		v, err := c.FindOrError(func(x string) bool {
+			return x == "z"
+		})
 
-		assert.Equal(t, 4, len(result))
-		assert.Equal(t, uint16(1), result[0])
-		assert.Equal(t, uint16(2), result[1])
-		assert.Equal(t, uint16(3), result[2])
-		assert.Equal(t, uint16(4), result[3])
+		assert.ErrorIs(t, err, collection.ErrNoElement)
+		assert.Equal(t, "", v)
 	})
 
-	t.Run("Uint32Ascending", func(t *testing.T) {
-		c := collection.NewFromSlice([]uint32{3, 1, 4, 2})
-		result := c.OrderBy(func(x uint32) any {
-			return x
-		}, true).ToSlice()
+	t.Run("MultipleMatches", func(t *testing.T) {
+		v, err := c.FindOrError(func(x string) bool {
+			return len(x) == 2
+		})
 
-		assert.Equal(t, 4, len(result))
-		assert.Equal(t, uint32(1), result[0])
-		assert.Equal(t, uint32(2), result[1])
-		assert.Equal(t, uint32(3), result[2])
-		assert.Equal(t, uint32(4), result[3])
+		assert.NoError(t, err)
+		assert.Equal(t, "bb", v)
 	})
+}
 
-	t.Run("Uint64Ascending", func(t *testing.T) {
-		c := collection.NewFromSlice([]uint64{3, 1, 4, 2})
-		result := c.OrderBy(func(x uint64) any {
-			return x
-		}, true).ToSlice()
-
-		assert.Equal(t, 4, len(result))
-		assert.Equal(t, uint64(1), result[0])
-		assert.Equal(t, uint64(2), result[1])
-		assert.Equal(t, uint64(3), result[2])
-		assert.Equal(t, uint64(4), result[3])
+func TestSelect(t *testing.T) {
+	type teststruct struct {
+		Property1 string
+		Property2 int
+	}
+	var teststructs []teststruct
+	teststructs = append(teststructs, teststruct{
+		Property1: "s1",
+		Property2: 1,
+	}, teststruct{
+		Property1: "s2",
+		Property2: 2,
 	})
+	c := collection.New[teststruct](teststructs)
 
-	t.Run("Float32Ascending", func(t *testing.T) {
-		c := collection.NewFromSlice([]float32{3, 1, 4, 2})
-		result := c.OrderBy(func(x float32) any {
-			return x
-		}, true).ToSlice()
+	t.Run("ReturnProperty", func(t *testing.T) {
+		var results []int
+		for _, val := range c.Select(func(x teststruct) any {
+			return x.Property2
+		}).ToSlice() {
+			results = append(results, val.(int))
+		}
 
-		assert.Equal(t, 4, len(result))
-		assert.Equal(t, float32(1), result[0])
-		assert.Equal(t, float32(2), result[1])
-		assert.Equal(t, float32(3), result[2])
-		assert.Equal(t, float32(4), result[3])
+		assert.Equal(t, 1, results[0])
+		assert.Equal(t, 2, results[1])
 	})
 
-	t.Run("Float64Ascending", func(t *testing.T) {
-		c := collection.NewFromSlice([]float64{3, 1, 4, 2})
-		result := c.OrderBy(func(x float64) any {
-			return x
-		}, true).ToSlice()
-
-		assert.Equal(t, 4, len(result))
-		assert.Equal(t, float64(1), result[0])
-		assert.Equal(t, float64(2), result[1])
-		assert.Equal(t, float64(3), result[2])
-		assert.Equal(t, float64(4), result[3])
+	t.Run("Break", func(t *testing.T) {
+		for range *c.Select(func(x teststruct) any {
+			return x.Property2
+		}) {
+			break
+		}
 	})
 
-	t.Run("StringsAscending", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"banana", "apple", "cherry"})
-		result := c.OrderBy(func(x string) any {
-			return x
-		}, true).ToSlice()
+	t.Run("ReceiverMutatedAfterBuild", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4})
+		doubled := collection.Select(c, func(x int) int { return x * 2 })
+		c.Pop()
 
-		assert.Equal(t, 3, len(result))
-		assert.Equal(t, "apple", result[0])
-		assert.Equal(t, "banana", result[1])
-		assert.Equal(t, "cherry", result[2])
+		assert.Equal(t, []int{2, 4, 6}, doubled.ToSlice())
 	})
+}
 
-	t.Run("StringsDescending", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"banana", "apple", "cherry"})
-		result := c.OrderBy(func(x string) any {
-			return x
-		}, false).ToSlice()
+func TestSelectIndexed(t *testing.T) {
+	t.Run("Logic", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		result := collection.SelectIndexed(c, func(i int, x string) string {
+			return fmt.Sprintf("%d: %s", i+1, x)
+		}).ToSlice()
 
-		assert.Equal(t, 3, len(result))
-		assert.Equal(t, "cherry", result[0])
-		assert.Equal(t, "banana", result[1])
-		assert.Equal(t, "apple", result[2])
+		assert.Equal(t, []string{"1: a", "2: b", "3: c"}, result)
 	})
 
-	t.Run("StructDefault", func(t *testing.T) {
-		type test struct{}
+	t.Run("Method", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b"})
+		result := c.SelectIndexed(func(i int, x string) any {
+			return i
+		}).ToSlice()
 
-		c := collection.NewFromSlice([]test{
-			{},
-			{},
-			{},
-		})
+		assert.Equal(t, []any{0, 1}, result)
+	})
 
-		result := c.OrderBy(func(x test) any {
-			return x
-		}, true).ToSlice()
+	t.Run("AfterFilter", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{5, 6, 7, 8}).Where(func(x int) bool {
+			return x%2 == 0
+		})
+		result := collection.SelectIndexed(c, func(i int, x int) int {
+			return i
+		}).ToSlice()
 
-		assert.Equal(t, 3, len(result))
+		assert.Equal(t, []int{0, 1}, result)
 	})
 
-	t.Run("StructField", func(t *testing.T) {
-		type person struct {
-			Name string
-			Age  int
+	t.Run("Break", func(t *testing.T) {
+		calls := 0
+		c := collection.NewFromSlice([]int{1, 2, 3})
+		for range *collection.SelectIndexed(c, func(i int, x int) int {
+			calls++
+			return x
+		}) {
+			break
 		}
 
-		c := collection.NewFromSlice([]person{
-			{Name: "Bob", Age: 30},
-			{Name: "Alice", Age: 25},
-			{Name: "Charlie", Age: 35},
-		})
+		assert.Equal(t, 1, calls)
+	})
+}
 
-		result := c.OrderBy(func(x person) any {
-			return x.Age
-		}, true).ToSlice()
+func TestSelectSafe(t *testing.T) {
+	type address struct {
+		City string
+	}
+	type profile struct {
+		Address *address
+	}
+	type user struct {
+		Name    string
+		Profile *profile
+	}
 
-		assert.Equal(t, 3, len(result))
-		assert.Equal(t, "Alice", result[0].Name)
-		assert.Equal(t, "Bob", result[1].Name)
-		assert.Equal(t, "Charlie", result[2].Name)
+	c := collection.NewFromSlice([]user{
+		{Name: "a", Profile: &profile{Address: &address{City: "London"}}},
+		{Name: "b"},
+		{Name: "c", Profile: &profile{}},
+		{Name: "d", Profile: &profile{Address: &address{City: "Leeds"}}},
 	})
-}
 
-func TestConcat(t *testing.T) {
-	t.Run("BothHaveElements", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]string{"a", "b"})
-		c2 := collection.NewFromSlice([]string{"c", "d"})
+	city := func(x user) (string, bool) {
+		if x.Profile == nil || x.Profile.Address == nil {
+			return "", false
+		}
+		return x.Profile.Address.City, true
+	}
 
-		result := c1.Concat(c2).ToSlice()
+	t.Run("Method", func(t *testing.T) {
+		result := c.SelectSafe(func(x user) (any, bool) { return city(x) }).ToSlice()
 
-		assert.Equal(t, 4, len(result))
-		assert.Equal(t, "a", result[0])
-		assert.Equal(t, "b", result[1])
-		assert.Equal(t, "c", result[2])
-		assert.Equal(t, "d", result[3])
+		assert.Equal(t, []any{"London", "Leeds"}, result)
 	})
 
-	t.Run("FirstEmpty", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]string{})
-		c2 := collection.NewFromSlice([]string{"c", "d"})
+	t.Run("Typed", func(t *testing.T) {
+		result := collection.SelectSafe(c, city).ToSlice()
 
-		result := c1.Concat(c2).ToSlice()
+		assert.Equal(t, []string{"London", "Leeds"}, result)
+	})
 
-		assert.Equal(t, 2, len(result))
-		assert.Equal(t, "c", result[0])
-		assert.Equal(t, "d", result[1])
+	t.Run("Break", func(t *testing.T) {
+		for range *collection.SelectSafe(c, city) {
+			break
+		}
 	})
+}
 
-	t.Run("SecondEmpty", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]string{"a", "b"})
-		c2 := collection.NewFromSlice([]string{})
+func TestSelectOrZero(t *testing.T) {
+	c := collection.NewFromSlice([]string{"1", "x", "3", "y"})
 
-		result := c1.Concat(c2).ToSlice()
+	t.Run("Method", func(t *testing.T) {
+		result := c.SelectOrZero(func(x string) (any, error) { return strconv.Atoi(x) })
 
-		assert.Equal(t, 2, len(result))
-		assert.Equal(t, "a", result[0])
-		assert.Equal(t, "b", result[1])
+		assert.Equal(t, []any{1, nil, 3, nil}, result.ToSlice())
+		assert.NotNil(t, result.Err())
 	})
 
-	t.Run("BothEmpty", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]string{})
-		c2 := collection.NewFromSlice([]string{})
+	t.Run("Summary", func(t *testing.T) {
+		result := collection.SelectOrZero(c, strconv.Atoi)
 
-		result := c1.Concat(c2).ToSlice()
+		assert.Equal(t, []int{1, 0, 3, 0}, result.ToSlice())
 
-		assert.Equal(t, 0, len(result))
+		var projectionErr *collection.ProjectionError
+		assert.ErrorAs(t, result.Err(), &projectionErr)
+		assert.Equal(t, 2, projectionErr.Failed)
+		assert.Equal(t, 4, projectionErr.Total)
+		assert.ErrorIs(t, result.Err(), strconv.ErrSyntax)
+		assert.ErrorContains(t, result.Err(), "2 of 4 elements failed to project")
+		assert.ErrorContains(t, result.Err(), "element 1")
+		assert.ErrorContains(t, result.Err(), "element 3")
 	})
 
-	t.Run("BreakFirst", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]string{"a", "b"})
-		c2 := collection.NewFromSlice([]string{})
+	t.Run("NoErrors", func(t *testing.T) {
+		result := collection.SelectOrZero(collection.NewFromSlice([]string{"1", "2"}), strconv.Atoi)
 
-		for range *c1.Concat(c2) {
-			break
-		}
+		assert.Equal(t, []int{1, 2}, result.ToSlice())
+		assert.Nil(t, result.Err())
 	})
 
-	t.Run("BreakSecond", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]string{})
-		c2 := collection.NewFromSlice([]string{"a", "b"})
+	t.Run("ResetsPerEnumeration", func(t *testing.T) {
+		result := collection.SelectOrZero(c, strconv.Atoi)
 
-		for range *c1.Concat(c2) {
-			break
-		}
+		result.ToSlice()
+		result.ToSlice()
+
+		var projectionErr *collection.ProjectionError
+		assert.ErrorAs(t, result.Err(), &projectionErr)
+		assert.Equal(t, 2, projectionErr.Failed)
 	})
 }
 
-func TestGroupBy(t *testing.T) {
-	t.Run("SimpleGrouping", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"apple", "banana", "cherry", "apricot", "blueberry"})
+func TestSelectOrError(t *testing.T) {
+	t.Run("AllSucceed", func(t *testing.T) {
+		result, err := collection.SelectOrError(collection.NewFromSlice([]string{"1", "2", "3"}), strconv.Atoi)
 
-		groups := c.GroupBy(func(x string) any {
-			return string(x[0]) // Group by first character
-		})
+		assert.Nil(t, err)
+		assert.Equal(t, []int{1, 2, 3}, result.ToSlice())
+	})
 
-		assert.Equal(t, 3, len(groups))
+	t.Run("Method", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"1", "2"})
+		result, err := c.SelectOrError(func(x string) (any, error) { return strconv.Atoi(x) })
 
-		aGroup := groups["a"].ToSlice()
-		assert.Equal(t, 2, len(aGroup))
-		assert.Contains(t, aGroup, "apple")
-		assert.Contains(t, aGroup, "apricot")
+		assert.Nil(t, err)
+		assert.Equal(t, []any{1, 2}, result.ToSlice())
+	})
 
-		bGroup := groups["b"].ToSlice()
-		assert.Equal(t, 2, len(bGroup))
-		assert.Contains(t, bGroup, "banana")
-		assert.Contains(t, bGroup, "blueberry")
+	t.Run("FailFast", func(t *testing.T) {
+		calls := 0
+		c := collection.NewFromSlice([]string{"1", "2", "x", "4", "y"})
+		result, err := collection.SelectOrError(c, func(x string) (int, error) {
+			calls++
+			return strconv.Atoi(x)
+		})
 
-		cGroup := groups["c"].ToSlice()
-		assert.Equal(t, 1, len(cGroup))
-		assert.Equal(t, "cherry", cGroup[0])
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, strconv.ErrSyntax)
+		assert.ErrorContains(t, err, "element 2")
+		assert.Equal(t, 3, calls)
 	})
 
 	t.Run("EmptyCollection", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{})
-
-		groups := c.GroupBy(func(x string) any {
-			return string(x[0])
-		})
+		result, err := collection.SelectOrError(collection.NewFromSlice([]string{}), strconv.Atoi)
 
-		assert.Equal(t, 0, len(groups))
+		assert.Nil(t, err)
+		assert.True(t, result.IsEmpty())
 	})
+}
 
-	t.Run("GroupByLength", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "bb", "ccc", "dd", "eee", "f"})
-
-		groups := c.GroupBy(func(x string) any {
-			return len(x)
-		})
+func TestSelectMany(t *testing.T) {
+	type teststruct struct {
+		Property1 string
+		Property2 int
+		Children  []string
+	}
 
-		assert.Equal(t, 3, len(groups))
+	var teststructs []teststruct
+	teststructs = append(teststructs, teststruct{
+		Property1: "s1",
+		Property2: 1,
+		Children:  []string{"child1", "child2"},
+	}, teststruct{
+		Property1: "s2",
+		Property2: 2,
+		Children:  []string{"child3", "child4", "child5"},
+	})
 
-		group1 := groups[1].ToSlice()
-		assert.Equal(t, 2, len(group1))
-		assert.Contains(t, group1, "a")
-		assert.Contains(t, group1, "f")
+	c := collection.New[teststruct](teststructs)
 
-		group2 := groups[2].ToSlice()
-		assert.Equal(t, 2, len(group2))
-		assert.Contains(t, group2, "bb")
-		assert.Contains(t, group2, "dd")
+	t.Run("FlattenChildren", func(t *testing.T) {
+		var results []string
+		for _, val := range c.SelectMany(func(x teststruct) *collection.Collection[any] {
+			childrenAny := make([]any, len(x.Children))
+			for i, child := range x.Children {
+				childrenAny[i] = child
+			}
+			return collection.New[any](childrenAny)
+		}).ToSlice() {
+			results = append(results, val.(string))
+		}
 
-		group3 := groups[3].ToSlice()
-		assert.Equal(t, 2, len(group3))
-		assert.Contains(t, group3, "ccc")
-		assert.Contains(t, group3, "eee")
+		assert.Equal(t, 5, len(results))
+		assert.Equal(t, "child1", results[0])
+		assert.Equal(t, "child2", results[1])
+		assert.Equal(t, "child3", results[2])
+		assert.Equal(t, "child4", results[3])
+		assert.Equal(t, "child5", results[4])
 	})
 
 	t.Run("Break", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"apple", "banana", "cherry", "apricot", "blueberry"})
-
-		groups := c.GroupBy(func(x string) any {
-			return string(x[0])
-		})
-
-		for _, group := range groups {
-			for range *group {
-				break
+		for range *c.SelectMany(func(x teststruct) *collection.Collection[any] {
+			childrenAny := make([]any, len(x.Children))
+			for i, child := range x.Children {
+				childrenAny[i] = child
 			}
+			return collection.New[any](childrenAny)
+		}) {
+			break
 		}
 	})
-}
 
-func TestUnion(t *testing.T) {
-	t.Run("WithDuplicates", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]int{1, 2, 3, 4})
-		c2 := collection.NewFromSlice([]int{3, 4, 5, 6})
+	t.Run("EmptyChildren", func(t *testing.T) {
+		var emptyTeststructs []teststruct
+		emptyTeststructs = append(emptyTeststructs, teststruct{
+			Property1: "s1",
+			Property2: 1,
+			Children:  []string{},
+		}, teststruct{
+			Property1: "s2",
+			Property2: 2,
+			Children:  []string{},
+		})
 
-		result := c1.Union(c2, func(a, b int) bool {
-			return a == b
-		}).ToSlice()
+		emptyC := collection.New[teststruct](emptyTeststructs)
 
-		assert.Equal(t, 6, len(result))
-		assert.Contains(t, result, 1)
-		assert.Contains(t, result, 2)
-		assert.Contains(t, result, 3)
-		assert.Contains(t, result, 4)
-		assert.Contains(t, result, 5)
-		assert.Contains(t, result, 6)
-	})
+		var results []string
+		for _, val := range emptyC.SelectMany(func(x teststruct) *collection.Collection[any] {
+			childrenAny := make([]any, len(x.Children))
+			for i, child := range x.Children {
+				childrenAny[i] = child
+			}
+			return collection.New[any](childrenAny)
+		}).ToSlice() {
+			results = append(results, val.(string))
+		}
 
-	t.Run("FirstEmpty", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]int{})
-		c2 := collection.NewFromSlice([]int{3, 4, 5})
+		assert.Equal(t, 0, len(results))
+	})
 
-		result := c1.Union(c2, func(a, b int) bool {
-			return a == b
-		}).ToSlice()
+	t.Run("MixedTypes", func(t *testing.T) {
+		var results []any
+		results = append(results, c.SelectMany(func(x teststruct) *collection.Collection[any] {
+			return collection.New[any]([]any{x.Property1, x.Property2})
+		}).ToSlice()...)
 
-		assert.Equal(t, 3, len(result))
-		assert.Contains(t, result, 3)
-		assert.Contains(t, result, 4)
-		assert.Contains(t, result, 5)
+		assert.Equal(t, 4, len(results))
+		assert.Equal(t, "s1", results[0])
+		assert.Equal(t, 1, results[1])
+		assert.Equal(t, "s2", results[2])
+		assert.Equal(t, 2, results[3])
 	})
+}
 
-	t.Run("SecondEmpty", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]int{1, 2, 3})
-		c2 := collection.NewFromSlice([]int{})
+func TestSelectManySlice(t *testing.T) {
+	type teststruct struct {
+		Property1 string
+		Children  []string
+	}
 
-		result := c1.Union(c2, func(a, b int) bool {
-			return a == b
+	c := collection.NewFromSlice([]teststruct{
+		{Property1: "s1", Children: []string{"child1", "child2"}},
+		{Property1: "s2", Children: []string{"child3", "child4", "child5"}},
+	})
+
+	t.Run("FlattenChildren", func(t *testing.T) {
+		results := collection.SelectManySlice(c, func(x teststruct) []string {
+			return x.Children
 		}).ToSlice()
 
-		assert.Equal(t, 3, len(result))
-		assert.Contains(t, result, 1)
-		assert.Contains(t, result, 2)
-		assert.Contains(t, result, 3)
+		assert.Equal(t, []string{"child1", "child2", "child3", "child4", "child5"}, results)
 	})
 
 	t.Run("Break", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]int{1, 2, 3, 4})
-		c2 := collection.NewFromSlice([]int{3, 4, 5, 6})
-
-		for range *c1.Union(c2, func(a, b int) bool {
-			return a == b
+		calls := 0
+		for range *collection.SelectManySlice(c, func(x teststruct) []string {
+			calls++
+			return x.Children
 		}) {
 			break
 		}
+
+		assert.Equal(t, 1, calls)
 	})
-}
 
-func TestIntersect(t *testing.T) {
-	t.Run("WithCommonElements", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]int{1, 2, 3, 4})
-		c2 := collection.NewFromSlice([]int{3, 4, 5, 6})
+	t.Run("EmptyChildren", func(t *testing.T) {
+		emptyC := collection.NewFromSlice([]teststruct{
+			{Property1: "s1", Children: []string{}},
+			{Property1: "s2", Children: nil},
+		})
 
-		result := c1.Intersect(c2, func(a, b int) bool {
-			return a == b
-		}).ToSlice()
+		results := collection.SelectManySlice(emptyC, func(x teststruct) []string {
+			return x.Children
+		})
 
-		assert.Equal(t, 2, len(result))
-		assert.Contains(t, result, 3)
-		assert.Contains(t, result, 4)
+		assert.True(t, results.IsEmpty())
 	})
+}
 
-	t.Run("NoCommonElements", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]int{1, 2})
-		c2 := collection.NewFromSlice([]int{3, 4})
-
-		result := c1.Intersect(c2, func(a, b int) bool {
-			return a == b
-		}).ToSlice()
+func TestToMap(t *testing.T) {
+	t.Run("Ints", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"1", "2", "3"})
+		v := collection.ToMap(c, func(x string) int {
+			i, _ := strconv.Atoi(x)
+			return i
+		})
 
-		assert.Equal(t, 0, len(result))
+		assert.Len(t, v, 3)
+		assert.Equal(t, "1", v[1])
+		assert.Equal(t, "2", v[2])
+		assert.Equal(t, "3", v[3])
 	})
 
-	t.Run("EmptyFirst", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]int{})
-		c2 := collection.NewFromSlice([]int{3, 4})
+	t.Run("Structs", func(t *testing.T) {
+		type teststruct struct {
+			Animal string
+			Name   string
+		}
 
-		result := c1.Intersect(c2, func(a, b int) bool {
-			return a == b
-		}).ToSlice()
+		c := collection.NewFromSlice([]teststruct{
+			{Animal: "Cow", Name: "Daisy"},
+			{Animal: "Chicken", Name: "Bert"},
+			{Animal: "Goose", Name: "Bruce"},
+		})
 
-		assert.Equal(t, 0, len(result))
-	})
+		v := collection.ToMap(c, func(x teststruct) string {
+			return x.Animal
+		})
 
-	t.Run("EmptySecond", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]int{1, 2})
-		c2 := collection.NewFromSlice([]int{})
+		assert.Len(t, v, 3)
+		assert.Equal(t, "Daisy", v["Cow"].Name)
+		assert.Equal(t, "Bert", v["Chicken"].Name)
+		assert.Equal(t, "Bruce", v["Goose"].Name)
+	})
+}
 
-		result := c1.Intersect(c2, func(a, b int) bool {
-			return a == b
-		}).ToSlice()
+func TestAll(t *testing.T) {
+	t.Run("True", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		v := c.All(func(x string) bool {
+			return x != ""
+		})
 
-		assert.Equal(t, 0, len(result))
+		assert.True(t, v)
 	})
 
-	t.Run("Break", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]int{1, 2, 3, 4})
-		c2 := collection.NewFromSlice([]int{3, 4, 5, 6})
+	t.Run("False", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		v := c.All(func(x string) bool {
+			return x == "a"
+		})
 
-		for range *c1.Intersect(c2, func(a, b int) bool {
-			return a == b
-		}) {
-			break
-		}
+		assert.False(t, v)
 	})
 }
 
-func TestExcept(t *testing.T) {
-	t.Run("WithCommonElements", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]int{1, 2, 3, 4})
-		c2 := collection.NewFromSlice([]int{3, 4, 5, 6})
+func TestFirst(t *testing.T) {
+	t.Run("NoElement_OkFalse", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{})
+		_, ok := c.First()
 
-		result := c1.Except(c2, func(a, b int) bool {
-			return a == b
-		}).ToSlice()
+		assert.False(t, ok)
+	})
+	t.Run("Element_OkTrue", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		v, ok := c.First()
 
-		assert.Equal(t, 2, len(result))
-		assert.Contains(t, result, 1)
-		assert.Contains(t, result, 2)
+		assert.True(t, ok)
+		assert.Equal(t, "a", v)
 	})
+}
 
-	t.Run("NoCommonElements", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]int{1, 2})
-		c2 := collection.NewFromSlice([]int{3, 4})
+func TestFirstOrError(t *testing.T) {
+	t.Run("Element", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		v, err := c.FirstOrError()
 
-		result := c1.Except(c2, func(a, b int) bool {
-			return a == b
-		}).ToSlice()
+		assert.Nil(t, err)
+		assert.Equal(t, "a", v)
+	})
 
-		assert.Equal(t, 2, len(result))
-		assert.Contains(t, result, 1)
-		assert.Contains(t, result, 2)
+	t.Run("NoElement_Error", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{})
+		_, err := c.FirstOrError()
+
+		assert.NotNil(t, err)
+		assert.Equal(t, collection.ErrNoElement, err)
 	})
+}
 
-	t.Run("AllElementsCommon", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]int{1, 2})
-		c2 := collection.NewFromSlice([]int{1, 2, 3})
+func TestFirstWhere(t *testing.T) {
+	t.Run("StopsAtMatch", func(t *testing.T) {
+		var seen []int
+		c := collection.NewFromSlice([]int{1, 2, 3, 4, 5}).Peek(func(v int) { seen = append(seen, v) })
 
-		result := c1.Except(c2, func(a, b int) bool {
-			return a == b
-		}).ToSlice()
+		v, ok := c.FirstWhere(func(x int) bool { return x > 2 })
 
-		assert.Equal(t, 0, len(result))
+		assert.True(t, ok)
+		assert.Equal(t, 3, v)
+		assert.Equal(t, []int{1, 2, 3}, seen)
 	})
 
-	t.Run("EmptyFirst", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]int{})
-		c2 := collection.NewFromSlice([]int{3, 4})
+	t.Run("NoMatch", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2})
 
-		result := c1.Except(c2, func(a, b int) bool {
-			return a == b
-		}).ToSlice()
+		v, ok := c.FirstWhere(func(x int) bool { return x > 2 })
 
-		assert.Equal(t, 0, len(result))
+		assert.False(t, ok)
+		assert.Equal(t, 0, v)
 	})
+}
 
-	t.Run("Break", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]int{1, 2, 3, 4})
-		c2 := collection.NewFromSlice([]int{3, 4, 5, 6})
+func TestFirstOr(t *testing.T) {
+	t.Run("Element", func(t *testing.T) {
+		var seen []int
+		c := collection.NewFromSlice([]int{7, 8, 9}).Peek(func(v int) { seen = append(seen, v) })
 
-		for range *c1.Except(c2, func(a, b int) bool {
-			return a == b
-		}) {
-			break
-		}
+		assert.Equal(t, 7, c.FirstOr(-1))
+		assert.Equal(t, []int{7}, seen)
 	})
-}
 
-func TestEquals(t *testing.T) {
-	t.Run("Equal", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]int{1, 2, 3})
-		c2 := collection.NewFromSlice([]int{1, 2, 3})
+	t.Run("EmptyCollection", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{})
 
-		assert.True(t, c1.Equals(c2, func(a, b int) bool {
-			return a == b
-		}))
+		assert.Equal(t, -1, c.FirstOr(-1))
 	})
+}
 
-	t.Run("NotEqual", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]int{1, 2, 3})
-		c2 := collection.NewFromSlice([]int{1, 2, 4})
+func TestLast(t *testing.T) {
+	t.Run("NoElement_OkFalse", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{})
+		_, ok := c.Last()
 
-		assert.False(t, c1.Equals(c2, func(a, b int) bool {
-			return a == b
-		}))
+		assert.False(t, ok)
 	})
+	t.Run("Element_OkTrue", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		v, ok := c.Last()
 
-	t.Run("DifferentLengths", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]int{1, 2, 3})
-		c2 := collection.NewFromSlice([]int{1, 2, 3, 4})
+		assert.True(t, ok)
+		assert.Equal(t, "c", v)
+	})
+}
 
-		assert.False(t, c1.Equals(c2, func(a, b int) bool {
-			return a == b
-		}))
+func TestLastOrError(t *testing.T) {
+	t.Run("Element", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		v, err := c.LastOrError()
+
+		assert.Nil(t, err)
+		assert.Equal(t, "c", v)
 	})
 
-	t.Run("EmptyCollections", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]int{})
-		c2 := collection.NewFromSlice([]int{})
+	t.Run("NoElement_Error", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{})
+		_, err := c.LastOrError()
 
-		assert.True(t, c1.Equals(c2, func(a, b int) bool {
-			return a == b
-		}))
+		assert.NotNil(t, err)
+		assert.Equal(t, collection.ErrNoElement, err)
 	})
 }
 
-func TestReverse(t *testing.T) {
-	t.Run("Reversed", func(t *testing.T) {
+func TestLen(t *testing.T) {
+	t.Run("NonEmptyCollection", func(t *testing.T) {
 		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		result := c.Reverse().ToSlice()
+		v := c.Len()
 
-		assert.Equal(t, 3, len(result))
-		assert.Equal(t, "c", result[0])
-		assert.Equal(t, "b", result[1])
-		assert.Equal(t, "a", result[2])
+		assert.Equal(t, 3, v)
 	})
 
-	t.Run("Break", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		for range *c.Reverse() {
-			break
-		}
+	t.Run("EmptyCollection", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{})
+		v := c.Len()
+
+		assert.Equal(t, 0, v)
 	})
 }
 
-func TestAppend(t *testing.T) {
-	t.Run("Appends", func(t *testing.T) {
+func TestCount(t *testing.T) {
+	t.Run("NonEmptyCollection", func(t *testing.T) {
 		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		result := c.Append("d").ToSlice()
+		v := c.Count()
 
-		assert.Equal(t, 4, len(result))
-		assert.Equal(t, "a", result[0])
-		assert.Equal(t, "b", result[1])
-		assert.Equal(t, "c", result[2])
-		assert.Equal(t, "d", result[3])
+		assert.Equal(t, 3, v)
 	})
 
-	t.Run("Break", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		for range *c.Append("d") {
-			break
-		}
+	t.Run("EmptyCollection", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{})
+		v := c.Count()
+
+		assert.Equal(t, 0, v)
 	})
 }
 
-func TestPrepend(t *testing.T) {
-	t.Run("Prepends", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		result := c.Prepend("d").ToSlice()
+func TestContains(t *testing.T) {
+	c := collection.NewFromSlice([]string{"a", "b", "c"})
+	t.Run("True", func(t *testing.T) {
+		v := c.Contains(func(x string) bool {
+			return x == "a"
+		})
 
-		assert.Equal(t, 4, len(result))
-		assert.Equal(t, "d", result[0])
-		assert.Equal(t, "a", result[1])
-		assert.Equal(t, "b", result[2])
-		assert.Equal(t, "c", result[3])
+		assert.True(t, v)
 	})
 
-	t.Run("Break", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		for range *c.Prepend("d") {
-			break
-		}
+	t.Run("False", func(t *testing.T) {
+		v := c.Contains(func(x string) bool {
+			return x == "z"
+		})
+
+		assert.False(t, v)
 	})
 }
 
-func TestChunk(t *testing.T) {
-	t.Run("Chunked", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e", "f", "g", "h"})
-		result := c.Chunk(3)
+func TestIsEmpty(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{})
+		v := c.IsEmpty()
 
-		assert.Equal(t, 3, len(result))
+		assert.True(t, v)
+	})
 
-		assert.Equal(t, 3, len(result[0].ToSlice()))
-		assert.Equal(t, "a", result[0].ToSlice()[0])
-		assert.Equal(t, "b", result[0].ToSlice()[1])
-		assert.Equal(t, "c", result[0].ToSlice()[2])
+	t.Run("NotEmpty", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a"})
+		v := c.IsEmpty()
 
-		assert.Equal(t, 3, len(result[1].ToSlice()))
-		assert.Equal(t, "d", result[1].ToSlice()[0])
-		assert.Equal(t, "e", result[1].ToSlice()[1])
-		assert.Equal(t, "f", result[1].ToSlice()[2])
+		assert.False(t, v)
+	})
+}
 
-		assert.Equal(t, 2, len(result[2].ToSlice()))
-		assert.Equal(t, "g", result[2].ToSlice()[0])
-		assert.Equal(t, "h", result[2].ToSlice()[1])
+func TestSingle(t *testing.T) {
+	t.Run("OneElement", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a"})
+		v, ok := c.Single()
+
+		assert.True(t, ok)
+		assert.Equal(t, "a", v)
 	})
 
-	t.Run("Break", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e", "f", "g", "h"})
-		for _, chunk := range c.Chunk(3) {
-			for range *chunk {
-				break
-			}
-		}
+	t.Run("MultipleElements", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b"})
+		_, ok := c.Single()
+
+		assert.False(t, ok)
+	})
+
+	t.Run("NoElements", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{})
+		_, ok := c.Single()
+
+		assert.False(t, ok)
 	})
 }
 
-func TestAggregate(t *testing.T) {
-	t.Run("Logic", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"Amsterdam", "Berlin", "New York", "San Francisco"})
+func TestSingleOrError(t *testing.T) {
+	t.Run("OneElement", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a"})
+		v, err := c.SingleOrError()
 
-		result := c.Aggregate("Paris", func(accumulator any, item string) any {
-			if len(accumulator.(string)) < len(item) {
-				return item
-			}
+		assert.Nil(t, err)
+		assert.Equal(t, "a", v)
+	})
 
-			return accumulator
-		})
+	t.Run("MultipleElements", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b"})
+		_, err := c.SingleOrError()
 
-		assert.Equal(t, "San Francisco", result)
+		assert.NotNil(t, err)
+		assert.Equal(t, collection.ErrNotExactlyOneElement, err)
+	})
+
+	t.Run("NoElements", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{})
+		_, err := c.SingleOrError()
+
+		assert.NotNil(t, err)
+		assert.Equal(t, collection.ErrNotExactlyOneElement, err)
+	})
+}
+
+func TestSingleWhere(t *testing.T) {
+	isEven := func(x int) bool { return x%2 == 0 }
+
+	t.Run("OneMatch", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3})
+		v, ok := c.SingleWhere(isEven)
+
+		assert.True(t, ok)
+		assert.Equal(t, 2, v)
+	})
+
+	t.Run("MultipleMatches", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 4})
+		v, ok := c.SingleWhere(isEven)
+
+		assert.False(t, ok)
+		assert.Equal(t, 0, v)
+	})
+
+	t.Run("NoMatch", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 3})
+		_, ok := c.SingleWhere(isEven)
+
+		assert.False(t, ok)
+	})
+}
+
+func TestSingleWhereOrError(t *testing.T) {
+	isEven := func(x int) bool { return x%2 == 0 }
+
+	t.Run("OneMatch", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3})
+		v, err := c.SingleWhereOrError(isEven)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2, v)
+	})
+
+	t.Run("MultipleMatches", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 4})
+		_, err := c.SingleWhereOrError(isEven)
+
+		assert.Equal(t, collection.ErrNotExactlyOneElement, err)
+	})
+
+	t.Run("NoMatch", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 3})
+		_, err := c.SingleWhereOrError(isEven)
+
+		assert.Equal(t, collection.ErrNoElement, err)
+	})
+
+	t.Run("StopsAfterSecondMatch", func(t *testing.T) {
+		var seen []int
+		c := collection.NewFromSlice([]int{1, 2, 3, 4, 5, 6}).Peek(func(v int) { seen = append(seen, v) })
+		_, err := c.SingleWhereOrError(isEven)
+
+		assert.Equal(t, collection.ErrNotExactlyOneElement, err)
+		assert.Equal(t, []int{1, 2, 3, 4}, seen)
+	})
+}
+
+func TestShuffle(t *testing.T) {
+	t.Run("ShuffleElements", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e"})
+		shuffled := c.Shuffle().ToSlice()
+
+		assert.Equal(t, 5, len(shuffled))
+		assert.Contains(t, shuffled, "a")
+		assert.Contains(t, shuffled, "b")
+		assert.Contains(t, shuffled, "c")
+		assert.Contains(t, shuffled, "d")
+		assert.Contains(t, shuffled, "e")
+
+		// Check that the order is different
+		if slices.Equal(shuffled, []string{"a", "b", "c", "d", "e"}) {
+			t.Error("Expected the order to be different after shuffle")
+		}
+	})
+
+	t.Run("ShuffleEmptyCollection", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{})
+		shuffled := c.Shuffle().ToSlice()
+
+		assert.Equal(t, 0, len(shuffled))
+	})
+
+	t.Run("ShuffleSingleElement", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a"})
+		shuffled := c.Shuffle().ToSlice()
+
+		assert.Equal(t, 1, len(shuffled))
+		assert.Equal(t, "a", shuffled[0])
+	})
+}
+
+func TestShuffleWithSource(t *testing.T) {
+	t.Run("SameSeedSamePermutation", func(t *testing.T) {
+		c := collection.NewFromRange(0, 20)
+		first := c.ShuffleWithSource(rand.New(rand.NewSource(1))).ToSlice()
+		second := c.ShuffleWithSource(rand.New(rand.NewSource(1))).ToSlice()
+
+		assert.Equal(t, first, second)
+		assert.ElementsMatch(t, c.ToSlice(), first)
+	})
+
+	t.Run("DifferentSeedsDiffer", func(t *testing.T) {
+		c := collection.NewFromRange(0, 20)
+		first := c.ShuffleWithSource(rand.New(rand.NewSource(1))).ToSlice()
+		second := c.ShuffleWithSource(rand.New(rand.NewSource(2))).ToSlice()
+
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("NilSource", func(t *testing.T) {
+		result := collection.NewFromSlice([]int{1, 2, 3}).ShuffleWithSource(nil).ToSlice()
+
+		assert.ElementsMatch(t, []int{1, 2, 3}, result)
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		result := collection.NewFromSlice([]int{}).ShuffleWithSource(rand.New(rand.NewSource(1))).ToSlice()
+
+		assert.Empty(t, result)
+	})
+
+	t.Run("SingleElement", func(t *testing.T) {
+		result := collection.NewFromSlice([]int{1}).ShuffleWithSource(rand.New(rand.NewSource(1))).ToSlice()
+
+		assert.Equal(t, []int{1}, result)
+	})
+}
+
+func TestShuffleCrypto(t *testing.T) {
+	t.Run("PreservesElements", func(t *testing.T) {
+		c := collection.NewFromRange(0, 20)
+		result, err := c.ShuffleCrypto()
+
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, c.ToSlice(), result.ToSlice())
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		result, err := collection.NewFromSlice([]int{}).ShuffleCrypto()
+
+		assert.NoError(t, err)
+		assert.True(t, result.IsEmpty())
+	})
+
+	t.Run("ReaderError", func(t *testing.T) {
+		readErr := errors.New("entropy unavailable")
+		r := iotest.ErrReader(readErr)
+		result, err := collection.NewFromSlice([]int{1, 2, 3}).ShuffleCryptoWithReader(r)
+
+		assert.ErrorIs(t, err, readErr)
+		assert.Nil(t, result)
+	})
+
+	t.Run("SingleElementReadsNothing", func(t *testing.T) {
+		r := iotest.ErrReader(errors.New("unexpected read"))
+		result, err := collection.NewFromSlice([]int{1}).ShuffleCryptoWithReader(r)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1}, result.ToSlice())
+	})
+}
+
+func TestDistinct(t *testing.T) {
+	t.Run("StringsWithDuplicates", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "a", "c", "b"})
+		result := c.Distinct(func(a, b string) bool {
+			return a == b
+		}).ToSlice()
+
+		assert.Equal(t, 3, len(result))
+		assert.Contains(t, result, "a")
+		assert.Contains(t, result, "b")
+		assert.Contains(t, result, "c")
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{})
+		result := c.Distinct(func(a, b string) bool {
+			return a == b
+		}).ToSlice()
+
+		assert.Equal(t, 0, len(result))
+	})
+
+	t.Run("CustomEquality", func(t *testing.T) {
+		type person struct {
+			ID   int
+			Name string
+		}
+
+		c := collection.NewFromSlice([]person{
+			{ID: 1, Name: "Alice"},
+			{ID: 2, Name: "Bob"},
+			{ID: 3, Name: "alice"}, // Different case, same name
+		})
+
+		result := c.Distinct(func(a, b person) bool {
+			return strings.EqualFold(a.Name, b.Name)
+		}).ToSlice()
+
+		assert.Equal(t, 2, len(result))
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "a", "c", "b"})
+		for range *c.Distinct(func(a, b string) bool {
+			return a == b
+		}) {
+			break
+		}
+	})
+}
+
+func TestDedupConsecutive(t *testing.T) {
+	equals := func(a, b int) bool { return a == b }
+
+	t.Run("RunsCollapsed", func(t *testing.T) {
+		result := collection.NewFromSlice([]int{1, 1, 2, 2, 2, 3, 1, 1}).DedupConsecutive(equals).ToSlice()
+
+		assert.Equal(t, []int{1, 2, 3, 1}, result)
+	})
+
+	t.Run("AlternatingUntouched", func(t *testing.T) {
+		result := collection.NewFromSlice([]int{1, 2, 1, 2}).DedupConsecutive(equals).ToSlice()
+
+		assert.Equal(t, []int{1, 2, 1, 2}, result)
+	})
+
+	t.Run("SingleElement", func(t *testing.T) {
+		result := collection.NewFromSlice([]int{7}).DedupConsecutive(equals).ToSlice()
+
+		assert.Equal(t, []int{7}, result)
+	})
+
+	t.Run("ZeroValueFirst", func(t *testing.T) {
+		result := collection.NewFromSlice([]int{0, 0, 1}).DedupConsecutive(equals).ToSlice()
+
+		assert.Equal(t, []int{0, 1}, result)
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		result := collection.NewFromSlice([]int{}).DedupConsecutive(equals)
+
+		assert.True(t, result.IsEmpty())
+	})
+
+	t.Run("EarlyBreak", func(t *testing.T) {
+		c := collection.Iterate(0, func(x int) int { return x + 1 })
+		result := c.DedupConsecutive(func(a, b int) bool { return a/2 == b/2 }).Take(3).ToSlice()
+
+		assert.Equal(t, []int{0, 2, 4}, result)
+	})
+}
+
+func TestSkip(t *testing.T) {
+	t.Run("SkipSome", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e"})
+		result := c.Skip(2).ToSlice()
+
+		assert.Equal(t, 3, len(result))
+		assert.Equal(t, "c", result[0])
+		assert.Equal(t, "d", result[1])
+		assert.Equal(t, "e", result[2])
+	})
+
+	t.Run("SkipAll", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		result := c.Skip(3).ToSlice()
+
+		assert.Equal(t, 0, len(result))
+	})
+
+	t.Run("SkipMore", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		result := c.Skip(5).ToSlice()
+
+		assert.Equal(t, 0, len(result))
+	})
+
+	t.Run("SkipZero", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		result := c.Skip(0).ToSlice()
+
+		assert.Equal(t, 3, len(result))
+		assert.Equal(t, "a", result[0])
+		assert.Equal(t, "b", result[1])
+		assert.Equal(t, "c", result[2])
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e"})
+		for range *c.Skip(2) {
+			break
+		}
+	})
+}
+
+func TestSkipUntil(t *testing.T) {
+	t.Run("SkipUntilSome", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e"})
+		result := c.SkipUntil(func(x string) bool {
+			return x == "d"
+		}).ToSlice()
+
+		assert.Equal(t, 2, len(result))
+		assert.Equal(t, "d", result[0])
+		assert.Equal(t, "e", result[1])
+	})
+
+	t.Run("SkipUntilAll", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		result := c.SkipUntil(func(x string) bool {
+			return x == "z"
+		}).ToSlice()
+
+		assert.Equal(t, 0, len(result))
+	})
+
+	t.Run("SkipUntilNone", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		result := c.SkipUntil(func(x string) bool {
+			return x == "a"
+		}).ToSlice()
+
+		assert.Equal(t, 3, len(result))
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e"})
+		for range *c.SkipUntil(func(x string) bool {
+			return x == "d"
+		}) {
+			break
+		}
+	})
+}
+
+func TestSkipLast(t *testing.T) {
+	t.Run("SkipSome", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e"})
+		result := c.SkipLast(2).ToSlice()
+
+		assert.Equal(t, 3, len(result))
+		assert.Equal(t, "a", result[0])
+		assert.Equal(t, "b", result[1])
+		assert.Equal(t, "c", result[2])
+	})
+
+	t.Run("SkipAll", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		result := c.SkipLast(3).ToSlice()
+
+		assert.Equal(t, 0, len(result))
+	})
+
+	t.Run("SkipMore", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		result := c.SkipLast(5).ToSlice()
+
+		assert.Equal(t, 0, len(result))
+	})
+
+	t.Run("SkipZero", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		result := c.SkipLast(0).ToSlice()
+
+		assert.Equal(t, 3, len(result))
+		assert.Equal(t, "a", result[0])
+		assert.Equal(t, "b", result[1])
+		assert.Equal(t, "c", result[2])
+	})
+
+	t.Run("SkipNegative", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		result := c.SkipLast(-1).ToSlice()
+
+		assert.Equal(t, []string{"a", "b", "c"}, result)
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e"})
+		for range *c.SkipLast(2) {
+			break
+		}
+	})
+}
+
+// newReleaseTrackingSource returns an unbounded collection and a function reporting whether an enumeration of it has
+// stopped
+func newReleaseTrackingSource() (*collection.Collection[int], func() bool) {
+	var released atomic.Bool
+	return collection.NewFromIterator(iter.Seq[int](func(yield func(int) bool) {
+		defer released.Store(true)
+		for i := 0; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	})), released.Load
+}
+
+func TestSplitAt(t *testing.T) {
+	t.Run("TailBreakReleasesSource", func(t *testing.T) {
+		c, released := newReleaseTrackingSource()
+		head, tail := c.SplitAt(2)
+
+		assert.Equal(t, []int{0, 1}, head.ToSlice())
+		assert.False(t, released())
+
+		for range *tail {
+			break
+		}
+		assert.True(t, released())
+	})
+
+	t.Run("Split", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4, 5})
+		head, tail := c.SplitAt(2)
+
+		assert.Equal(t, []int{1, 2}, head.ToSlice())
+		assert.Equal(t, []int{3, 4, 5}, tail.ToSlice())
+	})
+
+	t.Run("ZeroOrNegative", func(t *testing.T) {
+		for _, n := range []int{0, -1} {
+			c := collection.NewFromSlice([]int{1, 2, 3})
+			head, tail := c.SplitAt(n)
+
+			assert.True(t, head.IsEmpty())
+			assert.Equal(t, []int{1, 2, 3}, tail.ToSlice())
+		}
+	})
+
+	t.Run("BeyondLength", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3})
+		head, tail := c.SplitAt(3)
+
+		assert.Equal(t, []int{1, 2, 3}, head.ToSlice())
+		assert.True(t, tail.IsEmpty())
+
+		head, tail = c.SplitAt(10)
+
+		assert.Equal(t, []int{1, 2, 3}, head.ToSlice())
+		assert.True(t, tail.IsEmpty())
+	})
+
+	t.Run("ChannelTailFirst", func(t *testing.T) {
+		ch := make(chan int, 5)
+		for i := range 5 {
+			ch <- i
+		}
+		close(ch)
+
+		head, tail := collection.NewFromChannel(ch).SplitAt(2)
+
+		assert.Equal(t, []int{2, 3, 4}, tail.ToSlice())
+		assert.Equal(t, []int{0, 1}, head.ToSlice())
+		assert.Equal(t, []int{0, 1}, head.ToSlice())
+	})
+
+	t.Run("SourceEnumeratedOnce", func(t *testing.T) {
+		pulled := 0
+		c := collection.NewFromSlice([]int{1, 2, 3, 4}).Peek(func(int) { pulled++ })
+		head, tail := c.SplitAt(2)
+
+		head.ToSlice()
+		tail.ToSlice()
+		head.ToSlice()
+
+		assert.Equal(t, 4, pulled)
+	})
+
+	t.Run("TailBreak", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4})
+		_, tail := c.SplitAt(1)
+
+		for range *tail {
+			break
+		}
+
+		assert.Equal(t, []int{2, 3, 4}, tail.ToSlice())
+	})
+
+	t.Run("StopReleasesAbandonedTail", func(t *testing.T) {
+		c, released := newReleaseTrackingSource()
+		head, tail, stop := c.SplitAtStop(2)
+
+		assert.Equal(t, []int{0, 1}, head.ToSlice())
+		assert.False(t, released())
+
+		stop()
+		assert.True(t, released())
+		assert.Equal(t, []int{0, 1}, head.ToSlice())
+		assert.True(t, tail.IsEmpty())
+	})
+
+	t.Run("StopBeforeEnumeration", func(t *testing.T) {
+		pulled := 0
+		head, tail, stop := collection.NewFromSlice([]int{1, 2, 3}).Peek(func(int) { pulled++ }).SplitAtStop(1)
+
+		stop()
+
+		assert.True(t, head.IsEmpty())
+		assert.True(t, tail.IsEmpty())
+		assert.Zero(t, pulled)
+	})
+
+	t.Run("TailEnumeratedTwice", func(t *testing.T) {
+		_, tail := collection.NewFromSlice([]int{1, 2, 3, 4}).SplitAt(2)
+
+		assert.Equal(t, 2, tail.Count())
+		assert.Equal(t, []int{3, 4}, tail.ToSlice())
+		assert.Equal(t, []int{3, 4}, tail.ToSlice())
+	})
+}
+
+func TestSpan(t *testing.T) {
+	lessThanThree := func(v int) bool { return v < 3 }
+
+	t.Run("PrefixFirst", func(t *testing.T) {
+		prefix, rest := collection.NewFromIterator(slices.Values([]int{1, 2, 3, 1, 4})).Span(lessThanThree)
+
+		assert.Equal(t, []int{1, 2}, prefix.ToSlice())
+		assert.Equal(t, []int{3, 1, 4}, rest.ToSlice())
+	})
+
+	t.Run("RemainderFirst", func(t *testing.T) {
+		ch := make(chan int, 5)
+		for _, v := range []int{1, 2, 3, 1, 4} {
+			ch <- v
+		}
+		close(ch)
+
+		prefix, rest := collection.NewFromChannel(ch).Span(lessThanThree)
+
+		assert.Equal(t, []int{3, 1, 4}, rest.ToSlice())
+		assert.Equal(t, []int{1, 2}, prefix.ToSlice())
+	})
+
+	t.Run("PredicateAppliedOnce", func(t *testing.T) {
+		calls := 0
+		prefix, rest := collection.NewFromSlice([]int{1, 2, 3, 4}).Span(func(v int) bool {
+			calls++
+			return v < 3
+		})
+
+		prefix.ToSlice()
+		rest.ToSlice()
+		prefix.ToSlice()
+
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("RemainderEnumeratedTwice", func(t *testing.T) {
+		calls := 0
+		prefix, rest := collection.NewFromSlice([]int{1, 2, 3, 1, 4}).Span(func(v int) bool {
+			calls++
+			return v < 3
+		})
+
+		assert.Equal(t, []int{3, 1, 4}, rest.ToSlice())
+		assert.Equal(t, []int{3, 1, 4}, rest.ToSlice())
+		assert.Equal(t, []int{1, 2}, prefix.ToSlice())
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("StopReleasesAbandonedRemainder", func(t *testing.T) {
+		c, released := newReleaseTrackingSource()
+		prefix, rest, stop := c.SpanStop(lessThanThree)
+
+		assert.Equal(t, []int{0, 1, 2}, prefix.ToSlice())
+		assert.False(t, released())
+
+		stop()
+		assert.True(t, released())
+		assert.True(t, rest.IsEmpty())
+	})
+
+	t.Run("AllMatch", func(t *testing.T) {
+		prefix, rest := collection.NewFromSlice([]int{1, 2}).Span(lessThanThree)
+
+		assert.Equal(t, []int{1, 2}, prefix.ToSlice())
+		assert.True(t, rest.IsEmpty())
+	})
+
+	t.Run("NoneMatch", func(t *testing.T) {
+		prefix, rest := collection.NewFromSlice([]int{5, 1}).Span(lessThanThree)
+
+		assert.True(t, prefix.IsEmpty())
+		assert.Equal(t, []int{5, 1}, rest.ToSlice())
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		prefix, rest := collection.NewFromSlice([]int{}).Span(lessThanThree)
+
+		assert.True(t, prefix.IsEmpty())
+		assert.True(t, rest.IsEmpty())
+	})
+}
+
+func TestTake(t *testing.T) {
+	t.Run("TakeSome", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e"})
+		result := c.Take(3).ToSlice()
+
+		assert.Equal(t, 3, len(result))
+		assert.Equal(t, "a", result[0])
+		assert.Equal(t, "b", result[1])
+		assert.Equal(t, "c", result[2])
+	})
+
+	t.Run("TakeAll", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		result := c.Take(3).ToSlice()
+
+		assert.Equal(t, 3, len(result))
+	})
+
+	t.Run("TakeMore", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		result := c.Take(5).ToSlice()
+
+		assert.Equal(t, 3, len(result))
+	})
+
+	t.Run("TakeZero", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		result := c.Take(0).ToSlice()
+
+		assert.Equal(t, 0, len(result))
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e"})
+		for range *c.Take(3) {
+			break
+		}
+	})
+
+	t.Run("ReceiverMutatedAfterBuild", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4})
+		taken := c.Take(4)
+		c.Pop()
+
+		assert.Equal(t, []int{1, 2, 3}, taken.ToSlice())
+	})
+}
+
+func TestTakeWithOverflow(t *testing.T) {
+	t.Run("Overflow", func(t *testing.T) {
+		c := collection.NewFromRange(0, 1342)
+		taken, overflow := c.TakeWithOverflow(20)
+
+		assert.Equal(t, 20, taken.Len())
+		assert.Equal(t, 1322, overflow())
+	})
+
+	t.Run("ZeroOverflow", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3})
+		taken, overflow := c.TakeWithOverflow(5)
+
+		assert.Equal(t, []int{1, 2, 3}, taken.ToSlice())
+		assert.Equal(t, 0, overflow())
+	})
+
+	t.Run("NotEnumerated", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4})
+		_, overflow := c.TakeWithOverflow(1)
+
+		assert.Equal(t, 3, overflow())
+	})
+
+	t.Run("ChannelSource", func(t *testing.T) {
+		ch := make(chan int, 10)
+		for i := range 10 {
+			ch <- i
+		}
+		close(ch)
+
+		taken, overflow := collection.NewFromChannel(ch).TakeWithOverflow(4)
+
+		assert.Equal(t, []int{0, 1, 2, 3}, taken.ToSlice())
+		assert.Equal(t, 6, overflow())
+	})
+
+	t.Run("ChannelSourceNotEnumerated", func(t *testing.T) {
+		ch := make(chan int, 10)
+		for i := range 10 {
+			ch <- i
+		}
+		close(ch)
+
+		taken, overflow := collection.NewFromChannel(ch).TakeWithOverflow(4)
+
+		// Counting before enumeration consumes the channel
+		assert.Equal(t, 6, overflow())
+		assert.True(t, taken.IsEmpty())
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3})
+		taken, overflow := c.TakeWithOverflow(2)
+
+		for range *taken {
+			break
+		}
+
+		assert.Equal(t, 1, overflow())
+	})
+
+	t.Run("ConcurrentOverflow", func(t *testing.T) {
+		c := collection.NewFromRange(0, 1000)
+		taken, overflow := c.TakeWithOverflow(10)
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				assert.Equal(t, 990, overflow())
+			}
+		}()
+		for range 100 {
+			assert.Equal(t, 10, taken.Len())
+		}
+		wg.Wait()
+
+		assert.Equal(t, 990, overflow())
+	})
+
+	t.Run("ResetPerEnumeration", func(t *testing.T) {
+		items := []int{1, 2, 3, 4, 5}
+		c := collection.New[int](iter.Seq[int](func(yield func(int) bool) {
+			for _, v := range items {
+				if !yield(v) {
+					return
+				}
+			}
+		}))
+		taken, overflow := c.TakeWithOverflow(2)
+
+		assert.Equal(t, []int{1, 2}, taken.ToSlice())
+		assert.Equal(t, 3, overflow())
+
+		items = append(items, 6, 7)
+		for range *taken {
+			break
+		}
+		assert.Equal(t, 5, overflow())
+
+		items = items[:3]
+		assert.Equal(t, []int{1, 2}, taken.ToSlice())
+		assert.Equal(t, 1, overflow())
+	})
+
+	t.Run("ChannelSourceBreak", func(t *testing.T) {
+		ch := make(chan int, 10)
+		for i := range 10 {
+			ch <- i
+		}
+		close(ch)
+
+		taken, overflow := collection.NewFromChannel(ch).TakeWithOverflow(4)
+		for range *taken {
+			break
+		}
+
+		// The recount resumes the channel after the one element received, so it undercounts
+		assert.Equal(t, 5, overflow())
+	})
+}
+
+func TestTakeUntilInclusive(t *testing.T) {
+	t.Run("IncludesMatch", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "END", "c"})
+		result := c.TakeUntilInclusive(func(x string) bool {
+			return x == "END"
+		}).ToSlice()
+
+		assert.Equal(t, []string{"a", "b", "END"}, result)
+	})
+
+	t.Run("FirstElement", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"END", "a"})
+		result := c.TakeUntilInclusive(func(x string) bool {
+			return x == "END"
+		}).ToSlice()
+
+		assert.Equal(t, []string{"END"}, result)
+	})
+
+	t.Run("NoMatch", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		result := c.TakeUntilInclusive(func(x string) bool {
+			return x == "END"
+		}).ToSlice()
+
+		assert.Equal(t, []string{"a", "b", "c"}, result)
+	})
+
+	t.Run("StopsPullingAfterMatch", func(t *testing.T) {
+		ch := make(chan string, 4)
+		for _, v := range []string{"a", "END", "b", "c"} {
+			ch <- v
+		}
+		close(ch)
+
+		result := collection.NewFromChannel(ch).TakeUntilInclusive(func(x string) bool {
+			return x == "END"
+		}).ToSlice()
+
+		assert.Equal(t, []string{"a", "END"}, result)
+		assert.Equal(t, 2, len(ch))
+	})
+
+	t.Run("BreakBeforeMatch", func(t *testing.T) {
+		calls := 0
+		c := collection.NewFromSlice([]string{"a", "b", "END"})
+		for range *c.TakeUntilInclusive(func(x string) bool {
+			calls++
+			return x == "END"
+		}) {
+			break
+		}
+
+		assert.Equal(t, 0, calls)
+	})
+}
+
+func TestStride(t *testing.T) {
+	t.Run("Logic", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4, 5})
+
+		assert.Equal(t, []int{1, 3, 5}, c.Stride(2).ToSlice())
+	})
+
+	t.Run("Identity", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3})
+
+		assert.Equal(t, []int{1, 2, 3}, c.Stride(1).ToSlice())
+	})
+
+	t.Run("LargerThanLength", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3})
+
+		assert.Equal(t, []int{1}, c.Stride(10).ToSlice())
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		ch := make(chan int, 10)
+		for i := range 10 {
+			ch <- i
+		}
+		close(ch)
+
+		for v := range *collection.NewFromChannel(ch).Stride(3) {
+			assert.Equal(t, 0, v)
+			break
+		}
+
+		assert.Equal(t, 9, len(ch))
+	})
+
+	t.Run("InvalidStride", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1})
+
+		assert.Panics(t, func() { c.Stride(0) })
+		assert.Panics(t, func() { c.Stride(-1) })
+	})
+}
+
+func TestStrideFrom(t *testing.T) {
+	t.Run("Logic", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{0, 1, 2, 3, 4, 5, 6})
+
+		assert.Equal(t, []int{1, 4}, c.StrideFrom(1, 3).ToSlice())
+	})
+
+	t.Run("NegativeOffset", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{0, 1, 2, 3})
+
+		assert.Equal(t, []int{0, 2}, c.StrideFrom(-2, 2).ToSlice())
+	})
+
+	t.Run("OffsetBeyondLength", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{0, 1, 2})
+
+		assert.True(t, c.StrideFrom(5, 1).IsEmpty())
+	})
+
+	t.Run("InvalidStride", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1})
+
+		assert.Panics(t, func() { c.StrideFrom(1, 0) })
+	})
+}
+
+func TestSampleFraction(t *testing.T) {
+	t.Run("ZeroKeepsNothing", func(t *testing.T) {
+		result := collection.NewFromRange(0, 100).SampleFraction(0, nil)
+
+		assert.True(t, result.IsEmpty())
+	})
+
+	t.Run("OneKeepsEverything", func(t *testing.T) {
+		result := collection.NewFromRange(0, 100).SampleFraction(1, nil).ToSlice()
+
+		assert.Equal(t, collection.NewFromRange(0, 100).ToSlice(), result)
+	})
+
+	t.Run("HalfKeepsRoughlyHalf", func(t *testing.T) {
+		result := collection.NewFromRange(0, 10000).SampleFraction(0.5, rand.New(rand.NewSource(1))).ToSlice()
+
+		assert.InDelta(t, 5000, len(result), 250)
+		assert.True(t, slices.IsSorted(result))
+	})
+
+	t.Run("Reproducible", func(t *testing.T) {
+		c := collection.NewFromRange(0, 1000)
+		first := c.SampleFraction(0.1, rand.New(rand.NewSource(3))).ToSlice()
+		second := c.SampleFraction(0.1, rand.New(rand.NewSource(3))).ToSlice()
+
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("InvalidFraction", func(t *testing.T) {
+		c := collection.NewFromRange(0, 10)
+
+		assert.Panics(t, func() { c.SampleFraction(-0.1, nil) })
+		assert.Panics(t, func() { c.SampleFraction(1.1, nil) })
+		assert.Panics(t, func() { c.SampleFraction(math.NaN(), nil) })
+	})
+
+	t.Run("EarlyBreak", func(t *testing.T) {
+		consumed := 0
+		c := collection.New[int](iter.Seq[int](func(yield func(int) bool) {
+			for i := 0; ; i++ {
+				consumed++
+				if !yield(i) {
+					return
+				}
+			}
+		}))
+
+		result := c.SampleFraction(1, nil).Take(3).ToSlice()
+
+		assert.Equal(t, []int{0, 1, 2}, result)
+		assert.Equal(t, 3, consumed)
+	})
+}
+
+func TestTakeUntil(t *testing.T) {
+	t.Run("TakeUntilSome", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e"})
+		result := c.TakeUntil(func(x string) bool {
+			return x == "d"
+		}).ToSlice()
+
+		assert.Equal(t, 3, len(result))
+		assert.Equal(t, "a", result[0])
+		assert.Equal(t, "b", result[1])
+		assert.Equal(t, "c", result[2])
+	})
+
+	t.Run("TakeUntilAll", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		result := c.TakeUntil(func(x string) bool {
+			return x == "z"
+		}).ToSlice()
+
+		assert.Equal(t, 3, len(result))
+	})
+
+	t.Run("TakeUntilNone", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		result := c.TakeUntil(func(x string) bool {
+			return x == "a"
+		}).ToSlice()
+
+		assert.Equal(t, 0, len(result))
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e"})
+		for range *c.TakeUntil(func(x string) bool {
+			return x == "d"
+		}) {
+			break
+		}
+	})
+}
+
+func TestTakeWhile(t *testing.T) {
+	t.Run("TakeWhileSome", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4})
+		result := c.TakeWhile(func(x int) bool {
+			return x < 3
+		}).ToSlice()
+
+		assert.Equal(t, 2, len(result))
+		assert.Equal(t, 1, result[0])
+		assert.Equal(t, 2, result[1])
+	})
+
+	t.Run("TakeWhileAll", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4})
+		result := c.TakeWhile(func(x int) bool {
+			return x < 5
+		}).ToSlice()
+
+		assert.Equal(t, 4, len(result))
+		assert.Equal(t, 1, result[0])
+		assert.Equal(t, 2, result[1])
+		assert.Equal(t, 3, result[2])
+		assert.Equal(t, 4, result[3])
+	})
+
+	t.Run("TakeWhileNone", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{})
+		result := c.TakeWhile(func(x int) bool {
+			return x < 5
+		}).ToSlice()
+
+		assert.Equal(t, 0, len(result))
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4})
+		for range *c.TakeWhile(func(x int) bool {
+			return x < 5
+		}) {
+			break
+		}
+	})
+}
+
+func TestTakeLast(t *testing.T) {
+	t.Run("TakeSome", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e"})
+		result := c.TakeLast(3).ToSlice()
+
+		assert.Equal(t, 3, len(result))
+		assert.Equal(t, "c", result[0])
+		assert.Equal(t, "d", result[1])
+		assert.Equal(t, "e", result[2])
+	})
+
+	t.Run("TakeAll", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		result := c.TakeLast(3).ToSlice()
+
+		assert.Equal(t, 3, len(result))
+	})
+
+	t.Run("TakeMore", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		result := c.TakeLast(5).ToSlice()
+
+		assert.Equal(t, 3, len(result))
+	})
+
+	t.Run("TakeZero", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		result := c.TakeLast(0).ToSlice()
+
+		assert.Equal(t, 0, len(result))
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e"})
+		for range *c.TakeLast(3) {
+			break
+		}
+	})
+}
+
+func TestSkipWhile(t *testing.T) {
+	t.Run("SkipWhileSome", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4})
+		result := c.SkipWhile(func(x int) bool {
+			return x < 3
+		}).ToSlice()
+
+		assert.Equal(t, 2, len(result))
+		assert.Equal(t, 3, result[0])
+		assert.Equal(t, 4, result[1])
+	})
+
+	t.Run("SkipWhileAll", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4})
+		result := c.SkipWhile(func(x int) bool {
+			return x < 1
+		}).ToSlice()
+
+		assert.Equal(t, 4, len(result))
+		assert.Equal(t, 1, result[0])
+		assert.Equal(t, 2, result[1])
+		assert.Equal(t, 3, result[2])
+
+	})
+
+	t.Run("SkipWhileNone", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4})
+		result := c.SkipWhile(func(x int) bool {
+			return x < 5
+		}).ToSlice()
+
+		assert.Equal(t, 0, len(result))
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4})
+		for range *c.SkipWhile(func(x int) bool {
+			return false
+		}) {
+			break
+		}
+	})
+}
+
+func TestAny(t *testing.T) {
+	t.Run("True", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		v := c.Any(func(x string) bool {
+			return x == "b"
+		})
+		assert.True(t, v)
+	})
+
+	t.Run("False", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		v := c.Any(func(x string) bool {
+			return x == "d"
+		})
+		assert.False(t, v)
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{})
+		v := c.Any(func(x string) bool {
+			return true
+		})
+		assert.False(t, v)
+	})
+}
+
+func TestNone(t *testing.T) {
+	t.Run("True", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		v := c.None(func(x string) bool {
+			return x == "d"
+		})
+		assert.True(t, v)
+	})
+
+	t.Run("False", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		v := c.None(func(x string) bool {
+			return x == "b"
+		})
+		assert.False(t, v)
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{})
+		v := c.None(func(x string) bool {
+			return true
+		})
+		assert.True(t, v)
+	})
+}
+
+func TestOrderBy(t *testing.T) {
+	t.Run("IntAscending", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{3, 1, 4, 2})
+		result := c.OrderBy(func(x int) any {
+			return x
+		}, true).ToSlice()
+
+		assert.Equal(t, 4, len(result))
+		assert.Equal(t, 1, result[0])
+		assert.Equal(t, 2, result[1])
+		assert.Equal(t, 3, result[2])
+		assert.Equal(t, 4, result[3])
+	})
+
+	t.Run("IntDescending", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{3, 1, 4, 2})
+		result := c.OrderBy(func(x int) any {
+			return x
+		}, false).ToSlice()
+
+		assert.Equal(t, 4, len(result))
+		assert.Equal(t, 4, result[0])
+		assert.Equal(t, 3, result[1])
+		assert.Equal(t, 2, result[2])
+		assert.Equal(t, 1, result[3])
+	})
+
+	t.Run("Int8Ascending", func(t *testing.T) {
+		c := collection.NewFromSlice([]int8{3, 1, 4, 2})
+		result := c.OrderBy(func(x int8) any {
+			return x
+		}, true).ToSlice()
+
+		assert.Equal(t, 4, len(result))
+		assert.Equal(t, int8(1), result[0])
+		assert.Equal(t, int8(2), result[1])
+		assert.Equal(t, int8(3), result[2])
+		assert.Equal(t, int8(4), result[3])
+	})
+
+	t.Run("Int16Ascending", func(t *testing.T) {
+		c := collection.NewFromSlice([]int16{3, 1, 4, 2})
+		result := c.OrderBy(func(x int16) any {
+			return x
+		}, true).ToSlice()
+
+		assert.Equal(t, 4, len(result))
+		assert.Equal(t, int16(1), result[0])
+		assert.Equal(t, int16(2), result[1])
+		assert.Equal(t, int16(3), result[2])
+		assert.Equal(t, int16(4), result[3])
+	})
+
+	t.Run("Int32Ascending", func(t *testing.T) {
+		c := collection.NewFromSlice([]int32{3, 1, 4, 2})
+		result := c.OrderBy(func(x int32) any {
+			return x
+		}, true).ToSlice()
+
+		assert.Equal(t, 4, len(result))
+		assert.Equal(t, int32(1), result[0])
+		assert.Equal(t, int32(2), result[1])
+		assert.Equal(t, int32(3), result[2])
+		assert.Equal(t, int32(4), result[3])
+	})
+
+	t.Run("Int64Ascending", func(t *testing.T) {
+		c := collection.NewFromSlice([]int64{3, 1, 4, 2})
+		result := c.OrderBy(func(x int64) any {
+			return x
+		}, true).ToSlice()
+
+		assert.Equal(t, 4, len(result))
+		assert.Equal(t, int64(1), result[0])
+		assert.Equal(t, int64(2), result[1])
+		assert.Equal(t, int64(3), result[2])
+		assert.Equal(t, int64(4), result[3])
+	})
+
+	t.Run("UintAscending", func(t *testing.T) {
+		c := collection.NewFromSlice([]uint{3, 1, 4, 2})
+		result := c.OrderBy(func(x uint) any {
+			return x
+		}, true).ToSlice()
+
+		assert.Equal(t, 4, len(result))
+		assert.Equal(t, uint(1), result[0])
+		assert.Equal(t, uint(2), result[1])
+		assert.Equal(t, uint(3), result[2])
+		assert.Equal(t, uint(4), result[3])
+	})
+
+	t.Run("Uint8Ascending", func(t *testing.T) {
+		c := collection.NewFromSlice([]uint8{3, 1, 4, 2})
+		result := c.OrderBy(func(x uint8) any {
+			return x
+		}, true).ToSlice()
+
+		assert.Equal(t, 4, len(result))
+		assert.Equal(t, uint8(1), result[0])
+		assert.Equal(t, uint8(2), result[1])
+		assert.Equal(t, uint8(3), result[2])
+		assert.Equal(t, uint8(4), result[3])
+	})
+
+	t.Run("Uint16Ascending", func(t *testing.T) {
+		c := collection.NewFromSlice([]uint16{3, 1, 4, 2})
+		result := c.OrderBy(func(x uint16) any {
+			return x
+		}, true).ToSlice()
+
+		assert.Equal(t, 4, len(result))
+		assert.Equal(t, uint16(1), result[0])
+		assert.Equal(t, uint16(2), result[1])
+		assert.Equal(t, uint16(3), result[2])
+		assert.Equal(t, uint16(4), result[3])
+	})
+
+	t.Run("Uint32Ascending", func(t *testing.T) {
+		c := collection.NewFromSlice([]uint32{3, 1, 4, 2})
+		result := c.OrderBy(func(x uint32) any {
+			return x
+		}, true).ToSlice()
+
+		assert.Equal(t, 4, len(result))
+		assert.Equal(t, uint32(1), result[0])
+		assert.Equal(t, uint32(2), result[1])
+		assert.Equal(t, uint32(3), result[2])
+		assert.Equal(t, uint32(4), result[3])
+	})
+
+	t.Run("Uint64Ascending", func(t *testing.T) {
+		c := collection.NewFromSlice([]uint64{3, 1, 4, 2})
+		result := c.OrderBy(func(x uint64) any {
+			return x
+		}, true).ToSlice()
+
+		assert.Equal(t, 4, len(result))
+		assert.Equal(t, uint64(1), result[0])
+		assert.Equal(t, uint64(2), result[1])
+		assert.Equal(t, uint64(3), result[2])
+		assert.Equal(t, uint64(4), result[3])
+	})
+
+	t.Run("Float32Ascending", func(t *testing.T) {
+		c := collection.NewFromSlice([]float32{3, 1, 4, 2})
+		result := c.OrderBy(func(x float32) any {
+			return x
+		}, true).ToSlice()
+
+		assert.Equal(t, 4, len(result))
+		assert.Equal(t, float32(1), result[0])
+		assert.Equal(t, float32(2), result[1])
+		assert.Equal(t, float32(3), result[2])
+		assert.Equal(t, float32(4), result[3])
+	})
+
+	t.Run("Float64Ascending", func(t *testing.T) {
+		c := collection.NewFromSlice([]float64{3, 1, 4, 2})
+		result := c.OrderBy(func(x float64) any {
+			return x
+		}, true).ToSlice()
+
+		assert.Equal(t, 4, len(result))
+		assert.Equal(t, float64(1), result[0])
+		assert.Equal(t, float64(2), result[1])
+		assert.Equal(t, float64(3), result[2])
+		assert.Equal(t, float64(4), result[3])
+	})
+
+	t.Run("StringsAscending", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"banana", "apple", "cherry"})
+		result := c.OrderBy(func(x string) any {
+			return x
+		}, true).ToSlice()
+
+		assert.Equal(t, 3, len(result))
+		assert.Equal(t, "apple", result[0])
+		assert.Equal(t, "banana", result[1])
+		assert.Equal(t, "cherry", result[2])
+	})
+
+	t.Run("StringsDescending", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"banana", "apple", "cherry"})
+		result := c.OrderBy(func(x string) any {
+			return x
+		}, false).ToSlice()
+
+		assert.Equal(t, 3, len(result))
+		assert.Equal(t, "cherry", result[0])
+		assert.Equal(t, "banana", result[1])
+		assert.Equal(t, "apple", result[2])
+	})
+
+	t.Run("StructDefault", func(t *testing.T) {
+		type test struct{}
+
+		c := collection.NewFromSlice([]test{
+			{},
+			{},
+			{},
+		})
+
+		result := c.OrderBy(func(x test) any {
+			return x
+		}, true).ToSlice()
+
+		assert.Equal(t, 3, len(result))
+	})
+
+	t.Run("StructField", func(t *testing.T) {
+		type person struct {
+			Name string
+			Age  int
+		}
+
+		c := collection.NewFromSlice([]person{
+			{Name: "Bob", Age: 30},
+			{Name: "Alice", Age: 25},
+			{Name: "Charlie", Age: 35},
+		})
+
+		result := c.OrderBy(func(x person) any {
+			return x.Age
+		}, true).ToSlice()
+
+		assert.Equal(t, 3, len(result))
+		assert.Equal(t, "Alice", result[0].Name)
+		assert.Equal(t, "Bob", result[1].Name)
+		assert.Equal(t, "Charlie", result[2].Name)
+	})
+}
+
+func TestConcat(t *testing.T) {
+	t.Run("BothHaveElements", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]string{"a", "b"})
+		c2 := collection.NewFromSlice([]string{"c", "d"})
+
+		result := c1.Concat(c2).ToSlice()
+
+		assert.Equal(t, 4, len(result))
+		assert.Equal(t, "a", result[0])
+		assert.Equal(t, "b", result[1])
+		assert.Equal(t, "c", result[2])
+		assert.Equal(t, "d", result[3])
+	})
+
+	t.Run("FirstEmpty", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]string{})
+		c2 := collection.NewFromSlice([]string{"c", "d"})
+
+		result := c1.Concat(c2).ToSlice()
+
+		assert.Equal(t, 2, len(result))
+		assert.Equal(t, "c", result[0])
+		assert.Equal(t, "d", result[1])
+	})
+
+	t.Run("SecondEmpty", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]string{"a", "b"})
+		c2 := collection.NewFromSlice([]string{})
+
+		result := c1.Concat(c2).ToSlice()
+
+		assert.Equal(t, 2, len(result))
+		assert.Equal(t, "a", result[0])
+		assert.Equal(t, "b", result[1])
+	})
+
+	t.Run("BothEmpty", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]string{})
+		c2 := collection.NewFromSlice([]string{})
+
+		result := c1.Concat(c2).ToSlice()
+
+		assert.Equal(t, 0, len(result))
+	})
+
+	t.Run("NilTreatedAsEmpty", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b"})
+		var nilCollection *collection.Collection[string]
+
+		assert.Equal(t, []string{"a", "b"}, c.Concat(nil).ToSlice())
+		assert.Equal(t, []string{"a", "b"}, nilCollection.Concat(c).ToSlice())
+		assert.Empty(t, nilCollection.Concat(nil).ToSlice())
+	})
+
+	t.Run("BreakFirst", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]string{"a", "b"})
+		c2 := collection.NewFromSlice([]string{})
+
+		for range *c1.Concat(c2) {
+			break
+		}
+	})
+
+	t.Run("BreakSecond", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]string{})
+		c2 := collection.NewFromSlice([]string{"a", "b"})
+
+		for range *c1.Concat(c2) {
+			break
+		}
+	})
+
+	t.Run("ReceiverMutatedAfterBuild", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]int{1, 2})
+		c2 := collection.NewFromSlice([]int{3, 4})
+		result := c1.Concat(c2)
+		c1.Pop()
+		c2.Pop()
+
+		assert.Equal(t, []int{1, 3}, result.ToSlice())
+	})
+}
+
+func TestConcatAll(t *testing.T) {
+	t.Run("ThreeInputs", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2})
+		result := c.ConcatAll(collection.NewFromSlice([]int{3}), collection.NewFromSlice([]int{4, 5})).ToSlice()
+
+		assert.Equal(t, []int{1, 2, 3, 4, 5}, result)
+	})
+
+	t.Run("EmptyInMiddle", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1})
+		result := c.ConcatAll(collection.Empty[int](), nil, collection.NewFromSlice([]int{2})).ToSlice()
+
+		assert.Equal(t, []int{1, 2}, result)
+	})
+
+	t.Run("NilReceiverTreatedAsEmpty", func(t *testing.T) {
+		var c *collection.Collection[int]
+		result := c.ConcatAll(collection.NewFromSlice([]int{1, 2})).ToSlice()
+
+		assert.Equal(t, []int{1, 2}, result)
+	})
+
+	t.Run("NoOthers", func(t *testing.T) {
+		result := collection.NewFromSlice([]int{1, 2}).ConcatAll().ToSlice()
+
+		assert.Equal(t, []int{1, 2}, result)
+	})
+
+	t.Run("BreakDuringSecondInput", func(t *testing.T) {
+		pulled := 0
+		second := collection.NewFromSlice([]int{3, 4, 5}).Peek(func(int) { pulled++ })
+		third := collection.NewFromSlice([]int{6}).Peek(func(int) { t.Error("third collection should not be enumerated") })
+		result := collection.NewFromSlice([]int{1, 2}).ConcatAll(second, third).Take(3).ToSlice()
+
+		assert.Equal(t, []int{1, 2, 3}, result)
+		assert.Equal(t, 1, pulled)
+	})
+}
+
+func TestConcatCollections(t *testing.T) {
+	t.Run("ThreeInputs", func(t *testing.T) {
+		cs := collection.NewFromSlice([]*collection.Collection[string]{
+			collection.NewFromSlice([]string{"a"}),
+			collection.NewFromSlice([]string{"b", "c"}),
+			collection.NewFromSlice([]string{"d"}),
+		})
+
+		assert.Equal(t, []string{"a", "b", "c", "d"}, collection.ConcatCollections(cs).ToSlice())
+	})
+
+	t.Run("EmptyAndNilInMiddle", func(t *testing.T) {
+		cs := collection.NewFromSlice([]*collection.Collection[string]{
+			collection.NewFromSlice([]string{"a"}),
+			collection.Empty[string](),
+			nil,
+			collection.NewFromSlice([]string{"b", "c"}),
+		})
+
+		assert.Equal(t, []string{"a", "b", "c"}, collection.ConcatCollections(cs).ToSlice())
+	})
+
+	t.Run("BreakDuringSecondInput", func(t *testing.T) {
+		third := collection.NewFromSlice([]string{"d"}).Peek(func(string) { t.Error("third collection should not be enumerated") })
+		cs := collection.NewFromSlice([]*collection.Collection[string]{
+			collection.NewFromSlice([]string{"a"}),
+			collection.NewFromSlice([]string{"b", "c"}),
+			third,
+		})
+
+		assert.Equal(t, []string{"a", "b"}, collection.ConcatCollections(cs).Take(2).ToSlice())
+	})
+}
+
+func TestGroupBy(t *testing.T) {
+	t.Run("SimpleGrouping", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"apple", "banana", "cherry", "apricot", "blueberry"})
+
+		groups := c.GroupBy(func(x string) any {
+			return string(x[0]) // Group by first character
+		})
+
+		assert.Equal(t, 3, len(groups))
+
+		aGroup := groups["a"].ToSlice()
+		assert.Equal(t, 2, len(aGroup))
+		assert.Contains(t, aGroup, "apple")
+		assert.Contains(t, aGroup, "apricot")
+
+		bGroup := groups["b"].ToSlice()
+		assert.Equal(t, 2, len(bGroup))
+		assert.Contains(t, bGroup, "banana")
+		assert.Contains(t, bGroup, "blueberry")
+
+		cGroup := groups["c"].ToSlice()
+		assert.Equal(t, 1, len(cGroup))
+		assert.Equal(t, "cherry", cGroup[0])
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{})
+
+		groups := c.GroupBy(func(x string) any {
+			return string(x[0])
+		})
+
+		assert.Equal(t, 0, len(groups))
+	})
+
+	t.Run("GroupByLength", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "bb", "ccc", "dd", "eee", "f"})
+
+		groups := c.GroupBy(func(x string) any {
+			return len(x)
+		})
+
+		assert.Equal(t, 3, len(groups))
+
+		group1 := groups[1].ToSlice()
+		assert.Equal(t, 2, len(group1))
+		assert.Contains(t, group1, "a")
+		assert.Contains(t, group1, "f")
+
+		group2 := groups[2].ToSlice()
+		assert.Equal(t, 2, len(group2))
+		assert.Contains(t, group2, "bb")
+		assert.Contains(t, group2, "dd")
+
+		group3 := groups[3].ToSlice()
+		assert.Equal(t, 2, len(group3))
+		assert.Contains(t, group3, "ccc")
+		assert.Contains(t, group3, "eee")
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"apple", "banana", "cherry", "apricot", "blueberry"})
+
+		groups := c.GroupBy(func(x string) any {
+			return string(x[0])
+		})
+
+		for _, group := range groups {
+			for range *group {
+				break
+			}
+		}
+	})
+}
+
+func TestGroupByKey(t *testing.T) {
+	type product struct {
+		Name     string
+		Category string
+		Rating   int
+	}
+
+	c := collection.NewFromSlice([]product{
+		{Name: "Apple", Category: "Fruit", Rating: 5},
+		{Name: "Banana", Category: "Fruit", Rating: 3},
+		{Name: "Carrot", Category: "Vegetable", Rating: 5},
+	})
+
+	t.Run("IntKey", func(t *testing.T) {
+		groups := collection.GroupByKey(c, func(x product) int {
+			return x.Rating
+		})
+
+		assert.Len(t, groups, 2)
+
+		var names []string
+		for _, p := range groups[5].ToSlice() {
+			names = append(names, p.Name)
+		}
+		assert.Equal(t, []string{"Apple", "Carrot"}, names)
+		assert.Equal(t, 1, groups[3].Len())
+	})
+
+	t.Run("StringKey", func(t *testing.T) {
+		groups := collection.GroupByKey(c, func(x product) string {
+			return x.Category
+		})
+
+		assert.Len(t, groups, 2)
+		assert.Equal(t, 2, groups["Fruit"].Len())
+		assert.Equal(t, 1, groups["Vegetable"].Len())
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		groups := collection.GroupByKey(collection.NewFromSlice([]product{}), func(x product) string {
+			return x.Category
+		})
+
+		assert.Len(t, groups, 0)
+	})
+}
+
+func BenchmarkGroupBy(b *testing.B) {
+	c := collection.NewFromRange(0, 100000)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		c.GroupBy(func(x int) any {
+			return x % 10
+		})
+	}
+}
+
+func TestUnion(t *testing.T) {
+	t.Run("WithDuplicates", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]int{1, 2, 3, 4})
+		c2 := collection.NewFromSlice([]int{3, 4, 5, 6})
+
+		result := c1.Union(c2, func(a, b int) bool {
+			return a == b
+		}).ToSlice()
+
+		assert.Equal(t, 6, len(result))
+		assert.Contains(t, result, 1)
+		assert.Contains(t, result, 2)
+		assert.Contains(t, result, 3)
+		assert.Contains(t, result, 4)
+		assert.Contains(t, result, 5)
+		assert.Contains(t, result, 6)
+	})
+
+	t.Run("FirstEmpty", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]int{})
+		c2 := collection.NewFromSlice([]int{3, 4, 5})
+
+		result := c1.Union(c2, func(a, b int) bool {
+			return a == b
+		}).ToSlice()
+
+		assert.Equal(t, 3, len(result))
+		assert.Contains(t, result, 3)
+		assert.Contains(t, result, 4)
+		assert.Contains(t, result, 5)
+	})
+
+	t.Run("SecondEmpty", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]int{1, 2, 3})
+		c2 := collection.NewFromSlice([]int{})
+
+		result := c1.Union(c2, func(a, b int) bool {
+			return a == b
+		}).ToSlice()
+
+		assert.Equal(t, 3, len(result))
+		assert.Contains(t, result, 1)
+		assert.Contains(t, result, 2)
+		assert.Contains(t, result, 3)
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]int{1, 2, 3, 4})
+		c2 := collection.NewFromSlice([]int{3, 4, 5, 6})
+
+		for range *c1.Union(c2, func(a, b int) bool {
+			return a == b
+		}) {
+			break
+		}
+	})
+}
+
+func TestIntersect(t *testing.T) {
+	t.Run("WithCommonElements", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]int{1, 2, 3, 4})
+		c2 := collection.NewFromSlice([]int{3, 4, 5, 6})
+
+		result := c1.Intersect(c2, func(a, b int) bool {
+			return a == b
+		}).ToSlice()
+
+		assert.Equal(t, 2, len(result))
+		assert.Contains(t, result, 3)
+		assert.Contains(t, result, 4)
+	})
+
+	t.Run("NoCommonElements", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]int{1, 2})
+		c2 := collection.NewFromSlice([]int{3, 4})
+
+		result := c1.Intersect(c2, func(a, b int) bool {
+			return a == b
+		}).ToSlice()
+
+		assert.Equal(t, 0, len(result))
+	})
+
+	t.Run("EmptyFirst", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]int{})
+		c2 := collection.NewFromSlice([]int{3, 4})
+
+		result := c1.Intersect(c2, func(a, b int) bool {
+			return a == b
+		}).ToSlice()
+
+		assert.Equal(t, 0, len(result))
+	})
+
+	t.Run("EmptySecond", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]int{1, 2})
+		c2 := collection.NewFromSlice([]int{})
+
+		result := c1.Intersect(c2, func(a, b int) bool {
+			return a == b
+		}).ToSlice()
+
+		assert.Equal(t, 0, len(result))
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]int{1, 2, 3, 4})
+		c2 := collection.NewFromSlice([]int{3, 4, 5, 6})
+
+		for range *c1.Intersect(c2, func(a, b int) bool {
+			return a == b
+		}) {
+			break
+		}
+	})
+}
+
+func TestExcept(t *testing.T) {
+	t.Run("WithCommonElements", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]int{1, 2, 3, 4})
+		c2 := collection.NewFromSlice([]int{3, 4, 5, 6})
+
+		result := c1.Except(c2, func(a, b int) bool {
+			return a == b
+		}).ToSlice()
+
+		assert.Equal(t, 2, len(result))
+		assert.Contains(t, result, 1)
+		assert.Contains(t, result, 2)
+	})
+
+	t.Run("NoCommonElements", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]int{1, 2})
+		c2 := collection.NewFromSlice([]int{3, 4})
+
+		result := c1.Except(c2, func(a, b int) bool {
+			return a == b
+		}).ToSlice()
+
+		assert.Equal(t, 2, len(result))
+		assert.Contains(t, result, 1)
+		assert.Contains(t, result, 2)
+	})
+
+	t.Run("AllElementsCommon", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]int{1, 2})
+		c2 := collection.NewFromSlice([]int{1, 2, 3})
+
+		result := c1.Except(c2, func(a, b int) bool {
+			return a == b
+		}).ToSlice()
+
+		assert.Equal(t, 0, len(result))
+	})
+
+	t.Run("EmptyFirst", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]int{})
+		c2 := collection.NewFromSlice([]int{3, 4})
+
+		result := c1.Except(c2, func(a, b int) bool {
+			return a == b
+		}).ToSlice()
+
+		assert.Equal(t, 0, len(result))
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]int{1, 2, 3, 4})
+		c2 := collection.NewFromSlice([]int{3, 4, 5, 6})
+
+		for range *c1.Except(c2, func(a, b int) bool {
+			return a == b
+		}) {
+			break
+		}
+	})
+}
+
+func TestEquals(t *testing.T) {
+	t.Run("Equal", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]int{1, 2, 3})
+		c2 := collection.NewFromSlice([]int{1, 2, 3})
+
+		assert.True(t, c1.Equals(c2, func(a, b int) bool {
+			return a == b
+		}))
+	})
+
+	t.Run("NotEqual", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]int{1, 2, 3})
+		c2 := collection.NewFromSlice([]int{1, 2, 4})
+
+		assert.False(t, c1.Equals(c2, func(a, b int) bool {
+			return a == b
+		}))
+	})
+
+	t.Run("DifferentLengths", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]int{1, 2, 3})
+		c2 := collection.NewFromSlice([]int{1, 2, 3, 4})
+
+		assert.False(t, c1.Equals(c2, func(a, b int) bool {
+			return a == b
+		}))
+	})
+
+	t.Run("EmptyCollections", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]int{})
+		c2 := collection.NewFromSlice([]int{})
+
+		assert.True(t, c1.Equals(c2, func(a, b int) bool {
+			return a == b
+		}))
+	})
+
+	t.Run("MismatchAtFirstElement", func(t *testing.T) {
+		pulled1, pulled2 := 0, 0
+		c1 := collection.NewFromRange(0, 1000).Peek(func(int) { pulled1++ })
+		c2 := collection.NewFromRange(1, 1000).Peek(func(int) { pulled2++ })
+
+		assert.False(t, c1.Equals(c2, func(a, b int) bool {
+			return a == b
+		}))
+		assert.Equal(t, 1, pulled1)
+		assert.Equal(t, 1, pulled2)
+	})
+
+	t.Run("EqualLongCollections", func(t *testing.T) {
+		c1 := collection.NewFromRange(0, 100000)
+		c2 := collection.Iterate(0, func(x int) int { return x + 1 }).Take(100000)
+
+		assert.True(t, c1.Equals(c2, func(a, b int) bool {
+			return a == b
+		}))
+	})
+
+	t.Run("DifferentLengthsWithoutFullEnumeration", func(t *testing.T) {
+		pulled := 0
+		c1 := collection.NewFromRange(0, 3)
+		c2 := collection.Iterate(0, func(x int) int { return x + 1 }).Peek(func(int) { pulled++ })
+
+		assert.False(t, c1.Equals(c2, func(a, b int) bool {
+			return a == b
+		}))
+		assert.Equal(t, 4, pulled)
+	})
+}
+
+func TestReverse(t *testing.T) {
+	t.Run("Reversed", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		result := c.Reverse().ToSlice()
+
+		assert.Equal(t, 3, len(result))
+		assert.Equal(t, "c", result[0])
+		assert.Equal(t, "b", result[1])
+		assert.Equal(t, "a", result[2])
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		for range *c.Reverse() {
+			break
+		}
+	})
+}
+
+func TestAppend(t *testing.T) {
+	t.Run("Appends", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		result := c.Append("d").ToSlice()
+
+		assert.Equal(t, 4, len(result))
+		assert.Equal(t, "a", result[0])
+		assert.Equal(t, "b", result[1])
+		assert.Equal(t, "c", result[2])
+		assert.Equal(t, "d", result[3])
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		for range *c.Append("d") {
+			break
+		}
+	})
+}
+
+func TestPrepend(t *testing.T) {
+	t.Run("Prepends", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		result := c.Prepend("d").ToSlice()
+
+		assert.Equal(t, 4, len(result))
+		assert.Equal(t, "d", result[0])
+		assert.Equal(t, "a", result[1])
+		assert.Equal(t, "b", result[2])
+		assert.Equal(t, "c", result[3])
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		for range *c.Prepend("d") {
+			break
+		}
+	})
+}
+
+func TestInsert(t *testing.T) {
+	t.Run("AtStart", func(t *testing.T) {
+		result := collection.NewFromSlice([]int{1, 2, 3}).Insert(0, 0).ToSlice()
+
+		assert.Equal(t, []int{0, 1, 2, 3}, result)
+	})
+
+	t.Run("InMiddle", func(t *testing.T) {
+		result := collection.NewFromSlice([]int{1, 2, 3}).Insert(2, 9).ToSlice()
+
+		assert.Equal(t, []int{1, 2, 9, 3}, result)
+	})
+
+	t.Run("AtEnd", func(t *testing.T) {
+		result := collection.NewFromSlice([]int{1, 2, 3}).Insert(3, 4).ToSlice()
+
+		assert.Equal(t, []int{1, 2, 3, 4}, result)
+	})
+
+	t.Run("MultipleValues", func(t *testing.T) {
+		result := collection.NewFromSlice([]int{1, 4}).Insert(1, 2, 3).ToSlice()
+
+		assert.Equal(t, []int{1, 2, 3, 4}, result)
+	})
+
+	t.Run("BeyondEndAppends", func(t *testing.T) {
+		result := collection.NewFromSlice([]int{1, 2}).Insert(10, 3).ToSlice()
+
+		assert.Equal(t, []int{1, 2, 3}, result)
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		result := collection.Empty[int]().Insert(0, 1).ToSlice()
+
+		assert.Equal(t, []int{1}, result)
+	})
+
+	t.Run("NegativeIndex", func(t *testing.T) {
+		assert.Panics(t, func() { collection.NewFromSlice([]int{1}).Insert(-1, 0) })
+	})
+
+	t.Run("EarlyBreak", func(t *testing.T) {
+		c := collection.Iterate(0, func(x int) int { return x + 1 })
+		result := c.Insert(2, 100, 101).Take(4).ToSlice()
+
+		assert.Equal(t, []int{0, 1, 100, 101}, result)
+	})
+}
+
+func TestRemoveAt(t *testing.T) {
+	t.Run("First", func(t *testing.T) {
+		result := collection.NewFromSlice([]int{1, 2, 3}).RemoveAt(0).ToSlice()
+
+		assert.Equal(t, []int{2, 3}, result)
+	})
+
+	t.Run("Middle", func(t *testing.T) {
+		result := collection.NewFromSlice([]int{1, 2, 3}).RemoveAt(1).ToSlice()
+
+		assert.Equal(t, []int{1, 3}, result)
+	})
+
+	t.Run("Last", func(t *testing.T) {
+		result := collection.NewFromSlice([]int{1, 2, 3}).RemoveAt(2).ToSlice()
+
+		assert.Equal(t, []int{1, 2}, result)
+	})
+
+	t.Run("OutOfRange", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3})
+
+		assert.Equal(t, []int{1, 2, 3}, c.RemoveAt(3).ToSlice())
+		assert.Equal(t, []int{1, 2, 3}, c.RemoveAt(-1).ToSlice())
+	})
+}
+
+func TestRemoveRange(t *testing.T) {
+	t.Run("Middle", func(t *testing.T) {
+		result := collection.NewFromSlice([]int{1, 2, 3, 4, 5}).RemoveRange(1, 3).ToSlice()
+
+		assert.Equal(t, []int{1, 5}, result)
+	})
+
+	t.Run("PastEnd", func(t *testing.T) {
+		result := collection.NewFromSlice([]int{1, 2, 3, 4}).RemoveRange(2, 10).ToSlice()
+
+		assert.Equal(t, []int{1, 2}, result)
+	})
+
+	t.Run("NoOp", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3})
+
+		assert.Equal(t, []int{1, 2, 3}, c.RemoveRange(-1, 2).ToSlice())
+		assert.Equal(t, []int{1, 2, 3}, c.RemoveRange(1, 0).ToSlice())
+		assert.Equal(t, []int{1, 2, 3}, c.RemoveRange(5, 1).ToSlice())
+	})
+
+	t.Run("NoOpDoesNotAliasSource", func(t *testing.T) {
+		base := collection.NewFromSlice([]int{1, 2, 3, 4})
+
+		removed := base.RemoveAt(-1)
+		removed.RemoveWhere(func(x int) bool { return x%2 == 0 })
+
+		mapped := base.RemoveRange(1, 0)
+		mapped.MapInPlace(func(x int) int { return x * 10 })
+
+		assert.Equal(t, []int{1, 3}, removed.ToSlice())
+		assert.Equal(t, []int{10, 20, 30, 40}, mapped.ToSlice())
+		assert.Equal(t, []int{1, 2, 3, 4}, base.ToSlice())
+	})
+
+	t.Run("EarlyBreak", func(t *testing.T) {
+		c := collection.Iterate(0, func(x int) int { return x + 1 })
+		result := c.RemoveRange(1, 2).Take(3).ToSlice()
+
+		assert.Equal(t, []int{0, 3, 4}, result)
+	})
+}
+
+func TestReplaceWhere(t *testing.T) {
+	t.Run("SomeMatches", func(t *testing.T) {
+		result := collection.NewFromSlice([]string{"alice", "***", "bob"}).
+			ReplaceWhere(func(x string) bool { return x == "bob" }, "[redacted]").ToSlice()
+
+		assert.Equal(t, []string{"alice", "***", "[redacted]"}, result)
+	})
+
+	t.Run("NoMatches", func(t *testing.T) {
+		result := collection.NewFromSlice([]int{1, 2, 3}).ReplaceWhere(func(x int) bool { return x > 5 }, 0).ToSlice()
+
+		assert.Equal(t, []int{1, 2, 3}, result)
+	})
+
+	t.Run("AllMatch", func(t *testing.T) {
+		result := collection.NewFromSlice([]int{1, 2, 3}).ReplaceWhere(func(x int) bool { return true }, 0).ToSlice()
+
+		assert.Equal(t, []int{0, 0, 0}, result)
+	})
+
+	t.Run("EarlyBreak", func(t *testing.T) {
+		c := collection.Iterate(0, func(x int) int { return x + 1 })
+		result := c.ReplaceWhere(func(x int) bool { return x%2 == 1 }, -1).Take(4).ToSlice()
+
+		assert.Equal(t, []int{0, -1, 2, -1}, result)
+	})
+}
+
+func TestChunk(t *testing.T) {
+	t.Run("Chunked", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e", "f", "g", "h"})
+		result := c.Chunk(3)
+
+		assert.Equal(t, 3, len(result))
+
+		assert.Equal(t, 3, len(result[0].ToSlice()))
+		assert.Equal(t, "a", result[0].ToSlice()[0])
+		assert.Equal(t, "b", result[0].ToSlice()[1])
+		assert.Equal(t, "c", result[0].ToSlice()[2])
+
+		assert.Equal(t, 3, len(result[1].ToSlice()))
+		assert.Equal(t, "d", result[1].ToSlice()[0])
+		assert.Equal(t, "e", result[1].ToSlice()[1])
+		assert.Equal(t, "f", result[1].ToSlice()[2])
+
+		assert.Equal(t, 2, len(result[2].ToSlice()))
+		assert.Equal(t, "g", result[2].ToSlice()[0])
+		assert.Equal(t, "h", result[2].ToSlice()[1])
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c", "d", "e", "f", "g", "h"})
+		for _, chunk := range c.Chunk(3) {
+			for range *chunk {
+				break
+			}
+		}
+	})
+}
+
+func TestChunkSeq(t *testing.T) {
+	t.Run("EvenChunks", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4, 5, 6})
+
+		var chunks [][]int
+		for chunk := range *collection.ChunkSeq(c, 2) {
+			chunks = append(chunks, chunk.ToSlice())
+		}
+
+		assert.Equal(t, [][]int{{1, 2}, {3, 4}, {5, 6}}, chunks)
+	})
+
+	t.Run("PartialLastChunk", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4, 5})
+
+		var chunks [][]int
+		for chunk := range *collection.ChunkSeq(c, 2) {
+			chunks = append(chunks, chunk.ToSlice())
+		}
+
+		assert.Equal(t, [][]int{{1, 2}, {3, 4}, {5}}, chunks)
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{})
+
+		assert.True(t, collection.ChunkSeq(c, 2).IsEmpty())
+	})
+
+	t.Run("ChannelBreak", func(t *testing.T) {
+		ch := make(chan int, 10)
+		for i := range 10 {
+			ch <- i
+		}
+		close(ch)
+
+		for chunk := range *collection.ChunkSeq(collection.NewFromChannel(ch), 3) {
+			assert.Equal(t, []int{0, 1, 2}, chunk.ToSlice())
+			break
+		}
+
+		assert.Equal(t, 7, len(ch))
+	})
+
+	t.Run("InvalidSize", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1})
+
+		assert.Panics(t, func() { collection.ChunkSeq(c, 0) })
+	})
+}
+
+func TestAggregate(t *testing.T) {
+	t.Run("Logic", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"Amsterdam", "Berlin", "New York", "San Francisco"})
+
+		result := c.Aggregate("Paris", func(accumulator any, item string) any {
+			if len(accumulator.(string)) < len(item) {
+				return item
+			}
+
+			return accumulator
+		})
+
+		assert.Equal(t, "San Francisco", result)
+	})
+
+	t.Run("Sum", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4, 5})
+
+		result := c.Aggregate(0, func(accumulator any, item int) any {
+			return accumulator.(int) + item
+		})
+
+		assert.Equal(t, 15, result)
+	})
+
+	t.Run("Concatenation", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+
+		result := c.Aggregate("", func(accumulator any, item string) any {
+			return accumulator.(string) + item
+		})
+
+		assert.Equal(t, "abc", result)
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{})
+
+		seed := 10
+		result := c.Aggregate(seed, func(accumulator any, item int) any {
+			assert.Fail(t, "This should not be called")
+			return nil
+		})
+
+		assert.Equal(t, seed, result)
+	})
+}
+
+func TestAggregateOrError(t *testing.T) {
+	errInvalid := errors.New("invalid")
+
+	t.Run("MatchesAggregate", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4, 5})
+
+		expected := c.Aggregate(0, func(accumulator any, item int) any {
+			return accumulator.(int) + item
+		})
+		result, err := c.AggregateOrError(0, func(accumulator any, item int) (any, error) {
+			return accumulator.(int) + item, nil
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("StopsAtError", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "", "b"})
+
+		calls := 0
+		_, err := c.AggregateOrError("", func(accumulator any, item string) (any, error) {
+			calls++
+			if item == "" {
+				return nil, errInvalid
+			}
+			return accumulator.(string) + item, nil
+		})
+
+		assert.ErrorIs(t, err, errInvalid)
+		assert.ErrorContains(t, err, "element 1")
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{})
+
+		result, err := c.AggregateOrError(10, func(accumulator any, item int) (any, error) {
+			assert.Fail(t, "This should not be called")
+			return accumulator, nil
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 10, result)
+	})
+}
+
+func TestForEach(t *testing.T) {
+	numbers := collection.NewFromSlice([]int{1, 2, 3})
+
+	var results []int
+
+	numbers.ForEach(func(x int) {
+		results = append(results, x)
+	})
+
+	if len(results) != 3 {
+		t.Errorf("Expected 3 results, got %d", len(results))
+	}
+}
+
+func TestParallelForEach(t *testing.T) {
+	t.Run("SingleThread", func(t *testing.T) {
+		numbers := collection.NewFromSlice([]int{1, 2})
+
+		start := time.Now()
+
+		var results []int
+		var mu sync.Mutex
+
+		err := numbers.ParallelForEach(
+			context.Background(),
+			func(ctx context.Context, x int) error {
+				time.Sleep(1 * time.Second)
+
+				mu.Lock()
+				results = append(results, x)
+				mu.Unlock()
+				return nil
+			},
+			1,
+		)
+
+		duration := time.Since(start)
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if duration < 2*time.Second {
+			t.Error("Expected single-threaded execution")
+		}
+
+		if len(results) != 2 {
+			t.Errorf("Expected 2 results, got %d", len(results))
+		}
+	})
+
+	t.Run("MultiThread", func(t *testing.T) {
+		numbers := collection.NewFromSlice([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+
+		start := time.Now()
+
+		var results []int
+		var mu sync.Mutex
+
+		err := numbers.ParallelForEach(
+			context.Background(),
+			func(ctx context.Context, x int) error {
+				time.Sleep(1 * time.Second)
+
+				mu.Lock()
+				results = append(results, x)
+				mu.Unlock()
+				return nil
+			},
+			5,
+		)
+
+		duration := time.Since(start)
+
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if duration > 3*time.Second {
+			t.Error("Expected parallel execution")
+		}
+
+		if len(results) != 10 {
+			t.Errorf("Expected 10 results, got %d", len(results))
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		numbers := collection.NewFromSlice([]int{1, 2, 3, 4, 5})
+
+		err := numbers.ParallelForEach(
+			context.Background(),
+			func(ctx context.Context, x int) error {
+				if x == 3 {
+					return errors.New("error")
+				}
+				return nil
+			},
+			1,
+		)
+
+		if err == nil {
+			t.Error("Expected error, got nil")
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		numbers := collection.NewFromSlice([]int{1, 2, 3, 4, 5, 6})
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		go func() {
+			time.Sleep(1 * time.Second)
+			cancel()
+		}()
+
+		err := numbers.ParallelForEach(
+			ctx,
+			func(ctx context.Context, x int) error {
+				if x == 3 {
+					return errors.New("error")
+				}
+
+				time.Sleep(1 * time.Second)
+				return nil
+			},
+			1,
+		)
+
+		assert.IsType(t, context.Canceled, err)
+	})
+}
+
+func TestForEachCtx(t *testing.T) {
+	t.Run("Logic", func(t *testing.T) {
+		var results []int
+
+		err := collection.NewFromSlice([]int{1, 2, 3}).ForEachCtx(context.Background(), func(ctx context.Context, x int) error {
+			results = append(results, x)
+			return nil
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []int{1, 2, 3}, results)
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		pulled := 0
+		c := collection.NewFromRange(0, 100).Peek(func(int) { pulled++ })
+
+		err := c.ForEachCtx(ctx, func(ctx context.Context, x int) error {
+			if x == 2 {
+				cancel()
+			}
+			return nil
+		})
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 3, pulled)
+	})
+
+	t.Run("AlreadyCancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		pulled := 0
+		c := collection.NewFromRange(0, 100).Peek(func(int) { pulled++ })
+
+		err := c.ForEachCtx(ctx, func(ctx context.Context, x int) error {
+			t.Error("action should not be called")
+			return nil
+		})
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 0, pulled)
+	})
+
+	t.Run("ActionError", func(t *testing.T) {
+		errAction := errors.New("failed")
+		calls := 0
+
+		err := collection.NewFromSlice([]int{1, 2, 3}).ForEachCtx(context.Background(), func(ctx context.Context, x int) error {
+			calls++
+			if x == 2 {
+				return errAction
+			}
+			return nil
+		})
+
+		assert.ErrorIs(t, err, errAction)
+		assert.Equal(t, 2, calls)
+	})
+}
+
+type fakeLimiter struct {
+	waits atomic.Int64
+}
+
+func (l *fakeLimiter) Wait(ctx context.Context) error {
+	l.waits.Add(1)
+	return ctx.Err()
+}
+
+func TestForEachRate(t *testing.T) {
+	t.Run("WaitPerElement", func(t *testing.T) {
+		limiter := &fakeLimiter{}
+		var results []int
+
+		err := collection.NewFromSlice([]int{1, 2, 3}).ForEachRate(context.Background(), func(ctx context.Context, x int) error {
+			results = append(results, x)
+			return nil
+		}, limiter)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []int{1, 2, 3}, results)
+		assert.Equal(t, int64(3), limiter.waits.Load())
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		limiter := &fakeLimiter{}
+		calls := 0
+
+		err := collection.NewFromSlice([]int{1, 2, 3}).ForEachRate(ctx, func(ctx context.Context, x int) error {
+			calls++
+			cancel()
+			return nil
+		}, limiter)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("ActionError", func(t *testing.T) {
+		errAction := errors.New("failed")
+
+		err := collection.NewFromSlice([]int{1, 2, 3}).ForEachRate(context.Background(), func(ctx context.Context, x int) error {
+			return errAction
+		}, &fakeLimiter{})
+
+		assert.ErrorIs(t, err, errAction)
+	})
+}
+
+func TestParallelForEachRate(t *testing.T) {
+	t.Run("WaitPerElement", func(t *testing.T) {
+		limiter := &fakeLimiter{}
+		var count atomic.Int64
+
+		err := collection.NewFromRange(0, 20).ParallelForEachRate(context.Background(), func(ctx context.Context, x int) error {
+			count.Add(1)
+			return nil
+		}, limiter, 4)
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(20), count.Load())
+		assert.Equal(t, int64(20), limiter.waits.Load())
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := collection.NewFromRange(0, 20).ParallelForEachRate(ctx, func(ctx context.Context, x int) error {
+			assert.Fail(t, "This should not be called")
+			return nil
+		}, &fakeLimiter{}, 4)
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestRetryForEach(t *testing.T) {
+	errTransient := errors.New("transient")
+	noBackoff := func(int) time.Duration { return 0 }
+
+	t.Run("TransientFailure", func(t *testing.T) {
+		calls := map[int]int{}
+		var backoffs []int
+
+		err := collection.NewFromSlice([]int{1, 2}).RetryForEach(context.Background(), func(ctx context.Context, x int) error {
+			calls[x]++
+			if x == 2 && calls[x] == 1 {
+				return errTransient
+			}
+			return nil
+		}, 3, func(attempt int) time.Duration {
+			backoffs = append(backoffs, attempt)
+			return time.Millisecond
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, map[int]int{1: 1, 2: 2}, calls)
+		assert.Equal(t, []int{1}, backoffs)
+	})
+
+	t.Run("PermanentFailure", func(t *testing.T) {
+		calls := map[int]int{}
+
+		err := collection.NewFromSlice([]int{1, 2, 3}).RetryForEach(context.Background(), func(ctx context.Context, x int) error {
+			calls[x]++
+			if x != 2 {
+				return errTransient
+			}
+			return nil
+		}, 3, noBackoff)
+
+		assert.ErrorIs(t, err, errTransient)
+		assert.ErrorContains(t, err, "element 0: failed after 3 attempts")
+		assert.ErrorContains(t, err, "element 2: failed after 3 attempts")
+		assert.Equal(t, map[int]int{1: 3, 2: 1, 3: 3}, calls)
+	})
+
+	t.Run("NotRetryable", func(t *testing.T) {
+		errPermanent := errors.New("permanent")
+		calls := 0
+
+		err := collection.NewFromSlice([]int{1}).RetryForEachIf(context.Background(), func(ctx context.Context, x int) error {
+			calls++
+			return errPermanent
+		}, 5, noBackoff, func(err error) bool {
+			return !errors.Is(err, errPermanent)
+		})
+
+		assert.ErrorIs(t, err, errPermanent)
+		assert.ErrorContains(t, err, "element 0: failed after 1 attempts")
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("CancelDuringBackoff", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		calls := 0
+
+		start := time.Now()
+		err := collection.NewFromSlice([]int{1, 2}).RetryForEach(ctx, func(ctx context.Context, x int) error {
+			calls++
+			time.AfterFunc(10*time.Millisecond, cancel)
+			return errTransient
+		}, 3, func(int) time.Duration { return time.Minute })
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, calls)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
+
+func TestParallelForEachWithOptions(t *testing.T) {
+	t.Run("PanicRecovered", func(t *testing.T) {
+		numbers := collection.NewFromSlice([]int{1, 2, 3, 4, 5})
+
+		var ran atomic.Int64
+		err := numbers.ParallelForEach(
+			context.Background(),
+			func(ctx context.Context, x int) error {
+				if x == 3 {
+					panic("boom")
+				}
+				ran.Add(1)
+				return nil
+			},
+			1,
+		)
+
+		var panicErr *collection.PanicError
+		assert.ErrorAs(t, err, &panicErr)
+		assert.Equal(t, "boom", panicErr.Value)
+		assert.Contains(t, string(panicErr.Stack), "TestParallelForEachWithOptions")
+		assert.ErrorContains(t, err, "element 2: panic: boom")
+		assert.Equal(t, int64(2), ran.Load())
+	})
+
+	t.Run("PanicWithConcurrency", func(t *testing.T) {
+		numbers := collection.NewFromRange(0, 20)
+
+		err := numbers.ParallelForEachWithOptions(
+			context.Background(),
+			func(ctx context.Context, x int) error {
+				if x == 7 {
+					panic(errors.New("failure"))
+				}
+				return nil
+			},
+			collection.ParallelForEachOptions{Concurrency: 4},
+		)
+
+		assert.ErrorContains(t, err, "element 7: panic: failure")
+	})
+
+	t.Run("ProgressKnownTotal", func(t *testing.T) {
+		var progress [][2]int
+		err := collection.NewFromRange(0, 50).ParallelForEachWithOptions(
+			context.Background(),
+			func(ctx context.Context, x int) error {
+				return nil
+			},
+			collection.ParallelForEachOptions{
+				Concurrency: 4,
+				Total:       50,
+				OnProgress: func(done, total int) {
+					progress = append(progress, [2]int{done, total})
+				},
+			},
+		)
+
+		expected := make([][2]int, 0, 50)
+		for done := 1; done <= 50; done++ {
+			expected = append(expected, [2]int{done, 50})
+		}
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, progress)
+	})
+
+	t.Run("ProgressUnknownTotal", func(t *testing.T) {
+		var progress [][2]int
+		ch := make(chan int)
+		progressed := make(chan struct{}, 3)
+		go func() {
+			defer close(ch)
+			for i := range 3 {
+				ch <- i
+				<-progressed
+			}
+		}()
+
+		err := collection.NewFromChannel(ch).ParallelForEachWithOptions(
+			context.Background(),
+			func(ctx context.Context, x int) error {
+				return nil
+			},
+			collection.ParallelForEachOptions{
+				Concurrency: 3,
+				OnProgress: func(done, total int) {
+					progress = append(progress, [2]int{done, total})
+					progressed <- struct{}{}
+				},
+			},
+		)
+
+		assert.Nil(t, err)
+		assert.Equal(t, [][2]int{{1, -1}, {2, -1}, {3, -1}}, progress)
+	})
+
+	t.Run("ItemTimeout", func(t *testing.T) {
+		var completed atomic.Int64
+		err := collection.NewFromRange(0, 5).ParallelForEachWithOptions(
+			context.Background(),
+			func(ctx context.Context, x int) error {
+				if x == 3 {
+					<-ctx.Done()
+					return ctx.Err()
+				}
+				completed.Add(1)
+				return nil
+			},
+			collection.ParallelForEachOptions{Concurrency: 5, ItemTimeout: 20 * time.Millisecond},
+		)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.ErrorContains(t, err, "element 3: timed out after 20ms")
+		assert.Equal(t, int64(4), completed.Load())
+	})
+
+	t.Run("FailFast", func(t *testing.T) {
+		errFirst := errors.New("first")
+		var started atomic.Int64
+		err := collection.NewFromRange(0, 10).ParallelForEachWithOptions(
+			context.Background(),
+			func(ctx context.Context, x int) error {
+				started.Add(1)
+				if x == 0 {
+					return errFirst
+				}
+				return nil
+			},
+			collection.ParallelForEachOptions{Concurrency: 1},
+		)
+
+		assert.ErrorIs(t, err, errFirst)
+		assert.Equal(t, int64(1), started.Load())
+	})
+
+	t.Run("ContinueOnError", func(t *testing.T) {
+		errOne := errors.New("one")
+		errThree := errors.New("three")
+		var completed atomic.Int64
+		err := collection.NewFromRange(0, 5).ParallelForEachWithOptions(
+			context.Background(),
+			func(ctx context.Context, x int) error {
+				switch x {
+				case 1:
+					return errOne
+				case 3:
+					return errThree
+				}
+				if ctx.Err() == nil {
+					completed.Add(1)
+				}
+				return nil
+			},
+			collection.ParallelForEachOptions{Concurrency: 1, ContinueOnError: true},
+		)
+
+		assert.ErrorIs(t, err, errOne)
+		assert.ErrorIs(t, err, errThree)
+		assert.Equal(t, "element 1: one\nelement 3: three", err.Error())
+		assert.Equal(t, int64(3), completed.Load())
+	})
+
+	t.Run("ContinueOnErrorWithTimeoutAndPanic", func(t *testing.T) {
+		err := collection.NewFromRange(0, 3).ParallelForEachWithOptions(
+			context.Background(),
+			func(ctx context.Context, x int) error {
+				switch x {
+				case 0:
+					<-ctx.Done()
+					return ctx.Err()
+				case 2:
+					panic("boom")
+				}
+				return nil
+			},
+			collection.ParallelForEachOptions{Concurrency: 3, ItemTimeout: time.Millisecond, ContinueOnError: true},
+		)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.ErrorContains(t, err, "element 0: timed out after 1ms")
+		assert.ErrorContains(t, err, "element 2: panic: boom")
+		assert.NotContains(t, err.Error(), "element 0: element 0")
+	})
+
+	t.Run("ParentCancelled", func(t *testing.T) {
+		for _, continueOnError := range []bool{false, true} {
+			ctx, cancel := context.WithCancel(context.Background())
+			pulled := 0
+			c := collection.NewFromRange(0, 100).Peek(func(x int) {
+				pulled++
+				if x == 2 {
+					cancel()
+				}
+			})
+
+			var ran atomic.Int64
+			err := c.ParallelForEachWithOptions(
+				ctx,
+				func(ctx context.Context, x int) error {
+					ran.Add(1)
+					return nil
+				},
+				collection.ParallelForEachOptions{Concurrency: 1, ContinueOnError: continueOnError},
+			)
+
+			assert.ErrorIs(t, err, context.Canceled)
+			assert.Equal(t, "context canceled", err.Error())
+			assert.Equal(t, 3, pulled)
+			assert.LessOrEqual(t, ran.Load(), int64(2))
+		}
+	})
+
+	t.Run("ParentAlreadyCancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		pulled := 0
+		c := collection.NewFromRange(0, 100).Peek(func(int) { pulled++ })
+
+		err := c.ParallelForEachWithOptions(
+			ctx,
+			func(ctx context.Context, x int) error { return nil },
+			collection.ParallelForEachOptions{ContinueOnError: true},
+		)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 0, pulled)
+	})
+
+	t.Run("ItemTimeoutWrapsActionError", func(t *testing.T) {
+		errSlow := errors.New("slow upstream")
+		err := collection.NewFromSlice([]int{1}).ParallelForEachWithOptions(
+			context.Background(),
+			func(ctx context.Context, x int) error {
+				<-ctx.Done()
+				return errSlow
+			},
+			collection.ParallelForEachOptions{ItemTimeout: time.Millisecond},
+		)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.ErrorIs(t, err, errSlow)
+	})
+
+	t.Run("DisablePanicRecovery", func(t *testing.T) {
+		if os.Getenv("COLLECTION_PARALLEL_PANIC") == "1" {
+			collection.NewFromSlice([]int{1}).ParallelForEachWithOptions(
+				context.Background(),
+				func(ctx context.Context, x int) error {
+					panic("boom")
+				},
+				collection.ParallelForEachOptions{DisablePanicRecovery: true},
+			)
+			return
+		}
+
+		cmd := exec.Command(os.Args[0], "-test.run=^TestParallelForEachWithOptions$/^DisablePanicRecovery$")
+		cmd.Env = append(os.Environ(), "COLLECTION_PARALLEL_PANIC=1")
+		output, err := cmd.CombinedOutput()
+
+		var exitErr *exec.ExitError
+		assert.ErrorAs(t, err, &exitErr)
+		assert.Contains(t, string(output), "panic: boom")
+	})
+}
+
+func TestPeek(t *testing.T) {
+	t.Run("Peek", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3})
+
+		var results []int
+		c.Peek(func(x int) {
+			results = append(results, x)
+		}).ToSlice()
+
+		assert.Equal(t, 3, len(results))
+		assert.Equal(t, 1, results[0])
+		assert.Equal(t, 2, results[1])
+		assert.Equal(t, 3, results[2])
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3})
+
+		for range *c.Peek(func(x int) {}) {
+			break
+		}
+	})
+}
+
+func TestZip(t *testing.T) {
+	t.Run("EqualLength", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]int{1, 2, 3})
+		c2 := collection.NewFromSlice([]string{"a", "b", "c"})
+
+		result := collection.Zip(c1, c2, func(a int, b string) string {
+			return strconv.Itoa(a) + b
+		}).ToSlice()
+
+		assert.Equal(t, 3, len(result))
+		assert.Equal(t, "1a", result[0])
+		assert.Equal(t, "2b", result[1])
+		assert.Equal(t, "3c", result[2])
+	})
+
+	t.Run("FirstShorter", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]int{1, 2})
+		c2 := collection.NewFromSlice([]string{"a", "b", "c"})
+
+		result := collection.Zip(c1, c2, func(a int, b string) string {
+			return strconv.Itoa(a) + b
+		}).ToSlice()
+
+		assert.Equal(t, 2, len(result))
+		assert.Equal(t, "1a", result[0])
+		assert.Equal(t, "2b", result[1])
+	})
+
+	t.Run("SecondShorter", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]int{1, 2, 3})
+		c2 := collection.NewFromSlice([]string{"a", "b"})
+
+		result := collection.Zip(c1, c2, func(a int, b string) string {
+			return strconv.Itoa(a) + b
+		}).ToSlice()
+
+		assert.Equal(t, 2, len(result))
+		assert.Equal(t, "1a", result[0])
+		assert.Equal(t, "2b", result[1])
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]int{1, 2, 3})
+		c2 := collection.NewFromSlice([]string{"a", "b", "c"})
+
+		for range *collection.Zip(c1, c2, func(a int, b string) string {
+			return strconv.Itoa(a) + b
+		}) {
+			break
+		}
+	})
+}
+
+func TestZip3(t *testing.T) {
+	format := func(ts int, v float64, label string) string {
+		return fmt.Sprintf("%d:%s=%g", ts, label, v)
+	}
+
+	t.Run("EqualLengths", func(t *testing.T) {
+		result := collection.Zip3(
+			collection.NewFromSlice([]int{1, 2, 3}),
+			collection.NewFromSlice([]float64{0.5, 1.5, 2.5}),
+			collection.NewFromSlice([]string{"a", "b", "c"}),
+			format,
+		).ToSlice()
+
+		assert.Equal(t, []string{"1:a=0.5", "2:b=1.5", "3:c=2.5"}, result)
+	})
+
+	t.Run("EachInputShortest", func(t *testing.T) {
+		long := []int{1, 2, 3}
+		longF := []float64{1, 2, 3}
+		longS := []string{"a", "b", "c"}
+
+		assert.Len(t, collection.Zip3(collection.NewFromSlice(long[:1]), collection.NewFromSlice(longF), collection.NewFromSlice(longS), format).ToSlice(), 1)
+		assert.Len(t, collection.Zip3(collection.NewFromSlice(long), collection.NewFromSlice(longF[:2]), collection.NewFromSlice(longS), format).ToSlice(), 2)
+		assert.Len(t, collection.Zip3(collection.NewFromSlice(long), collection.NewFromSlice(longF), collection.NewFromSlice(longS[:0]), format).ToSlice(), 0)
+	})
+
+	t.Run("EarlyBreak", func(t *testing.T) {
+		naturals := collection.Iterate(0, func(x int) int { return x + 1 })
+		result := collection.Zip3(naturals, collection.NewFromFloatRange(0, 100, 1), collection.Iterate("x", func(x string) string { return x }), format).Take(2).ToSlice()
+
+		assert.Equal(t, []string{"0:x=0", "1:x=1"}, result)
+	})
+}
+
+func TestInterleave(t *testing.T) {
+	t.Run("EqualLengths", func(t *testing.T) {
+		result := collection.Interleave(
+			collection.NewFromSlice([]string{"a1", "a2"}),
+			collection.NewFromSlice([]string{"b1", "b2"}),
+			collection.NewFromSlice([]string{"c1", "c2"}),
+		).ToSlice()
+
+		assert.Equal(t, []string{"a1", "b1", "c1", "a2", "b2", "c2"}, result)
+	})
+
+	t.Run("UnequalLengths", func(t *testing.T) {
+		result := collection.Interleave(
+			collection.NewFromSlice([]int{1, 4, 6, 8}),
+			collection.NewFromSlice([]int{2}),
+			collection.NewFromSlice([]int{3, 5, 7}),
+		).ToSlice()
+
+		assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8}, result)
+	})
+
+	t.Run("SingleInput", func(t *testing.T) {
+		result := collection.Interleave(collection.NewFromSlice([]int{1, 2, 3})).ToSlice()
+
+		assert.Equal(t, []int{1, 2, 3}, result)
+	})
+
+	t.Run("EmptyInputAmongSeveral", func(t *testing.T) {
+		result := collection.Interleave(
+			collection.NewFromSlice([]int{1, 3}),
+			collection.Empty[int](),
+			collection.NewFromSlice([]int{2, 4}),
+		).ToSlice()
+
+		assert.Equal(t, []int{1, 2, 3, 4}, result)
+	})
+
+	t.Run("NoInputs", func(t *testing.T) {
+		assert.True(t, collection.Interleave[int]().IsEmpty())
+	})
+
+	t.Run("EarlyBreak", func(t *testing.T) {
+		evens := collection.Iterate(0, func(x int) int { return x + 2 })
+		odds := collection.Iterate(1, func(x int) int { return x + 2 })
+		result := collection.Interleave(evens, odds).Take(5).ToSlice()
+
+		assert.Equal(t, []int{0, 1, 2, 3, 4}, result)
+	})
+}
+
+func TestPrefetch(t *testing.T) {
+	t.Run("Order", func(t *testing.T) {
+		c := collection.NewFromRange(0, 1000)
+
+		assert.Equal(t, c.ToSlice(), c.Prefetch(10).ToSlice())
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3})
+
+		assert.Equal(t, []int{1, 2, 3}, c.Prefetch(0).ToSlice())
+		assert.Equal(t, []int{1, 2, 3}, c.Prefetch(-1).ToSlice())
+	})
+
+	t.Run("DisabledDoesNotAliasSource", func(t *testing.T) {
+		base := collection.NewFromSlice([]int{1, 3})
+
+		result := base.Prefetch(0)
+		result.MapInPlace(func(x int) int { return x * 10 })
+
+		assert.Equal(t, []int{10, 30}, result.ToSlice())
+		assert.Equal(t, []int{1, 3}, base.ToSlice())
+	})
+
+	t.Run("NoLeakOnBreak", func(t *testing.T) {
+		before := runtime.NumGoroutine()
+
+		for range *collection.NewFromRange(0, 1000000).Prefetch(4) {
+			break
+		}
+
+		for range 100 {
+			if runtime.NumGoroutine() <= before {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+	})
+}
+
+func BenchmarkPrefetch(b *testing.B) {
+	c := collection.NewFromRange(0, 20).Peek(func(int) { time.Sleep(100 * time.Microsecond) })
+
+	b.Run("Sequential", func(b *testing.B) {
+		for b.Loop() {
+			for range *c {
+				time.Sleep(100 * time.Microsecond)
+			}
+		}
+	})
+
+	b.Run("Prefetch", func(b *testing.B) {
+		for b.Loop() {
+			for range *c.Prefetch(4) {
+				time.Sleep(100 * time.Microsecond)
+			}
+		}
+	})
+}
+
+func TestCache(t *testing.T) {
+	newChannel := func(n int) <-chan int {
+		ch := make(chan int, n)
+		for i := range n {
+			ch <- i
+		}
+		close(ch)
+		return ch
+	}
+
+	t.Run("Replay", func(t *testing.T) {
+		c := collection.NewFromChannel(newChannel(5)).Cache()
+
+		assert.Equal(t, []int{0, 1, 2, 3, 4}, c.ToSlice())
+		assert.Equal(t, []int{0, 1, 2, 3, 4}, c.ToSlice())
+		assert.Equal(t, 5, c.Count())
+	})
+
+	t.Run("StopReleasesSource", func(t *testing.T) {
+		source, released := newReleaseTrackingSource()
+		c, stop := source.CacheStop()
+
+		assert.Equal(t, []int{0, 1, 2}, c.Take(3).ToSlice())
+		assert.False(t, released())
+
+		stop()
+		assert.True(t, released())
+		assert.Equal(t, []int{0, 1, 2}, c.ToSlice())
+	})
+
+	t.Run("EnumeratedToEndReleasesSource", func(t *testing.T) {
+		source, released := newReleaseTrackingSource()
+		c := source.Take(3).Cache()
+
+		assert.Equal(t, []int{0, 1, 2}, c.ToSlice())
+		assert.True(t, released())
+	})
+
+	t.Run("PartialThenFull", func(t *testing.T) {
+		ch := newChannel(5)
+		c := collection.NewFromChannel(ch).Cache()
+
+		assert.Equal(t, []int{0, 1}, c.Take(2).ToSlice())
+		assert.Equal(t, 3, len(ch))
+		assert.Equal(t, []int{0, 1, 2, 3, 4}, c.ToSlice())
+	})
+
+	t.Run("SourceEnumeratedOnce", func(t *testing.T) {
+		calls := 0
+		c := collection.NewFromSlice([]int{1, 2, 3}).Peek(func(int) { calls++ }).Cache()
+
+		c.ToSlice()
+		c.ToSlice()
+
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("ConcurrentReaders", func(t *testing.T) {
+		c := collection.NewFromChannel(newChannel(100)).Cache()
+
+		var wg sync.WaitGroup
+		results := make([][]int, 4)
+		for i := range results {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i] = c.ToSlice()
+			}()
+		}
+		wg.Wait()
+
+		for _, result := range results {
+			assert.Equal(t, collection.NewFromRange(0, 100).ToSlice(), result)
+		}
+	})
+
+	t.Run("ReplayWhileSourceIdle", func(t *testing.T) {
+		parked := make(chan struct{})
+		release := make(chan struct{})
+		c := collection.New[int](iter.Seq[int](func(yield func(int) bool) {
+			if !yield(1) || !yield(2) {
+				return
+			}
+			close(parked)
+			<-release
+		})).Cache()
+
+		finished := make(chan struct{})
+		go func() {
+			defer close(finished)
+			c.ToSlice()
+		}()
+		<-parked
+
+		replayed := make(chan []int)
+		go func() { replayed <- c.Take(2).ToSlice() }()
+
+		select {
+		case result := <-replayed:
+			assert.Equal(t, []int{1, 2}, result)
+		case <-time.After(time.Second):
+			t.Error("replay blocked behind the enumeration waiting on the source")
+		}
+		close(release)
+		<-finished
+	})
+}
+
+func TestCycle(t *testing.T) {
+	t.Run("Take", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3})
+
+		assert.Equal(t, []int{1, 2, 3, 1, 2, 3, 1}, c.Cycle().Take(7).ToSlice())
+	})
+
+	t.Run("Channel", func(t *testing.T) {
+		ch := make(chan string, 2)
+		ch <- "a"
+		ch <- "b"
+		close(ch)
+
+		assert.Equal(t, []string{"a", "b", "a", "b", "a"}, collection.NewFromChannel(ch).Cycle().Take(5).ToSlice())
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{})
+
+		assert.True(t, c.Cycle().IsEmpty())
+	})
+}
+
+func TestElementAt(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		v, ok := c.ElementAt(0)
+
+		assert.True(t, ok)
+		assert.Equal(t, "a", v)
+	})
+
+	t.Run("LessThanZero", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		_, ok := c.ElementAt(-1)
+
+		assert.False(t, ok)
+	})
+
+	t.Run("OutOfBounds", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		_, ok := c.ElementAt(3)
+
+		assert.False(t, ok)
+	})
+}
+
+func TestElementAtOrError(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		v, err := c.ElementAtOrError(0)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "a", v)
+	})
+
+	t.Run("LessThanZero", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		_, err := c.ElementAtOrError(-1)
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("OutOfBounds", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+		_, err := c.ElementAtOrError(3)
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestRandom(t *testing.T) {
+	t.Run("RandomElement", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4, 5})
+		result, ok := c.Random()
+
+		assert.True(t, ok)
+		assert.Contains(t, []int{1, 2, 3, 4, 5}, result)
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{})
+		result, ok := c.Random()
+
+		assert.False(t, ok)
+		assert.Equal(t, 0, result)
+	})
+}
+
+func TestRandomN(t *testing.T) {
+	t.Run("RandomElements", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4, 5})
+		result, ok := c.RandomN(3)
+
+		assert.True(t, ok)
+		assert.Equal(t, 3, len(result))
+	})
+
+	t.Run("SingleElement", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3})
+		result, ok := c.RandomN(1)
+
+		assert.True(t, ok)
+		assert.Len(t, result, 1)
+		assert.Contains(t, []int{1, 2, 3}, result[0])
+	})
+
+	t.Run("DistinctElements", func(t *testing.T) {
+		c := collection.NewFromRange(0, 20)
+		for range 100 {
+			result, ok := c.RandomN(10)
+
+			assert.True(t, ok)
+			assert.Len(t, result, 10)
+			seen := make(map[int]bool)
+			for _, v := range result {
+				assert.False(t, seen[v])
+				seen[v] = true
+			}
+		}
+	})
+
+	t.Run("AllElementsIsPermutation", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4, 5})
+		result, ok := c.RandomN(5)
+
+		assert.True(t, ok)
+		assert.ElementsMatch(t, []int{1, 2, 3, 4, 5}, result)
+	})
+
+	t.Run("Distribution", func(t *testing.T) {
+		c := collection.NewFromRange(0, 5)
+		counts := make([]int, 5)
+		const runs = 10000
+		for range runs {
+			result, _ := c.RandomN(2)
+			for _, v := range result {
+				counts[v]++
+			}
+		}
+
+		// Each element is expected in 2/5 of the runs
+		for _, count := range counts {
+			assert.InDelta(t, runs*2/5, count, runs/20)
+		}
+	})
+
+	t.Run("NLessThan1", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2})
+		result, ok := c.RandomN(0)
+
+		assert.False(t, ok)
+		assert.Len(t, result, 0)
+	})
+
+	t.Run("MoreThanAvailable", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2})
+		result, ok := c.RandomN(5)
+
+		assert.False(t, ok)
+		assert.Len(t, result, 0)
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{})
+		result, ok := c.RandomN(3)
+
+		assert.False(t, ok)
+		assert.Len(t, result, 0)
+	})
+}
+
+func TestIndexOf(t *testing.T) {
+	t.Run("ElementFound", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{10, 20, 30, 40})
+		index := c.IndexOf(func(x int) bool {
+			return x == 30
+		})
+
+		assert.Equal(t, 2, index)
+	})
+
+	t.Run("ElementNotFound", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{10, 20, 30, 40})
+		index := c.IndexOf(func(x int) bool {
+			return x == 50
+		})
+
+		assert.Equal(t, -1, index)
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{})
+		index := c.IndexOf(func(x int) bool {
+			return x == 10
+		})
+
+		assert.Equal(t, -1, index)
+	})
+
+	t.Run("FirstElement", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{10, 20, 30, 40})
+		index := c.IndexOf(func(x int) bool {
+			return x == 10
+		})
+
+		assert.Equal(t, 0, index)
+	})
+
+	t.Run("LastElement", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{10, 20, 30, 40})
+		index := c.IndexOf(func(x int) bool {
+			return x == 40
+		})
+
+		assert.Equal(t, 3, index)
+	})
+}
+
+func TestLastIndexOf(t *testing.T) {
+	t.Run("LastMatch", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{10, 20, 10, 40})
+		index := c.LastIndexOf(func(x int) bool {
+			return x == 10
+		})
+
+		assert.Equal(t, 2, index)
+	})
+
+	t.Run("FirstElement", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{10, 20, 30})
+		index := c.LastIndexOf(func(x int) bool {
+			return x == 10
+		})
+
+		assert.Equal(t, 0, index)
+	})
+
+	t.Run("LastElement", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{10, 20, 30})
+		index := c.LastIndexOf(func(x int) bool {
+			return x == 30
+		})
+
+		assert.Equal(t, 2, index)
+	})
+
+	t.Run("ElementNotFound", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{10, 20, 30})
+		index := c.LastIndexOf(func(x int) bool {
+			return x == 50
+		})
+
+		assert.Equal(t, -1, index)
+	})
+
+	t.Run("SinglePass", func(t *testing.T) {
+		ch := make(chan int, 4)
+		for _, v := range []int{1, 2, 1, 3} {
+			ch <- v
+		}
+		close(ch)
+
+		index := collection.NewFromChannel(ch).LastIndexOf(func(x int) bool {
+			return x == 1
+		})
+
+		assert.Equal(t, 2, index)
+	})
+}
+
+func TestIndexOfValue(t *testing.T) {
+	c := collection.NewFromSlice([]string{"a", "b", "c", "b"})
+
+	assert.Equal(t, 0, collection.IndexOfValue(c, "a"))
+	assert.Equal(t, 1, collection.IndexOfValue(c, "b"))
+	assert.Equal(t, 2, collection.IndexOfValue(c, "c"))
+	assert.Equal(t, -1, collection.IndexOfValue(c, "z"))
+}
+
+func TestLastIndexOfValue(t *testing.T) {
+	c := collection.NewFromSlice([]string{"a", "b", "c", "b"})
+
+	assert.Equal(t, 0, collection.LastIndexOfValue(c, "a"))
+	assert.Equal(t, 3, collection.LastIndexOfValue(c, "b"))
+	assert.Equal(t, 2, collection.LastIndexOfValue(c, "c"))
+	assert.Equal(t, -1, collection.LastIndexOfValue(c, "z"))
+	assert.Equal(t, -1, collection.LastIndexOfValue(collection.NewFromSlice([]string{}), "a"))
+}
+
+func TestEqualsUnordered(t *testing.T) {
+	t.Run("PermutedEqual", func(t *testing.T) {
+		a := collection.NewFromSlice([]int{1, 2, 2, 3})
+		b := collection.NewFromSlice([]int{2, 3, 1, 2})
+
+		assert.True(t, collection.EqualsUnordered(a, b))
+	})
+
+	t.Run("DifferentDuplicateCounts", func(t *testing.T) {
+		a := collection.NewFromSlice([]int{1, 1, 2})
+		b := collection.NewFromSlice([]int{1, 2, 2})
+
+		assert.False(t, collection.EqualsUnordered(a, b))
+	})
+
+	t.Run("DifferentLengths", func(t *testing.T) {
+		a := collection.NewFromSlice([]int{1, 2})
+		b := collection.NewFromSlice([]int{1, 2, 2})
+
+		assert.False(t, collection.EqualsUnordered(a, b))
+		assert.False(t, collection.EqualsUnordered(b, a))
+	})
+
+	t.Run("BothEmpty", func(t *testing.T) {
+		assert.True(t, collection.EqualsUnordered(collection.Empty[int](), collection.Empty[int]()))
+	})
+}
+
+func TestEqualsUnorderedBy(t *testing.T) {
+	type tagged struct {
+		Name string
+		Tags []string
+	}
+	name := func(x tagged) string { return x.Name }
+
+	t.Run("PermutedEqual", func(t *testing.T) {
+		a := collection.NewFromSlice([]tagged{{"a", nil}, {"b", []string{"x"}}})
+		b := collection.NewFromSlice([]tagged{{"b", nil}, {"a", []string{"y"}}})
+
+		assert.True(t, collection.EqualsUnorderedBy(a, b, name))
+	})
+
+	t.Run("DifferentKeys", func(t *testing.T) {
+		a := collection.NewFromSlice([]tagged{{"a", nil}, {"a", nil}})
+		b := collection.NewFromSlice([]tagged{{"a", nil}, {"b", nil}})
+
+		assert.False(t, collection.EqualsUnorderedBy(a, b, name))
+	})
+}
+
+func TestCompare(t *testing.T) {
+	t.Run("Equal", func(t *testing.T) {
+		a := collection.NewFromSlice([]int{1, 2, 3})
+		b := collection.NewFromSlice([]int{1, 2, 3})
+
+		assert.Equal(t, 0, collection.Compare(a, b, cmp.Compare[int]))
+	})
+
+	t.Run("FirstDifferenceDecides", func(t *testing.T) {
+		a := collection.NewFromSlice([]int{1, 2, 9})
+		b := collection.NewFromSlice([]int{1, 3, 0})
+
+		assert.Equal(t, -1, collection.Compare(a, b, cmp.Compare[int]))
+		assert.Equal(t, 1, collection.Compare(b, a, cmp.Compare[int]))
+	})
+
+	t.Run("Prefix", func(t *testing.T) {
+		a := collection.NewFromSlice([]int{1, 2})
+		b := collection.NewFromSlice([]int{1, 2, 3})
+
+		assert.Equal(t, -1, collection.Compare(a, b, cmp.Compare[int]))
+		assert.Equal(t, 1, collection.Compare(b, a, cmp.Compare[int]))
+	})
+
+	t.Run("BothEmpty", func(t *testing.T) {
+		assert.Equal(t, 0, collection.Compare(collection.Empty[int](), collection.Empty[int](), cmp.Compare[int]))
+	})
+
+	t.Run("MatchesSlicesCompareFunc", func(t *testing.T) {
+		inputs := [][]string{{}, {"a"}, {"a", "b"}, {"b"}, {"a", "a", "z"}}
+		for _, x := range inputs {
+			for _, y := range inputs {
+				expected := slices.CompareFunc(x, y, strings.Compare)
+				actual := collection.Compare(collection.NewFromSlice(x), collection.NewFromSlice(y), strings.Compare)
+
+				assert.Equal(t, expected, actual, "%v vs %v", x, y)
+			}
+		}
+	})
+
+	t.Run("MismatchShortCircuits", func(t *testing.T) {
+		pulled := 0
+		a := collection.Iterate(0, func(x int) int { return x + 1 }).Peek(func(int) { pulled++ })
+		b := collection.NewFromSlice([]int{0, 1, 2, 3, 4, 99})
+
+		assert.Equal(t, -1, collection.Compare(a, b, cmp.Compare[int]))
+		assert.Equal(t, 6, pulled)
+	})
+}
+
+func TestDiff(t *testing.T) {
+	type resource struct {
+		ID      string
+		Version int
+	}
+	id := func(x resource) string { return x.ID }
+
+	t.Run("Mixed", func(t *testing.T) {
+		before := collection.NewFromSlice([]resource{{"a", 1}, {"b", 1}, {"c", 1}})
+		after := collection.NewFromSlice([]resource{{"d", 1}, {"b", 2}, {"a", 1}})
+		added, removed, unchanged := collection.Diff(before, after, id)
+
+		assert.Equal(t, []resource{{"d", 1}}, added.ToSlice())
+		assert.Equal(t, []resource{{"c", 1}}, removed.ToSlice())
+		assert.Equal(t, []resource{{"b", 2}, {"a", 1}}, unchanged.ToSlice())
+	})
+
+	t.Run("Disjoint", func(t *testing.T) {
+		before := collection.NewFromSlice([]resource{{"a", 1}, {"b", 1}})
+		after := collection.NewFromSlice([]resource{{"c", 1}})
+		added, removed, unchanged := collection.Diff(before, after, id)
+
+		assert.Equal(t, []resource{{"c", 1}}, added.ToSlice())
+		assert.Equal(t, []resource{{"a", 1}, {"b", 1}}, removed.ToSlice())
+		assert.True(t, unchanged.IsEmpty())
+	})
+
+	t.Run("Identical", func(t *testing.T) {
+		items := []resource{{"a", 1}, {"b", 1}}
+		added, removed, unchanged := collection.Diff(collection.NewFromSlice(items), collection.NewFromSlice(items), id)
+
+		assert.True(t, added.IsEmpty())
+		assert.True(t, removed.IsEmpty())
+		assert.Equal(t, items, unchanged.ToSlice())
+	})
+
+	t.Run("EmptyBefore", func(t *testing.T) {
+		after := collection.NewFromSlice([]resource{{"a", 1}})
+		added, removed, unchanged := collection.Diff(collection.Empty[resource](), after, id)
+
+		assert.Equal(t, []resource{{"a", 1}}, added.ToSlice())
+		assert.True(t, removed.IsEmpty())
+		assert.True(t, unchanged.IsEmpty())
+	})
+
+	t.Run("EmptyAfter", func(t *testing.T) {
+		before := collection.NewFromSlice([]resource{{"a", 1}})
+		added, removed, unchanged := collection.Diff(before, collection.Empty[resource](), id)
+
+		assert.True(t, added.IsEmpty())
+		assert.Equal(t, []resource{{"a", 1}}, removed.ToSlice())
+		assert.True(t, unchanged.IsEmpty())
+	})
+
+	t.Run("RepeatedKeysUseFirst", func(t *testing.T) {
+		before := collection.NewFromSlice([]resource{{"a", 1}, {"a", 2}, {"b", 1}, {"b", 2}})
+		after := collection.NewFromSlice([]resource{{"a", 3}, {"c", 1}, {"a", 4}, {"c", 2}})
+		added, removed, unchanged := collection.Diff(before, after, id)
+
+		assert.Equal(t, []resource{{"c", 1}}, added.ToSlice())
+		assert.Equal(t, []resource{{"b", 1}}, removed.ToSlice())
+		assert.Equal(t, []resource{{"a", 3}}, unchanged.ToSlice())
+	})
+}
+
+func TestDiffFunc(t *testing.T) {
+	type resource struct {
+		ID      string
+		Version int
+	}
+	id := func(x resource) string { return x.ID }
+	sameVersion := func(before, after resource) bool { return before.Version == after.Version }
+
+	t.Run("Changed", func(t *testing.T) {
+		before := collection.NewFromSlice([]resource{{"a", 1}, {"b", 1}, {"c", 1}})
+		after := collection.NewFromSlice([]resource{{"a", 1}, {"b", 2}, {"d", 1}})
+		added, removed, changed, unchanged := collection.DiffFunc(before, after, id, sameVersion)
+
+		assert.Equal(t, []resource{{"d", 1}}, added.ToSlice())
+		assert.Equal(t, []resource{{"c", 1}}, removed.ToSlice())
+		assert.Equal(t, []resource{{"b", 2}}, changed.ToSlice())
+		assert.Equal(t, []resource{{"a", 1}}, unchanged.ToSlice())
+	})
+}
+
+func TestReservoirSample(t *testing.T) {
+	t.Run("SampleSize", func(t *testing.T) {
+		result := collection.ReservoirSample(collection.NewFromRange(0, 100), 10, rand.New(rand.NewSource(1)))
+
+		assert.Len(t, result, 10)
+		seen := make(map[int]bool)
+		for _, v := range result {
+			assert.False(t, seen[v])
+			assert.True(t, v >= 0 && v < 100)
+			seen[v] = true
+		}
+	})
+
+	t.Run("KGreaterThanLength", func(t *testing.T) {
+		result := collection.ReservoirSample(collection.NewFromSlice([]int{1, 2, 3}), 5, nil)
+
+		assert.Equal(t, []int{1, 2, 3}, result)
+	})
+
+	t.Run("ZeroK", func(t *testing.T) {
+		result := collection.ReservoirSample(collection.NewFromSlice([]int{1, 2, 3}), 0, nil)
+
+		assert.Empty(t, result)
+	})
+
+	t.Run("Deterministic", func(t *testing.T) {
+		c := collection.NewFromRange(0, 1000)
+		first := collection.ReservoirSample(c, 5, rand.New(rand.NewSource(42)))
+		second := collection.ReservoirSample(c, 5, rand.New(rand.NewSource(42)))
+
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("Uniformity", func(t *testing.T) {
+		r := rand.New(rand.NewSource(7))
+		c := collection.NewFromRange(0, 10)
+		counts := make([]int, 10)
+		const runs = 10000
+		for range runs {
+			for _, v := range collection.ReservoirSample(c, 3, r) {
+				counts[v]++
+			}
+		}
+
+		// Each element is expected in 3/10 of the runs
+		for _, count := range counts {
+			assert.InDelta(t, runs*3/10, count, runs/20)
+		}
+	})
+}
+
+func TestPartition(t *testing.T) {
+	t.Run("Partitions", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+
+		even, odd := c.Partition(func(x int) bool {
+			return x%2 == 0
+		})
+
+		assert.Equal(t, 5, len(even.ToSlice()))
+		assert.Equal(t, 5, len(odd.ToSlice()))
+
+		assert.Contains(t, even.ToSlice(), 2)
+		assert.Contains(t, even.ToSlice(), 4)
+		assert.Contains(t, even.ToSlice(), 6)
+		assert.Contains(t, even.ToSlice(), 8)
+		assert.Contains(t, even.ToSlice(), 10)
+
+		assert.Contains(t, odd.ToSlice(), 1)
+		assert.Contains(t, odd.ToSlice(), 3)
+		assert.Contains(t, odd.ToSlice(), 5)
+		assert.Contains(t, odd.ToSlice(), 7)
+		assert.Contains(t, odd.ToSlice(), 9)
+	})
+
+	t.Run("BreakEven", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+
+		even, _ := c.Partition(func(x int) bool {
+			return x%2 == 0
+		})
+
+		for range *even {
+			break
+		}
+	})
+
+	t.Run("BreakOdd", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+
+		_, odd := c.Partition(func(x int) bool {
+			return x%2 != 0
+		})
+
+		for range *odd {
+			break
+		}
+	})
+}
+
+func TestJoin(t *testing.T) {
+	type testPerson struct {
+		ID   int
+		Name string
+		Age  int
+	}
+
+	type testPet struct {
+		OwnerID int
+		Name    string
+		Species string
+	}
+
+	t.Run("Joins", func(t *testing.T) {
+
+		// Setup
+		people := collection.NewFromSlice([]testPerson{
+			{1, "Alice", 30},
+			{2, "Bob", 25},
+			{3, "Charlie", 35},
+		})
+
+		pets := collection.NewFromSlice([]testPet{
+			{1, "Fluffy", "Cat"},
+			{2, "Rex", "Dog"},
+			{1, "Whiskers", "Cat"},
+			{4, "Spike", "Dog"},
+		})
+
+		// Perform join
+		result := collection.Join(
+			people,
+			pets,
+			func(p testPerson) int { return p.ID },
+			func(pet testPet) int { return pet.OwnerID },
+			func(p testPerson, pet testPet) string {
+				return fmt.Sprintf("%s owns %s", p.Name, pet.Name)
+			},
+		).ToSlice()
+
+		// Verify results
+		expected := []string{
+			"Alice owns Fluffy",
+			"Alice owns Whiskers",
+			"Bob owns Rex",
+		}
+
+		if len(result) != len(expected) {
+			t.Errorf("Expected %d results, got %d", len(expected), len(result))
+		}
+
+		// Check that all expected results are present
+		for _, exp := range expected {
+			found := false
+			for _, res := range result {
+				if res == exp {
+					found = true
+					break
+				}
+			}
+			if !found {
+				t.Errorf("Expected result not found: %s", exp)
+			}
+		}
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		people := collection.NewFromSlice([]testPerson{
+			{1, "Alice", 30},
+			{2, "Bob", 25},
+			{3, "Charlie", 35},
+		})
+
+		pets := collection.NewFromSlice([]testPet{
+			{1, "Fluffy", "Cat"},
+			{2, "Rex", "Dog"},
+			{1, "Whiskers", "Cat"},
+			{4, "Spike", "Dog"},
+		})
+
+		for range *collection.Join(
+			people,
+			pets,
+			func(p testPerson) int { return p.ID },
+			func(pet testPet) int { return pet.OwnerID },
+			func(p testPerson, pet testPet) string {
+				return fmt.Sprintf("%s owns %s", p.Name, pet.Name)
+			},
+		) {
+			break
+		}
+	})
+}
+
+func TestLeftJoin(t *testing.T) {
+	type testPerson struct {
+		ID   int
+		Name string
+	}
+
+	type testPet struct {
+		OwnerID int
+		Name    string
+	}
+
+	people := collection.NewFromSlice([]testPerson{
+		{1, "Alice"},
+		{2, "Bob"},
+		{3, "Charlie"},
+	})
+
+	describe := func(p testPerson, pet testPet, matched bool) string {
+		if !matched {
+			return fmt.Sprintf("%s has no pets", p.Name)
+		}
+		return fmt.Sprintf("%s owns %s", p.Name, pet.Name)
+	}
+
+	t.Run("Joins", func(t *testing.T) {
+		pets := collection.NewFromSlice([]testPet{
+			{1, "Fluffy"},
+			{2, "Rex"},
+			{1, "Whiskers"},
+			{4, "Spike"},
+		})
+
+		result := collection.LeftJoin(
+			people,
+			pets,
+			func(p testPerson) int { return p.ID },
+			func(pet testPet) int { return pet.OwnerID },
+			describe,
+		).ToSlice()
+
+		assert.Equal(t, []string{
+			"Alice owns Fluffy",
+			"Alice owns Whiskers",
+			"Bob owns Rex",
+			"Charlie has no pets",
+		}, result)
+	})
+
+	t.Run("EmptyInner", func(t *testing.T) {
+		pets := collection.NewFromSlice([]testPet{})
+
+		result := collection.LeftJoin(
+			people,
+			pets,
+			func(p testPerson) int { return p.ID },
+			func(pet testPet) int { return pet.OwnerID },
+			describe,
+		).ToSlice()
+
+		assert.Equal(t, []string{
+			"Alice has no pets",
+			"Bob has no pets",
+			"Charlie has no pets",
+		}, result)
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		pets := collection.NewFromSlice([]testPet{
+			{1, "Fluffy"},
+		})
+
+		for range *collection.LeftJoin(
+			people,
+			pets,
+			func(p testPerson) int { return p.ID },
+			func(pet testPet) int { return pet.OwnerID },
+			describe,
+		) {
+			break
+		}
+	})
+}
+
+func TestZipByKeyStrict(t *testing.T) {
+	type order struct {
+		ID    int
+		Total int
+	}
+
+	type invoice struct {
+		OrderID int
+		Number  string
+	}
+
+	orderKey := func(o order) int { return o.ID }
+	invoiceKey := func(i invoice) int { return i.OrderID }
+	describe := func(o order, i invoice) string {
+		return fmt.Sprintf("%s:%d", i.Number, o.Total)
+	}
+
+	t.Run("PerfectMatch", func(t *testing.T) {
+		orders := collection.NewFromSlice([]order{{1, 10}, {2, 20}, {3, 30}})
+		invoices := collection.NewFromSlice([]invoice{{3, "INV3"}, {1, "INV1"}, {2, "INV2"}})
+
+		result, err := collection.ZipByKeyStrict(orders, invoices, orderKey, invoiceKey, describe)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"INV1:10", "INV2:20", "INV3:30"}, result.ToSlice())
+	})
+
+	t.Run("ExtraInFirst", func(t *testing.T) {
+		orders := collection.NewFromSlice([]order{{1, 10}, {2, 20}})
+		invoices := collection.NewFromSlice([]invoice{{1, "INV1"}})
+
+		_, err := collection.ZipByKeyStrict(orders, invoices, orderKey, invoiceKey, describe)
+
+		assert.ErrorIs(t, err, collection.ErrKeyMismatch)
+		assert.Contains(t, err.Error(), "keys only in first collection [2]")
+		assert.Contains(t, err.Error(), "keys only in second collection []")
+	})
+
+	t.Run("ExtraInSecond", func(t *testing.T) {
+		orders := collection.NewFromSlice([]order{{1, 10}})
+		invoices := collection.NewFromSlice([]invoice{{1, "INV1"}, {5, "INV5"}})
+
+		_, err := collection.ZipByKeyStrict(orders, invoices, orderKey, invoiceKey, describe)
+
+		assert.ErrorIs(t, err, collection.ErrKeyMismatch)
+		assert.Contains(t, err.Error(), "keys only in first collection []")
+		assert.Contains(t, err.Error(), "keys only in second collection [5]")
+	})
+
+	t.Run("ReportedKeysCapped", func(t *testing.T) {
+		orders := collection.NewFromRange(0, 15)
+		invoices := collection.NewFromSlice([]invoice{})
+
+		_, err := collection.ZipByKeyStrict(orders, invoices, func(x int) int { return x }, invoiceKey, func(o int, i invoice) int { return o })
+
+		assert.ErrorIs(t, err, collection.ErrKeyMismatch)
+		assert.Contains(t, err.Error(), "[0 1 2 3 4 5 6 7 8 9 ... and 5 more]")
+	})
+
+	t.Run("DuplicateKey", func(t *testing.T) {
+		orders := collection.NewFromSlice([]order{{1, 10}, {1, 11}})
+		invoices := collection.NewFromSlice([]invoice{{1, "INV1"}})
+
+		_, err := collection.ZipByKeyStrict(orders, invoices, orderKey, invoiceKey, describe)
+
+		assert.ErrorIs(t, err, collection.ErrDuplicateKey)
+
+		orders = collection.NewFromSlice([]order{{1, 10}})
+		invoices = collection.NewFromSlice([]invoice{{1, "INV1"}, {1, "INV1b"}})
+
+		_, err = collection.ZipByKeyStrict(orders, invoices, orderKey, invoiceKey, describe)
+
+		assert.ErrorIs(t, err, collection.ErrDuplicateKey)
+	})
+
+	t.Run("EmptyCollections", func(t *testing.T) {
+		orders := collection.NewFromSlice([]order{})
+		invoices := collection.NewFromSlice([]invoice{})
+
+		result, err := collection.ZipByKeyStrict(orders, invoices, orderKey, invoiceKey, describe)
+
+		assert.Nil(t, err)
+		assert.True(t, result.IsEmpty())
+	})
+}
+
+func TestWindowed(t *testing.T) {
+	windows := func(c *collection.Collection[*collection.Collection[int]]) [][]int {
+		var result [][]int
+		for w := range *c {
+			result = append(result, w.ToSlice())
+		}
+		return result
+	}
+
+	t.Run("Windows", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4})
+
+		assert.Equal(t, [][]int{{1, 2}, {2, 3}, {3, 4}}, windows(collection.Windowed(c, 2)))
+	})
+
+	t.Run("SizeOne", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3})
+
+		assert.Equal(t, [][]int{{1}, {2}, {3}}, windows(collection.Windowed(c, 1)))
+	})
+
+	t.Run("SizeEqualToLength", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3})
+
+		assert.Equal(t, [][]int{{1, 2, 3}}, windows(collection.Windowed(c, 3)))
+	})
+
+	t.Run("SizeGreaterThanLength", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3})
+
+		assert.Empty(t, windows(collection.Windowed(c, 4)))
+	})
+
+	t.Run("WindowsRetainable", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4, 5})
+
+		retained := collection.Windowed(c, 3).ToSlice()
+
+		assert.Equal(t, []int{1, 2, 3}, retained[0].ToSlice())
+		assert.Equal(t, []int{3, 4, 5}, retained[2].ToSlice())
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4})
+
+		for range *collection.Windowed(c, 2) {
+			break
+		}
+	})
+
+	t.Run("InvalidSize", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1})
+
+		assert.Panics(t, func() { collection.Windowed(c, 0) })
+	})
+}
+
+func TestPermutations(t *testing.T) {
+	t.Run("AllOrderings", func(t *testing.T) {
+		result := collection.Permutations(collection.NewFromSlice([]int{1, 2, 3})).ToSlice()
+
+		assert.ElementsMatch(t, [][]int{
+			{1, 2, 3}, {1, 3, 2}, {2, 1, 3}, {2, 3, 1}, {3, 1, 2}, {3, 2, 1},
+		}, result)
+	})
+
+	t.Run("FourElements", func(t *testing.T) {
+		result := collection.Permutations(collection.NewFromRange(0, 4)).ToSlice()
+
+		assert.Len(t, result, 24)
+		seen := make(map[string]bool)
+		for _, p := range result {
+			seen[fmt.Sprint(p)] = true
+		}
+		assert.Len(t, seen, 24)
+	})
+
+	t.Run("SlicesAreIndependent", func(t *testing.T) {
+		result := collection.Permutations(collection.NewFromSlice([]int{1, 2})).ToSlice()
+		result[0][0] = 99
+
+		assert.Equal(t, []int{2, 1}, result[1])
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		result := collection.Permutations(collection.NewFromSlice([]int{})).ToSlice()
+
+		assert.Equal(t, [][]int{{}}, result)
+	})
+
+	t.Run("SingleElement", func(t *testing.T) {
+		result := collection.Permutations(collection.NewFromSlice([]int{1})).ToSlice()
+
+		assert.Equal(t, [][]int{{1}}, result)
+	})
+
+	t.Run("EarlyBreak", func(t *testing.T) {
+		result := collection.Permutations(collection.NewFromRange(0, 20)).Take(3).ToSlice()
+
+		assert.Len(t, result, 3)
+		assert.Equal(t, collection.NewFromRange(0, 20).ToSlice(), result[0])
+	})
+}
+
+func TestCombinations(t *testing.T) {
+	t.Run("Pairs", func(t *testing.T) {
+		result := collection.Combinations(collection.NewFromSlice([]string{"a", "b", "c", "d"}), 2).ToSlice()
+
+		assert.Equal(t, [][]string{
+			{"a", "b"}, {"a", "c"}, {"a", "d"}, {"b", "c"}, {"b", "d"}, {"c", "d"},
+		}, result)
+	})
+
+	t.Run("AllElements", func(t *testing.T) {
+		result := collection.Combinations(collection.NewFromSlice([]int{1, 2, 3}), 3).ToSlice()
+
+		assert.Equal(t, [][]int{{1, 2, 3}}, result)
+	})
+
+	t.Run("ZeroK", func(t *testing.T) {
+		result := collection.Combinations(collection.NewFromSlice([]int{1, 2, 3}), 0).ToSlice()
+
+		assert.Equal(t, [][]int{{}}, result)
+	})
+
+	t.Run("KGreaterThanLength", func(t *testing.T) {
+		result := collection.Combinations(collection.NewFromSlice([]int{1, 2}), 3)
+
+		assert.True(t, result.IsEmpty())
+	})
+
+	t.Run("NegativeK", func(t *testing.T) {
+		result := collection.Combinations(collection.NewFromSlice([]int{1, 2}), -1)
+
+		assert.True(t, result.IsEmpty())
+	})
+
+	t.Run("EarlyBreak", func(t *testing.T) {
+		result := collection.Combinations(collection.NewFromRange(0, 30), 15).Take(2).ToSlice()
+
+		assert.Len(t, result, 2)
+		assert.Equal(t, collection.NewFromRange(0, 15).ToSlice(), result[0])
+		assert.Equal(t, append(collection.NewFromRange(0, 14).ToSlice(), 15), result[1])
+	})
+}
+
+func TestSplitWhen(t *testing.T) {
+	isZero := func(v int) bool { return v == 0 }
+	segments := func(c *collection.Collection[*collection.Collection[int]]) [][]int {
+		var result [][]int
+		for segment := range *c {
+			result = append(result, segment.ToSlice())
+		}
+		return result
+	}
+
+	t.Run("Logic", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 0, 3, 0, 4, 5})
+
+		assert.Equal(t, [][]int{{1, 2}, {3}, {4, 5}}, segments(collection.SplitWhen(c, isZero)))
+	})
+
+	t.Run("ConsecutiveDelimiters", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 0, 0, 2})
+
+		assert.Equal(t, [][]int{{1}, nil, {2}}, segments(collection.SplitWhen(c, isZero)))
+	})
+
+	t.Run("LeadingAndTrailingDelimiters", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{0, 1, 0})
+
+		assert.Equal(t, [][]int{nil, {1}, nil}, segments(collection.SplitWhen(c, isZero)))
+	})
+
+	t.Run("OnlyDelimiter", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{0})
+
+		assert.Equal(t, [][]int{nil, nil}, segments(collection.SplitWhen(c, isZero)))
+	})
+
+	t.Run("NoDelimiter", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2})
+
+		assert.Equal(t, [][]int{{1, 2}}, segments(collection.SplitWhen(c, isZero)))
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{})
+
+		assert.True(t, collection.SplitWhen(c, isZero).IsEmpty())
+	})
+
+	t.Run("ChannelBreak", func(t *testing.T) {
+		ch := make(chan int, 6)
+		for _, v := range []int{1, 0, 2, 0, 3, 4} {
+			ch <- v
+		}
+		close(ch)
+
+		for segment := range *collection.SplitWhen(collection.NewFromChannel(ch), isZero) {
+			assert.Equal(t, []int{1}, segment.ToSlice())
+			break
+		}
+
+		assert.Equal(t, 4, len(ch))
+	})
+}
+
+func TestTee(t *testing.T) {
+	newChannel := func(n int) <-chan int {
+		ch := make(chan int, n)
+		for i := range n {
+			ch <- i
+		}
+		close(ch)
+		return ch
+	}
+
+	t.Run("BufferedReadDoesNotWaitOnSource", func(t *testing.T) {
+		waiting := make(chan struct{})
+		resume := make(chan struct{})
+		a, b := collection.Tee(collection.NewFromIterator(iter.Seq[int](func(yield func(int) bool) {
+			if !yield(0) || !yield(1) {
+				return
+			}
+			close(waiting)
+			<-resume
+			yield(2)
+		})))
+
+		readA := make(chan []int)
+		go func() { readA <- a.ToSlice() }()
+		<-waiting
+
+		// a is blocked pulling the third element, with the first two buffered for b
+		readB := make(chan []int)
+		go func() { readB <- b.Take(2).ToSlice() }()
+		select {
+		case v := <-readB:
+			assert.Equal(t, []int{0, 1}, v)
+		case <-time.After(5 * time.Second):
+			t.Fatal("buffered elements waited on the source")
+		}
+
+		close(resume)
+		assert.Equal(t, []int{0, 1, 2}, <-readA)
+	})
+
+	t.Run("BothFinishedReleasesSource", func(t *testing.T) {
+		c, released := newReleaseTrackingSource()
+		a, b := collection.Tee(c)
+
+		assert.Equal(t, []int{0, 1, 2}, a.Take(3).ToSlice())
+		assert.False(t, released())
+
+		assert.Equal(t, []int{0}, b.Take(1).ToSlice())
+		assert.True(t, released())
+	})
+
+	t.Run("StopReleasesAbandonedOutput", func(t *testing.T) {
+		c, released := newReleaseTrackingSource()
+		a, b, stop := collection.TeeStop(c)
+
+		assert.Equal(t, []int{0, 1, 2}, a.Take(3).ToSlice())
+		assert.False(t, released())
+
+		stop()
+		assert.True(t, released())
+		assert.Equal(t, []int{0, 1, 2}, b.ToSlice())
+	})
+
+	t.Run("Lockstep", func(t *testing.T) {
+		a, b := collection.Tee(collection.NewFromChannel(newChannel(5)))
+
+		nextA, stopA := iter.Pull(iter.Seq[int](*a))
+		defer stopA()
+		nextB, stopB := iter.Pull(iter.Seq[int](*b))
+		defer stopB()
+
+		for i := range 5 {
+			va, okA := nextA()
+			vb, okB := nextB()
+
+			assert.True(t, okA)
+			assert.True(t, okB)
+			assert.Equal(t, i, va)
+			assert.Equal(t, i, vb)
+		}
+
+		_, okA := nextA()
+		_, okB := nextB()
+
+		assert.False(t, okA)
+		assert.False(t, okB)
+	})
+
+	t.Run("OneAhead", func(t *testing.T) {
+		a, b := collection.Tee(collection.NewFromChannel(newChannel(5)))
+
+		assert.Equal(t, 5, a.Count())
+		assert.Equal(t, []int{0, 1, 2, 3, 4}, b.ToSlice())
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		a, b := collection.Tee(collection.NewFromChannel(newChannel(1000)))
+
+		var count int
+		var values []int
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			count = a.Count()
+		}()
+		go func() {
+			defer wg.Done()
+			values = b.ToSlice()
+		}()
+		wg.Wait()
+
+		assert.Equal(t, 1000, count)
+		assert.Equal(t, collection.NewFromRange(0, 1000).ToSlice(), values)
+	})
+
+	t.Run("OneAbandoned", func(t *testing.T) {
+		a, b := collection.Tee(collection.NewFromChannel(newChannel(5)))
+
+		assert.Equal(t, []int{0, 1}, a.Take(2).ToSlice())
+		assert.Equal(t, []int{0, 1, 2, 3, 4}, b.ToSlice())
+		assert.True(t, a.IsEmpty())
+	})
+
+	t.Run("BothAbandoned", func(t *testing.T) {
+		ch := newChannel(5)
+		a, b := collection.Tee(collection.NewFromChannel(ch))
+
+		assert.Equal(t, []int{0}, a.Take(1).ToSlice())
+		assert.Equal(t, []int{0, 1}, b.Take(2).ToSlice())
+		assert.Equal(t, 3, len(ch))
+	})
+}
+
+func TestMergeConcurrent(t *testing.T) {
+	t.Run("AllElements", func(t *testing.T) {
+		result := collection.MergeConcurrent(context.Background(),
+			collection.NewFromRange(0, 100),
+			collection.NewFromRange(100, 100),
+			collection.NewFromRange(200, 100),
+		).ToSlice()
+
+		assert.ElementsMatch(t, collection.NewFromRange(0, 300).ToSlice(), result)
+	})
+
+	t.Run("EmptySource", func(t *testing.T) {
+		result := collection.MergeConcurrent(context.Background(),
+			collection.NewFromSlice([]int{1, 2}),
+			collection.NewFromSlice([]int{}),
+			collection.NewFromSlice([]int{3}),
+		).ToSlice()
+
+		assert.ElementsMatch(t, []int{1, 2, 3}, result)
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var pulled atomic.Int64
+		source := func() *collection.Collection[int] {
+			return collection.NewFromRange(0, 1000).Peek(func(int) { pulled.Add(1) })
+		}
+
+		var result []int
+		for v := range *collection.MergeConcurrent(ctx, source(), source()) {
+			result = append(result, v)
+			if len(result) == 5 {
+				cancel()
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		count := pulled.Load()
+		time.Sleep(10 * time.Millisecond)
+
+		assert.Len(t, result, 5)
+		assert.Equal(t, count, pulled.Load())
+		assert.Less(t, count, int64(2000))
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		var pulled atomic.Int64
+		source := collection.NewFromRange(0, 1000).Peek(func(int) { pulled.Add(1) })
+
+		for range *collection.MergeConcurrent(context.Background(), source) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		assert.Less(t, pulled.Load(), int64(1000))
+	})
+
+	t.Run("BlockedSourceLeaksUntilUnblocked", func(t *testing.T) {
+		before := runtime.NumGoroutine()
+		blocked := make(chan int)
+
+		for range *collection.MergeConcurrent(context.Background(), collection.NewFromItems(1), collection.NewFromChannel(blocked)) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		assert.Greater(t, runtime.NumGoroutine(), before)
+
+		close(blocked)
+		for range 100 {
+			if runtime.NumGoroutine() <= before {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+	})
+}
+
+func TestParallelMap(t *testing.T) {
+	t.Run("Aligned", func(t *testing.T) {
+		c := collection.NewFromRange(0, 100)
+
+		results, err := collection.ParallelMap(context.Background(), c, func(ctx context.Context, x int) (string, error) {
+			time.Sleep(time.Duration(rand.Intn(3)) * time.Millisecond)
+			return strconv.Itoa(x), nil
+		}, 8)
+
+		assert.Nil(t, err)
+		assert.Equal(t, collection.Select(c, strconv.Itoa).ToSlice(), results)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"1", "2", "x", "4"})
+
+		results, err := collection.ParallelMap(context.Background(), c, func(ctx context.Context, x string) (int, error) {
+			return strconv.Atoi(x)
+		}, 1)
+
+		assert.ErrorIs(t, err, strconv.ErrSyntax)
+		assert.ErrorContains(t, err, "element 2")
+		assert.Equal(t, []int{1, 2}, results[:2])
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		results, err := collection.ParallelMap(context.Background(), collection.NewFromSlice([]int{}), func(ctx context.Context, x int) (int, error) {
+			return x, nil
+		}, 2)
+
+		assert.Nil(t, err)
+		assert.NotNil(t, results)
+		assert.Empty(t, results)
+	})
+}
+
+func TestParallelAggregate(t *testing.T) {
+	newAcc := func() int { return 0 }
+	sum := func(acc int, v int) int { return acc + v }
+
+	t.Run("MatchesFold", func(t *testing.T) {
+		c := collection.NewFromRange(0, 10000)
+
+		result, err := collection.ParallelAggregate(context.Background(), c, newAcc, sum, sum, 4)
+
+		assert.Nil(t, err)
+		assert.Equal(t, collection.Fold(c, 0, sum), result)
+	})
+
+	t.Run("MergePerWorker", func(t *testing.T) {
+		merges := 0
+		_, err := collection.ParallelAggregate(context.Background(), collection.NewFromRange(0, 100), newAcc, sum, func(a, b int) int {
+			merges++
+			return a + b
+		}, 3)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 3, merges)
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		c := collection.NewFromRange(0, 1000).Peek(func(x int) {
+			if x == 10 {
+				cancel()
+			}
+		})
+
+		_, err := collection.ParallelAggregate(ctx, c, newAcc, sum, sum, 2)
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("CancelAfterSourceEnds", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		c := collection.NewFromIterator(iter.Seq[int](func(yield func(int) bool) {
+			defer cancel()
+			for i := range 100 {
+				if !yield(i) {
+					return
+				}
+			}
+		}))
+
+		result, err := collection.ParallelAggregate(ctx, c, newAcc, sum, sum, 2)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 4950, result)
+	})
+
+	t.Run("CancelDuringMerge", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		result, err := collection.ParallelAggregate(ctx, collection.NewFromRange(0, 100), newAcc, sum, func(a, b int) int {
+			cancel()
+			return a + b
+		}, 2)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 4950, result)
+	})
+}
+
+func TestBufferByTime(t *testing.T) {
+	t.Run("Windows", func(t *testing.T) {
+		ch := make(chan int)
+		go func() {
+			defer close(ch)
+			ch <- 1
+			ch <- 2
+			time.Sleep(75 * time.Millisecond)
+			ch <- 3
+			time.Sleep(75 * time.Millisecond)
+			ch <- 4
+		}()
+
+		var batches [][]int
+		for batch := range *collection.BufferByTime(context.Background(), collection.NewFromChannel(ch), 50*time.Millisecond) {
+			batches = append(batches, batch)
+		}
+
+		var flattened []int
+		for _, batch := range batches {
+			flattened = append(flattened, batch...)
+		}
+		assert.Equal(t, []int{1, 2, 3, 4}, flattened)
+		assert.Equal(t, []int{1, 2}, batches[0])
+		assert.Equal(t, []int{4}, batches[len(batches)-1])
+		assert.GreaterOrEqual(t, len(batches), 3)
+	})
+
+	t.Run("EmptyWindows", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ch := make(chan int)
+
+		var batches [][]int
+		for batch := range *collection.BufferByTime(ctx, collection.NewFromChannel(ch), 5*time.Millisecond) {
+			batches = append(batches, batch)
+			if len(batches) == 3 {
+				cancel()
+			}
+		}
+
+		assert.Equal(t, [][]int{{}, {}, {}}, batches)
+		close(ch)
+	})
+
+	t.Run("NoLeakOnBreak", func(t *testing.T) {
+		before := runtime.NumGoroutine()
+		c := collection.NewFromRange(0, 1000000)
+
+		for range *collection.BufferByTime(context.Background(), c, time.Millisecond) {
+			break
+		}
+
+		for range 100 {
+			if runtime.NumGoroutine() <= before {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+	})
+}
+
+func TestBatch(t *testing.T) {
+	t.Run("SizeTriggered", func(t *testing.T) {
+		var batches [][]int
+		for batch := range *collection.Batch(context.Background(), collection.NewFromRange(0, 7), 3, time.Minute) {
+			batches = append(batches, batch)
+		}
+
+		assert.Equal(t, [][]int{{0, 1, 2}, {3, 4, 5}, {6}}, batches)
+	})
+
+	t.Run("TimeTriggered", func(t *testing.T) {
+		ch := make(chan int)
+		go func() {
+			defer close(ch)
+			ch <- 1
+			ch <- 2
+			time.Sleep(100 * time.Millisecond)
+			ch <- 3
+		}()
+
+		var batches [][]int
+		for batch := range *collection.Batch(context.Background(), collection.NewFromChannel(ch), 10, 20*time.Millisecond) {
+			batches = append(batches, batch)
+		}
+
+		assert.Equal(t, [][]int{{1, 2}, {3}}, batches)
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := make(chan int)
+		go func() {
+			ch <- 1
+			cancel()
+		}()
+
+		for range *collection.Batch(ctx, collection.NewFromChannel(ch), 10, time.Minute) {
+			assert.Fail(t, "This should not be called")
+		}
+		close(ch)
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		before := runtime.NumGoroutine()
+
+		for range *collection.Batch(context.Background(), collection.NewFromRange(0, 1000000), 2, time.Minute) {
+			break
+		}
+
+		for range 100 {
+			if runtime.NumGoroutine() <= before {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+	})
+
+	t.Run("InvalidSize", func(t *testing.T) {
+		assert.Panics(t, func() { collection.Batch(context.Background(), collection.NewFromSlice([]int{1}), 0, time.Second) })
+	})
+}
+
+func TestPairwise(t *testing.T) {
+	t.Run("Pairs", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 4, 7, 11})
+
+		result := collection.Pairwise(c).ToSlice()
+
+		assert.Equal(t, []collection.Pair[int, int]{
+			{First: 1, Second: 2},
+			{First: 2, Second: 4},
+			{First: 4, Second: 7},
+			{First: 7, Second: 11},
+		}, result)
+	})
+
+	t.Run("SingleElement", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1})
+
+		assert.True(t, collection.Pairwise(c).IsEmpty())
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{})
+
+		assert.True(t, collection.Pairwise(c).IsEmpty())
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3})
+
+		for range *collection.Pairwise(c) {
+			break
+		}
+	})
+}
+
+func TestPairwiseWith(t *testing.T) {
+	t.Run("Deltas", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 4, 7, 11})
+
+		result := collection.PairwiseWith(c, func(prev, cur int) int { return cur - prev }).ToSlice()
+
+		assert.Equal(t, []int{1, 2, 3, 4}, result)
+	})
+
+	t.Run("Sortedness", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 3, 2})
+
+		sorted := collection.PairwiseWith(c, func(prev, cur int) bool { return prev <= cur }).All(func(x bool) bool { return x })
+
+		assert.False(t, sorted)
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3})
+
+		for range *collection.PairwiseWith(c, func(prev, cur int) int { return cur - prev }) {
+			break
+		}
+	})
+}
+
+func TestSkipNil(t *testing.T) {
+	one, two := 1, 2
+
+	t.Run("Mixed", func(t *testing.T) {
+		c := collection.NewFromSlice([]*int{nil, &one, nil, &two})
+
+		assert.Equal(t, []*int{&one, &two}, collection.SkipNil(c).ToSlice())
+		assert.Equal(t, 2, collection.SkipNil(c).Count())
+	})
+
+	t.Run("AllNil", func(t *testing.T) {
+		c := collection.NewFromSlice([]*int{nil, nil})
+
+		assert.True(t, collection.SkipNil(c).IsEmpty())
+	})
+}
+
+func TestDeref(t *testing.T) {
+	one, two := 1, 2
+
+	t.Run("Mixed", func(t *testing.T) {
+		c := collection.NewFromSlice([]*int{&one, nil, &two, nil})
+
+		assert.Equal(t, []int{1, 2}, collection.Deref(c).ToSlice())
+		assert.Equal(t, 2, collection.Deref(c).Count())
+	})
+
+	t.Run("AllNil", func(t *testing.T) {
+		c := collection.NewFromSlice([]*int{nil, nil})
+
+		assert.True(t, collection.Deref(c).IsEmpty())
+	})
+
+	t.Run("Lazy", func(t *testing.T) {
+		calls := 0
+		c := collection.NewFromSlice([]*int{nil, &one, &two}).Peek(func(*int) { calls++ })
+		result := collection.Deref(c)
+
+		assert.Equal(t, 0, calls)
+		assert.Equal(t, []int{1}, result.Take(1).ToSlice())
+		assert.Equal(t, 2, calls)
+	})
+}
+
+func TestCompact(t *testing.T) {
+	t.Run("Strings", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"", "a", "", "", "b", ""})
+
+		assert.Equal(t, []string{"a", "b"}, collection.Compact(c).ToSlice())
+	})
+
+	t.Run("Ints", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{0, 1, 0, 2, 3, 0})
+
+		assert.Equal(t, []int{1, 2, 3}, collection.Compact(c).ToSlice())
+	})
+
+	t.Run("Interfaces", func(t *testing.T) {
+		c := collection.NewFromSlice([]any{nil, 1, "a", nil})
+
+		assert.Equal(t, []any{1, "a"}, collection.Compact(c).ToSlice())
+	})
+
+	t.Run("AllZero", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{0, 0, 0})
+
+		assert.True(t, collection.Compact(c).IsEmpty())
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		calls := 0
+		c := collection.NewFromSlice([]int{0, 1, 2, 3}).Peek(func(int) { calls++ })
+
+		for range *collection.Compact(c) {
+			break
+		}
+
+		assert.Equal(t, 2, calls)
+	})
+}
+
+func TestCompactBy(t *testing.T) {
+	c := collection.NewFromSlice([][]int{{1}, nil, {}, {2, 3}})
+
+	result := collection.CompactBy(c, func(x []int) bool { return len(x) == 0 })
+
+	assert.Equal(t, [][]int{{1}, {2, 3}}, result.ToSlice())
+}
+
+func TestDedupConsecutiveBy(t *testing.T) {
+	type reading struct {
+		Sensor string
+		Value  int
+	}
+
+	t.Run("RunsCollapsed", func(t *testing.T) {
+		c := collection.NewFromSlice([]reading{{"a", 1}, {"a", 2}, {"b", 3}, {"b", 4}, {"a", 5}})
+		result := collection.DedupConsecutiveBy(c, func(x reading) string { return x.Sensor }).ToSlice()
+
+		assert.Equal(t, []reading{{"a", 1}, {"b", 3}, {"a", 5}}, result)
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		result := collection.DedupConsecutiveBy(collection.NewFromSlice([]reading{}), func(x reading) string { return x.Sensor })
+
+		assert.True(t, result.IsEmpty())
+	})
+
+	t.Run("EarlyBreak", func(t *testing.T) {
+		c := collection.Iterate(0, func(x int) int { return x + 1 })
+		result := collection.DedupConsecutiveBy(c, func(x int) int { return x / 3 }).Take(3).ToSlice()
+
+		assert.Equal(t, []int{0, 3, 6}, result)
+	})
+}
+
+func TestCastOrError(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b"}).Select(func(x string) any { return x })
+		result, err := collection.CastOrError[string](c)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a", "b"}, result.ToSlice())
+	})
+
+	t.Run("MismatchFirst", func(t *testing.T) {
+		c := collection.NewFromSlice([]any{1, "a"})
+		result, err := collection.CastOrError[string](c)
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, collection.ErrInvalidCast)
+		assert.ErrorContains(t, err, "element 0 is int")
+	})
+
+	t.Run("MismatchMidStream", func(t *testing.T) {
+		c := collection.NewFromSlice([]any{"a", "b", 2.5, "c"})
+		_, err := collection.CastOrError[string](c)
+
+		assert.ErrorIs(t, err, collection.ErrInvalidCast)
+		assert.ErrorContains(t, err, "element 2 is float64")
+	})
+
+	t.Run("InterfaceTarget", func(t *testing.T) {
+		c := collection.NewFromSlice([]any{time.Second, 1})
+		_, err := collection.CastOrError[fmt.Stringer](c)
+
+		assert.ErrorIs(t, err, collection.ErrInvalidCast)
+		assert.ErrorContains(t, err, "element 1 is int, not fmt.Stringer")
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		result, err := collection.CastOrError[string](collection.NewFromSlice([]any{}))
+
+		assert.Nil(t, err)
+		assert.True(t, result.IsEmpty())
+	})
+}
+
+func TestFlatten(t *testing.T) {
+	t.Run("FlattenNonEmpty", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]int{1, 2, 3})
+		c2 := collection.NewFromSlice([]int{4, 5, 6})
+		c3 := collection.NewFromSlice([]int{7, 8, 9})
+
+		collections := collection.NewFromSlice([]*collection.Collection[int]{c1, c2, c3})
+		result := collection.Flatten(collections).ToSlice()
+
+		assert.Equal(t, 9, len(result))
+		assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}, result)
+	})
+
+	t.Run("FlattenEmpty", func(t *testing.T) {
+		collections := collection.NewFromSlice([]*collection.Collection[int]{})
+		result := collection.Flatten(collections).ToSlice()
+
+		assert.Equal(t, 0, len(result))
+	})
+
+	t.Run("FlattenWithEmptyCollections", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]int{})
+		c2 := collection.NewFromSlice([]int{4, 5, 6})
+		c3 := collection.NewFromSlice([]int{})
+
+		collections := collection.NewFromSlice([]*collection.Collection[int]{c1, c2, c3})
+		result := collection.Flatten(collections).ToSlice()
+
+		assert.Equal(t, 3, len(result))
+		assert.Equal(t, []int{4, 5, 6}, result)
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		c1 := collection.NewFromSlice([]int{1, 2, 3})
+		c2 := collection.NewFromSlice([]int{4, 5, 6})
+		c3 := collection.NewFromSlice([]int{7, 8, 9})
+
+		collections := collection.NewFromSlice([]*collection.Collection[int]{c1, c2, c3})
+		for range *collection.Flatten(collections) {
+			break
+		}
+	})
+}
+
+func TestFold(t *testing.T) {
+	t.Run("Logic", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"Amsterdam", "Berlin", "New York", "San Francisco"})
+
+		result := collection.Fold(c, "Paris", func(accumulator string, item string) string {
+			if len(accumulator) < len(item) {
+				return item
+			}
+
+			return accumulator
+		})
+
+		assert.Equal(t, "San Francisco", result)
+	})
+
+	t.Run("Sum", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4, 5})
+
+		result := collection.Fold(c, 0, func(accumulator int, item int) int {
+			return accumulator + item
+		})
+
+		assert.Equal(t, 15, result)
+	})
+
+	t.Run("Concatenation", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+
+		result := collection.Fold(c, "", func(accumulator string, item string) string {
+			return accumulator + item
+		})
+
+		assert.Equal(t, "abc", result)
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{})
+
+		result := collection.Fold(c, 10, func(accumulator int, item int) int {
+			assert.Fail(t, "This should not be called")
+			return accumulator
+		})
+
+		assert.Equal(t, 10, result)
+	})
+}
+
+func BenchmarkFold(b *testing.B) {
+	c := collection.NewFromRange(0, 1000000)
+
+	b.Run("Fold", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			collection.Fold(c, 0, func(accumulator int, item int) int {
+				return accumulator + item
+			})
+		}
+	})
+
+	b.Run("Aggregate", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			c.Aggregate(0, func(accumulator any, item int) any {
+				return accumulator.(int) + item
+			})
+		}
+	})
+}
+
+func TestFoldOrError(t *testing.T) {
+	errNegative := errors.New("negative")
+	sumPositive := func(acc int, item int) (int, error) {
+		if item < 0 {
+			return acc, errNegative
+		}
+		return acc + item, nil
+	}
+
+	t.Run("Logic", func(t *testing.T) {
+		result, err := collection.FoldOrError(collection.NewFromSlice([]int{1, 2, 3}), 10, sumPositive)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 16, result)
+	})
+
+	t.Run("StopsAtError", func(t *testing.T) {
+		calls := 0
+		result, err := collection.FoldOrError(collection.NewFromSlice([]int{1, 2, -3, 4}), 0, func(acc int, item int) (int, error) {
+			calls++
+			return sumPositive(acc, item)
+		})
+
+		assert.ErrorIs(t, err, errNegative)
+		assert.ErrorContains(t, err, "element 2")
+		assert.Equal(t, 3, result)
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		result, err := collection.FoldOrError(collection.NewFromSlice([]int{}), 10, sumPositive)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 10, result)
+	})
+}
+
+func TestScan(t *testing.T) {
+	t.Run("RunningSum", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4})
+
+		result := collection.Scan(c, 0, func(acc, x int) int { return acc + x }).ToSlice()
+
+		assert.Equal(t, []int{1, 3, 6, 10}, result)
+	})
+
+	t.Run("RunningConcatenation", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
+
+		result := collection.Scan(c, ">", func(acc, x string) string { return acc + x }).ToSlice()
+
+		assert.Equal(t, []string{">a", ">ab", ">abc"}, result)
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{})
+
+		assert.True(t, collection.Scan(c, 0, func(acc, x int) int { return acc + x }).IsEmpty())
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		pulled := 0
+		c := collection.NewFromSlice([]int{1, 2, 3, 4}).Peek(func(int) { pulled++ })
+
+		for range *collection.Scan(c, 0, func(acc, x int) int { return acc + x }) {
+			break
+		}
+
+		assert.Equal(t, 1, pulled)
+	})
+}
+
+func TestGenCollection(t *testing.T) {
+	gen := func(r *rand.Rand) int { return r.Intn(1000) }
+
+	t.Run("Size", func(t *testing.T) {
+		c := collection.GenCollection(rand.New(rand.NewSource(1)), 50, gen)
+
+		assert.Equal(t, 50, c.Len())
+	})
+
+	t.Run("Reproducible", func(t *testing.T) {
+		a := collection.GenCollection(rand.New(rand.NewSource(42)), 20, gen).ToSlice()
+		b := collection.GenCollection(rand.New(rand.NewSource(42)), 20, gen).ToSlice()
+
+		assert.Equal(t, a, b)
+	})
+
+	t.Run("ReverseTwiceIsIdentity", func(t *testing.T) {
+		r := rand.New(rand.NewSource(7))
+		for range 100 {
+			c := collection.GenCollection(r, r.Intn(20), gen)
+
+			assert.True(t, c.Reverse().Reverse().Equals(c, func(a, b int) bool { return a == b }))
+		}
+	})
+
+	t.Run("ZeroSize", func(t *testing.T) {
+		assert.True(t, collection.GenCollection(rand.New(rand.NewSource(1)), 0, gen).IsEmpty())
+	})
+}
+
+func TestGenSlicePermutations(t *testing.T) {
+	s := []int{1, 2, 3, 4, 5}
+
+	t.Run("Permutations", func(t *testing.T) {
+		permutations := collection.GenSlicePermutations(rand.New(rand.NewSource(1)), s, 10).ToSlice()
+
+		assert.Len(t, permutations, 10)
+		for _, p := range permutations {
+			assert.ElementsMatch(t, s, p)
+		}
+		assert.Equal(t, []int{1, 2, 3, 4, 5}, s)
+	})
+
+	t.Run("Reproducible", func(t *testing.T) {
+		a := collection.GenSlicePermutations(rand.New(rand.NewSource(3)), s, 5).ToSlice()
+		b := collection.GenSlicePermutations(rand.New(rand.NewSource(3)), s, 5).ToSlice()
+
+		assert.Equal(t, a, b)
+	})
+}
+
+func TestGenWithDuplicates(t *testing.T) {
+	unique := func() func(*rand.Rand) int {
+		next := 0
+		return func(*rand.Rand) int {
+			next++
+			return next
+		}
+	}
+
+	countDuplicates := func(values []int) int {
+		seen := make(map[int]bool)
+		duplicates := 0
+		for _, v := range values {
+			if seen[v] {
+				duplicates++
+			}
+			seen[v] = true
+		}
+		return duplicates
+	}
+
+	t.Run("NoDuplicates", func(t *testing.T) {
+		values := collection.GenWithDuplicates(rand.New(rand.NewSource(1)), 100, 0, unique()).ToSlice()
+
+		assert.Len(t, values, 100)
+		assert.Equal(t, 0, countDuplicates(values))
+	})
+
+	t.Run("AllDuplicates", func(t *testing.T) {
+		values := collection.GenWithDuplicates(rand.New(rand.NewSource(1)), 100, 1, unique()).ToSlice()
+
+		assert.Len(t, values, 100)
+		assert.Equal(t, 99, countDuplicates(values))
+	})
+
+	t.Run("DuplicateRate", func(t *testing.T) {
+		values := collection.GenWithDuplicates(rand.New(rand.NewSource(1)), 10000, 0.3, unique()).ToSlice()
+
+		assert.InDelta(t, 3000, countDuplicates(values), 200)
+	})
+
+	t.Run("Reproducible", func(t *testing.T) {
+		a := collection.GenWithDuplicates(rand.New(rand.NewSource(5)), 50, 0.5, unique()).ToSlice()
+		b := collection.GenWithDuplicates(rand.New(rand.NewSource(5)), 50, 0.5, unique()).ToSlice()
+
+		assert.Equal(t, a, b)
+	})
+
+	t.Run("InvalidRate", func(t *testing.T) {
+		assert.Panics(t, func() { collection.GenWithDuplicates(rand.New(rand.NewSource(1)), 1, 1.5, unique()) })
+	})
+}
+
+func TestMode(t *testing.T) {
+	t.Run("SingleMode", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 2, 3, 4})
+		mode, err := collection.Mode(c)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2, mode)
+	})
+
+	t.Run("MultipleModes_FirstEncountered", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 2, 3, 3})
+		mode, err := collection.Mode(c)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2, mode) // First encountered mode
+	})
+
+	t.Run("AllUnique", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4, 5})
+		mode, err := collection.Mode(c)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, mode) // First element is returned
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{})
+		_, err := collection.Mode(c)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, collection.ErrEmptyCollection, err)
+	})
+
+	t.Run("StringElements", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"apple", "banana", "apple", "cherry", "banana", "banana"})
+		mode, err := collection.Mode(c)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "banana", mode)
+	})
+}
+
+func TestKeepLatestBy(t *testing.T) {
+	type entry struct {
+		Key     string
+		Version int
+		Created time.Time
+	}
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("MultipleVersions", func(t *testing.T) {
+		c := collection.NewFromSlice([]entry{
+			{Key: "a", Version: 1, Created: base},
+			{Key: "b", Version: 1, Created: base.Add(time.Minute)},
+			{Key: "a", Version: 3, Created: base.Add(2 * time.Minute)},
+			{Key: "a", Version: 2, Created: base.Add(time.Minute)},
+			{Key: "b", Version: 2, Created: base.Add(3 * time.Minute)},
+		})
+
+		result := collection.KeepLatestBy(c, func(x entry) string { return x.Key }, func(x entry) time.Time { return x.Created }).ToSlice()
+
+		assert.Len(t, result, 2)
+		assert.Equal(t, "a", result[0].Key)
+		assert.Equal(t, 3, result[0].Version)
+		assert.Equal(t, "b", result[1].Key)
+		assert.Equal(t, 2, result[1].Version)
+	})
+
+	t.Run("EqualTimestampsKeepsLater", func(t *testing.T) {
+		c := collection.NewFromSlice([]entry{
+			{Key: "a", Version: 1, Created: base},
+			{Key: "a", Version: 2, Created: base},
+		})
+
+		result := collection.KeepLatestBy(c, func(x entry) string { return x.Key }, func(x entry) time.Time { return x.Created }).ToSlice()
+
+		assert.Len(t, result, 1)
+		assert.Equal(t, 2, result[0].Version)
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		c := collection.NewFromSlice([]entry{})
+
+		result := collection.KeepLatestBy(c, func(x entry) string { return x.Key }, func(x entry) time.Time { return x.Created })
+
+		assert.True(t, result.IsEmpty())
+	})
+}
+
+func TestDistinctExternal(t *testing.T) {
+	type record struct {
+		ID   int
+		Name string
+	}
+
+	key := func(x record) string { return strconv.Itoa(x.ID) }
+	encode := func(x record) ([]byte, error) { return json.Marshal(x) }
+	decode := func(data []byte) (x record, err error) { return x, json.Unmarshal(data, &x) }
+
+	records := make([]record, 0, 500)
+	for i := range 500 {
+		records = append(records, record{ID: (i * 7) % 53, Name: fmt.Sprintf("record-%d", i)})
+	}
+
+	t.Run("Spills", func(t *testing.T) {
+		dir := t.TempDir()
+		c := collection.NewFromSlice(records)
+
+		result, err := collection.DistinctExternal(c, key, encode, decode, collection.ExternalOptions{MemoryLimit: 10, TempDir: dir})
+		assert.Nil(t, err)
+
+		expected := c.Distinct(func(a, b record) bool { return a.ID == b.ID }).ToSlice()
+
+		assert.Equal(t, expected, result.ToSlice())
+		assert.Nil(t, result.Err())
+	})
+
+	t.Run("InMemory", func(t *testing.T) {
+		dir := t.TempDir()
+		c := collection.NewFromSlice([]record{{2, "b"}, {1, "a"}, {2, "c"}})
+
+		result, err := collection.DistinctExternal(c, key, encode, decode, collection.ExternalOptions{TempDir: dir})
+		assert.Nil(t, err)
+
+		assert.Equal(t, []record{{2, "b"}, {1, "a"}}, result.ToSlice())
+
+		entries, _ := os.ReadDir(dir)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("Replayable", func(t *testing.T) {
+		dir := t.TempDir()
+		c := collection.NewFromSlice(records)
+
+		result, err := collection.DistinctExternal(c, key, encode, decode, collection.ExternalOptions{MemoryLimit: 10, TempDir: dir})
+		assert.Nil(t, err)
+		defer result.Close()
+
+		first := result.ToSlice()
+
+		assert.Len(t, first, 53)
+		assert.Equal(t, first, result.ToSlice())
+		assert.Nil(t, result.Err())
+	})
+
+	t.Run("TempFilesRemovedOnClose", func(t *testing.T) {
+		dir := t.TempDir()
+		c := collection.NewFromSlice(records)
+
+		result, err := collection.DistinctExternal(c, key, encode, decode, collection.ExternalOptions{MemoryLimit: 10, TempDir: dir})
+		assert.Nil(t, err)
+
+		result.ToSlice()
+
+		entries, _ := os.ReadDir(dir)
+		assert.NotEmpty(t, entries)
+
+		assert.Nil(t, result.Close())
+
+		entries, _ = os.ReadDir(dir)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("TempFilesRemovedWithoutEnumeration", func(t *testing.T) {
+		dir := t.TempDir()
+		c := collection.NewFromSlice(records)
+
+		result, err := collection.DistinctExternal(c, key, encode, decode, collection.ExternalOptions{MemoryLimit: 10, TempDir: dir})
+		assert.Nil(t, err)
+		assert.Nil(t, result.Close())
+
+		entries, _ := os.ReadDir(dir)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		dir := t.TempDir()
+		c := collection.NewFromSlice(records)
+
+		result, err := collection.DistinctExternal(c, key, encode, decode, collection.ExternalOptions{MemoryLimit: 10, TempDir: dir})
+		assert.Nil(t, err)
+		defer result.Close()
+
+		for range *result.Collection {
+			break
+		}
+
+		assert.Nil(t, result.Err())
+		assert.Len(t, result.ToSlice(), 53)
+	})
+
+	t.Run("ReadErrorAfterClose", func(t *testing.T) {
+		c := collection.NewFromSlice(records)
+
+		result, err := collection.DistinctExternal(c, key, encode, decode, collection.ExternalOptions{MemoryLimit: 10, TempDir: t.TempDir()})
+		assert.Nil(t, err)
+		assert.Nil(t, result.Close())
+
+		assert.NotPanics(t, func() { result.ToSlice() })
+		assert.ErrorIs(t, result.Err(), os.ErrNotExist)
+	})
+
+	t.Run("CorruptRun", func(t *testing.T) {
+		dir := t.TempDir()
+		c := collection.NewFromSlice(records)
+
+		result, err := collection.DistinctExternal(c, key, encode, decode, collection.ExternalOptions{MemoryLimit: 10, TempDir: dir})
+		assert.Nil(t, err)
+		defer result.Close()
+
+		runs, _ := filepath.Glob(filepath.Join(dir, "*", "seq-*.run"))
+		assert.NotEmpty(t, runs)
+		assert.Nil(t, os.WriteFile(runs[0], []byte("not a run"), 0o600))
+
+		assert.NotPanics(t, func() { result.ToSlice() })
+		assert.ErrorContains(t, result.Err(), "failed to read run")
+	})
+
+	t.Run("SpilledMatchesInMemory", func(t *testing.T) {
+		type secretRecord struct {
+			ID     int
+			secret int
+		}
+		encode := func(x secretRecord) ([]byte, error) { return fmt.Appendf(nil, "%d %d", x.ID, x.secret), nil }
+		decode := func(data []byte) (x secretRecord, err error) {
+			_, err = fmt.Sscanf(string(data), "%d %d", &x.ID, &x.secret)
+			return x, err
+		}
+		key := func(x secretRecord) string { return strconv.Itoa(x.ID) }
+		c := collection.Select(collection.NewFromRange(0, 200), func(i int) secretRecord {
+			return secretRecord{ID: (i * 7) % 31, secret: i + 1}
+		})
+
+		spilled, err := collection.DistinctExternal(c, key, encode, decode, collection.ExternalOptions{MemoryLimit: 4, TempDir: t.TempDir()})
+		assert.Nil(t, err)
+		defer spilled.Close()
+		inMemory, err := collection.DistinctExternal(c, key, encode, decode, collection.ExternalOptions{})
+		assert.Nil(t, err)
+
+		expected := c.Distinct(func(a, b secretRecord) bool { return a.ID == b.ID }).ToSlice()
+		assert.Equal(t, expected, inMemory.ToSlice())
+		assert.Equal(t, expected, spilled.ToSlice())
+		assert.Nil(t, spilled.Err())
+	})
+
+	t.Run("EncodeError", func(t *testing.T) {
+		errEncode := errors.New("encode failed")
+		c := collection.NewFromSlice(records)
+		dir := t.TempDir()
+
+		_, err := collection.DistinctExternal(c, key, func(record) ([]byte, error) { return nil, errEncode }, decode, collection.ExternalOptions{MemoryLimit: 10, TempDir: dir})
+
+		assert.ErrorIs(t, err, errEncode)
+		entries, _ := os.ReadDir(dir)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		c := collection.NewFromSlice([]record{})
+
+		result, err := collection.DistinctExternal(c, key, encode, decode, collection.ExternalOptions{MemoryLimit: 10})
+		assert.Nil(t, err)
+		assert.True(t, result.IsEmpty())
+	})
+
+	t.Run("InvalidTempDir", func(t *testing.T) {
+		c := collection.NewFromSlice(records)
+
+		_, err := collection.DistinctExternal(c, key, encode, decode, collection.ExternalOptions{MemoryLimit: 10, TempDir: filepath.Join(t.TempDir(), "missing")})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestCollection2(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2, "c": 3, "d": 4}
+
+	t.Run("FilterByValue", func(t *testing.T) {
+		result := collection.NewFromMapEntries(m).Where(func(k string, v int) bool {
+			return v%2 == 0
+		}).ToMap()
+
+		assert.Equal(t, map[string]int{"b": 2, "d": 4}, result)
+	})
+
+	t.Run("TransformValues", func(t *testing.T) {
+		result := collection.Select2(collection.NewFromMapEntries(m), func(k string, v int) string {
+			return fmt.Sprintf("%s=%d", k, v)
+		}).ToMap()
+
+		assert.Equal(t, map[string]string{"a": "a=1", "b": "b=2", "c": "c=3", "d": "d=4"}, result)
+	})
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		assert.Equal(t, m, collection.NewFromMapEntries(m).ToMap())
+	})
+
+	t.Run("KeysAndValues", func(t *testing.T) {
+		c := collection.NewFromMapEntries(m).Where(func(k string, v int) bool {
+			return k != "a"
+		})
+
+		assert.ElementsMatch(t, []string{"b", "c", "d"}, c.Keys().ToSlice())
+		assert.ElementsMatch(t, []int{2, 3, 4}, c.Values().ToSlice())
+	})
+
+	t.Run("Entries", func(t *testing.T) {
+		c := collection.NewFromMapEntries(map[string]int{"a": 1})
+
+		assert.Equal(t, []collection.Pair[string, int]{{First: "a", Second: 1}}, c.Entries().ToSlice())
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		calls := 0
+		c := collection.NewFromMapEntries(m).Where(func(k string, v int) bool {
+			calls++
+			return true
+		})
+
+		assert.Equal(t, 1, c.Keys().Take(1).Len())
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestToSeq2(t *testing.T) {
+	t.Run("Logic", func(t *testing.T) {
+		c := collection.NewFromSlice([]collection.Pair[string, int]{{First: "a", Second: 1}, {First: "b", Second: 2}})
+
+		var keys []string
+		var values []int
+		for k, v := range collection.ToSeq2(c) {
+			keys = append(keys, k)
+			values = append(values, v)
+		}
+
+		assert.Equal(t, []string{"a", "b"}, keys)
+		assert.Equal(t, []int{1, 2}, values)
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		calls := 0
+		c := collection.NewFromSlice([]collection.Pair[string, int]{{First: "a", Second: 1}, {First: "b", Second: 2}}).
+			Peek(func(collection.Pair[string, int]) { calls++ })
+
+		for range collection.ToSeq2(c) {
+			break
+		}
+
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestKeysAndValues(t *testing.T) {
+	entries := []collection.Pair[string, int]{{First: "a", Second: 1}, {First: "b", Second: 2}, {First: "c", Second: 3}}
+
+	t.Run("Aligned", func(t *testing.T) {
+		c := collection.NewFromSlice(entries)
+		keys := collection.Keys(c).ToSlice()
+		values := collection.Values(c).ToSlice()
+
+		for i, entry := range entries {
+			assert.Equal(t, entry.First, keys[i])
+			assert.Equal(t, entry.Second, values[i])
+		}
+	})
+
+	t.Run("Independent", func(t *testing.T) {
+		c := collection.NewFromSlice(entries)
+
+		assert.Equal(t, []string{"a"}, collection.Keys(c).Take(1).ToSlice())
+		assert.Equal(t, []int{1, 2, 3}, collection.Values(c).ToSlice())
+		assert.Equal(t, []string{"a", "b", "c"}, collection.Keys(c).ToSlice())
+	})
+
+	t.Run("Lazy", func(t *testing.T) {
+		calls := 0
+		c := collection.NewFromSlice(entries).Peek(func(collection.Pair[string, int]) { calls++ })
+		keys := collection.Keys(c)
+
+		assert.Equal(t, 0, calls)
+		assert.Equal(t, []string{"a", "b"}, keys.Take(2).ToSlice())
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("FromMap", func(t *testing.T) {
+		c := collection.NewFromSeq2(maps.All(map[string]int{"a": 1, "b": 2}))
+
+		assert.ElementsMatch(t, []string{"a", "b"}, collection.Keys(c).ToSlice())
+		assert.ElementsMatch(t, []int{1, 2}, collection.Values(c).ToSlice())
+	})
+}
+
+func TestToMultiMap(t *testing.T) {
+	type person struct {
+		Name string
+		City string
+	}
+	people := []person{
+		{Name: "Alice", City: "London"},
+		{Name: "Bob", City: "Paris"},
+		{Name: "Charlie", City: "London"},
+	}
+
+	t.Run("Logic", func(t *testing.T) {
+		result := collection.ToMultiMap(collection.NewFromSlice(people), func(p person) string {
+			return p.City
+		})
+
+		assert.Equal(t, map[string][]person{
+			"London": {people[0], people[2]},
+			"Paris":  {people[1]},
+		}, result)
+	})
+
+	t.Run("Select", func(t *testing.T) {
+		result := collection.ToMultiMapSelect(collection.NewFromSlice(people), func(p person) string {
+			return p.City
+		}, func(p person) string {
+			return p.Name
+		})
+
+		assert.Equal(t, map[string][]string{"London": {"Alice", "Charlie"}, "Paris": {"Bob"}}, result)
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		result := collection.ToMultiMap(collection.NewFromSlice([]person{}), func(p person) string {
+			return p.City
+		})
+
+		assert.Empty(t, result)
+	})
+}
+
+func TestToSortedSlice(t *testing.T) {
+	t.Run("Ints", func(t *testing.T) {
+		assert.Equal(t, []int{1, 2, 3, 5}, collection.ToSortedSlice(collection.NewFromSlice([]int{3, 1, 5, 2})))
+	})
+
+	t.Run("Strings", func(t *testing.T) {
+		assert.Equal(t, []string{"a", "b", "c"}, collection.ToSortedSlice(collection.NewFromSlice([]string{"c", "a", "b"})))
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		assert.Empty(t, collection.ToSortedSlice(collection.NewFromSlice([]int{})))
+	})
+}
+
+func TestToSortedSliceFunc(t *testing.T) {
+	t.Run("Descending", func(t *testing.T) {
+		result := collection.ToSortedSliceFunc(collection.NewFromSlice([]int{3, 1, 5, 2}), func(a, b int) int {
+			return b - a
+		})
+
+		assert.Equal(t, []int{5, 3, 2, 1}, result)
+	})
+
+	t.Run("Stable", func(t *testing.T) {
+		type item struct {
+			Key   int
+			Label string
+		}
+		c := collection.NewFromSlice([]item{{2, "a"}, {1, "b"}, {2, "c"}, {1, "d"}})
+
+		result := collection.ToSortedSliceFunc(c, func(a, b item) int {
+			return a.Key - b.Key
+		})
+
+		assert.Equal(t, []item{{1, "b"}, {1, "d"}, {2, "a"}, {2, "c"}}, result)
+	})
+}
+
+func TestWriteLines(t *testing.T) {
+	t.Run("Lines", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := collection.WriteLines(collection.NewFromSlice([]string{"a", "", "b"}), &buf)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "a\n\nb\n", buf.String())
+	})
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		lines := collection.NewFromLines(strings.NewReader("one\r\ntwo\nthree"))
+		var buf bytes.Buffer
+		err := collection.WriteLines(lines.Collection, &buf)
+
+		assert.NoError(t, err)
+		assert.NoError(t, lines.Err())
+		assert.Equal(t, "one\ntwo\nthree\n", buf.String())
+	})
+
+	t.Run("StringType", func(t *testing.T) {
+		type level string
+		var buf bytes.Buffer
+		err := collection.WriteLines(collection.NewFromSlice([]level{"info", "warn"}), &buf)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "info\nwarn\n", buf.String())
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := collection.WriteLines(collection.NewFromSlice([]string{}), &buf)
+
+		assert.NoError(t, err)
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("WriterError", func(t *testing.T) {
+		err := collection.WriteLines(collection.NewFromSlice([]string{"a", "b"}), &failingWriter{n: 1})
+
+		assert.EqualError(t, err, "write failed")
+	})
+}
+
+func TestToLookup(t *testing.T) {
+	words := []string{"banana", "apple", "cherry", "blueberry", "avocado", "beetroot"}
+	firstLetter := func(x string) string { return string(x[0]) }
+
+	t.Run("KeysInInsertionOrder", func(t *testing.T) {
+		for range 10 {
+			l := collection.ToLookup(collection.NewFromSlice(words), firstLetter)
+
+			assert.Equal(t, 3, l.Len())
+			assert.Equal(t, []string{"b", "a", "c"}, l.Keys().ToSlice())
+		}
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		l := collection.ToLookup(collection.NewFromSlice(words), firstLetter)
+
+		assert.Equal(t, []string{"banana", "blueberry", "beetroot"}, l.Get("b").ToSlice())
+		assert.Equal(t, []string{"apple", "avocado"}, l.Get("a").ToSlice())
+	})
+
+	t.Run("GetMissingKey", func(t *testing.T) {
+		l := collection.ToLookup(collection.NewFromSlice(words), firstLetter)
+
+		assert.True(t, l.Get("z").IsEmpty())
+	})
+
+	t.Run("All", func(t *testing.T) {
+		l := collection.ToLookup(collection.NewFromSlice(words), firstLetter)
+
+		var keys []string
+		var counts []int
+		for k, group := range l.All() {
+			keys = append(keys, k)
+			counts = append(counts, group.Len())
+		}
+
+		assert.Equal(t, []string{"b", "a", "c"}, keys)
+		assert.Equal(t, []int{3, 2, 1}, counts)
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		l := collection.ToLookup(collection.NewFromSlice(words), firstLetter)
+
+		for range l.All() {
+			break
+		}
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		l := collection.ToLookup(collection.NewFromSlice([]string{}), firstLetter)
+
+		assert.Equal(t, 0, l.Len())
+		assert.True(t, l.Keys().IsEmpty())
+	})
+}
+
+func TestRoundRobin(t *testing.T) {
+	t.Run("DifferentSizes", func(t *testing.T) {
+		result := collection.RoundRobin([]*collection.Collection[string]{
+			collection.NewFromSlice([]string{"a1", "a2", "a3"}),
+			collection.NewFromSlice([]string{"b1"}),
+			collection.NewFromSlice([]string{"c1", "c2"}),
+		}).ToSlice()
+
+		assert.Equal(t, []string{"a1", "b1", "c1", "a2", "c2", "a3"}, result)
+	})
+
+	t.Run("EmptyGroup", func(t *testing.T) {
+		result := collection.RoundRobin([]*collection.Collection[int]{
+			collection.Empty[int](),
+			collection.NewFromSlice([]int{1, 2}),
+		}).ToSlice()
+
+		assert.Equal(t, []int{1, 2}, result)
+	})
+
+	t.Run("SingleGroup", func(t *testing.T) {
+		result := collection.RoundRobin([]*collection.Collection[int]{collection.NewFromSlice([]int{1, 2, 3})}).ToSlice()
+
+		assert.Equal(t, []int{1, 2, 3}, result)
+	})
+
+	t.Run("NoGroups", func(t *testing.T) {
+		assert.True(t, collection.RoundRobin[int](nil).IsEmpty())
+	})
+
+	t.Run("Lookup", func(t *testing.T) {
+		type job struct {
+			Tenant string
+			ID     int
+		}
+		jobs := collection.NewFromSlice([]job{{"x", 1}, {"x", 2}, {"y", 3}, {"x", 4}, {"z", 5}, {"y", 6}})
+		lookup := collection.ToLookup(jobs, func(j job) string { return j.Tenant })
+
+		for range 3 {
+			result := collection.Select(lookup.RoundRobin(), func(j job) int { return j.ID }).ToSlice()
+
+			assert.Equal(t, []int{1, 3, 5, 2, 6, 4}, result)
+		}
+	})
+}
+
+func TestAverageOrError(t *testing.T) {
+	t.Run("Empty_Error", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{})
+		_, err := collection.AverageOrError(c)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Uint", func(t *testing.T) {
+		c := collection.NewFromSlice([]uint{1, 2, 3})
+		v, err := collection.AverageOrError(c)
+		f, _ := v.Float64()
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2.0, f)
+	})
+
+	t.Run("Uint8", func(t *testing.T) {
+		c := collection.NewFromSlice([]uint8{1, 2, 3})
+		v, err := collection.AverageOrError(c)
+		f, _ := v.Float64()
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2.0, f)
+	})
+
+	t.Run("Uint16", func(t *testing.T) {
+		c := collection.NewFromSlice([]uint16{1, 2, 3})
+		v, err := collection.AverageOrError(c)
+		f, _ := v.Float64()
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2.0, f)
+	})
+
+	t.Run("Uint32", func(t *testing.T) {
+		c := collection.NewFromSlice([]uint32{1, 2, 3})
+		v, err := collection.AverageOrError(c)
+		f, _ := v.Float64()
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2.0, f)
+	})
+
+	t.Run("Uint64", func(t *testing.T) {
+		c := collection.NewFromSlice([]uint64{1, 2, 3})
+		v, err := collection.AverageOrError(c)
+		f, _ := v.Float64()
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2.0, f)
+	})
+
+	t.Run("Int", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3})
+		v, err := collection.AverageOrError(c)
+		f, _ := v.Float64()
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2.0, f)
+	})
+
+	t.Run("Int8", func(t *testing.T) {
+		c := collection.NewFromSlice([]int8{1, 2, 3})
+		v, err := collection.AverageOrError(c)
+		f, _ := v.Float64()
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2.0, f)
+	})
+
+	t.Run("Int16", func(t *testing.T) {
+		c := collection.NewFromSlice([]int16{1, 2, 3})
+		v, err := collection.AverageOrError(c)
+		f, _ := v.Float64()
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2.0, f)
+	})
+
+	t.Run("Int32", func(t *testing.T) {
+		c := collection.NewFromSlice([]int32{1, 2, 3})
+		v, err := collection.AverageOrError(c)
+		f, _ := v.Float64()
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2.0, f)
+	})
+
+	t.Run("Int64", func(t *testing.T) {
+		c := collection.NewFromSlice([]int64{1, 2, 3})
+		v, err := collection.AverageOrError(c)
+		f, _ := v.Float64()
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2.0, f)
+	})
+
+	t.Run("Float32", func(t *testing.T) {
+		c := collection.NewFromSlice([]float32{1.0, 2.0, 3.0})
+		v, err := collection.AverageOrError(c)
+		f, _ := v.Float64()
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2.0, f)
+	})
+
+	t.Run("Float64", func(t *testing.T) {
+		c := collection.NewFromSlice([]float64{1.0, 2.0, 3.0})
+		v, err := collection.AverageOrError(c)
+		f, _ := v.Float64()
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2.0, f)
+	})
+}
+
+func TestSum(t *testing.T) {
+	t.Run("Uint", func(t *testing.T) {
+		c := collection.NewFromSlice([]uint{1, 2, 3})
+		v := collection.Sum(c)
+		f, _ := v.Float64()
+
+		assert.Equal(t, 6.0, f)
+	})
+
+	t.Run("Uint8", func(t *testing.T) {
+		c := collection.NewFromSlice([]uint8{1, 2, 3})
+		v := collection.Sum(c)
+		f, _ := v.Float64()
+
+		assert.Equal(t, 6.0, f)
+	})
+
+	t.Run("Uint16", func(t *testing.T) {
+		c := collection.NewFromSlice([]uint16{1, 2, 3})
+		v := collection.Sum(c)
+		f, _ := v.Float64()
+
+		assert.Equal(t, 6.0, f)
+	})
+
+	t.Run("Uint32", func(t *testing.T) {
+		c := collection.NewFromSlice([]uint32{1, 2, 3})
+		v := collection.Sum(c)
+		f, _ := v.Float64()
+
+		assert.Equal(t, 6.0, f)
+	})
+
+	t.Run("Uint64", func(t *testing.T) {
+		c := collection.NewFromSlice([]uint64{1, 2, 3})
+		v := collection.Sum(c)
+		f, _ := v.Float64()
+
+		assert.Equal(t, 6.0, f)
+	})
+
+	t.Run("Int", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3})
+		v := collection.Sum(c)
+		f, _ := v.Float64()
+
+		assert.Equal(t, 6.0, f)
+	})
+
+	t.Run("Int8", func(t *testing.T) {
+		c := collection.NewFromSlice([]int8{1, 2, 3})
+		v := collection.Sum(c)
+		f, _ := v.Float64()
+
+		assert.Equal(t, 6.0, f)
+	})
+
+	t.Run("Int16", func(t *testing.T) {
+		c := collection.NewFromSlice([]int16{1, 2, 3})
+		v := collection.Sum(c)
+		f, _ := v.Float64()
+
+		assert.Equal(t, 6.0, f)
+	})
+
+	t.Run("Int32", func(t *testing.T) {
+		c := collection.NewFromSlice([]int32{1, 2, 3})
+		v := collection.Sum(c)
+		f, _ := v.Float64()
+
+		assert.Equal(t, 6.0, f)
+	})
+
+	t.Run("Int64", func(t *testing.T) {
+		c := collection.NewFromSlice([]int64{1, 2, 3})
+		v := collection.Sum(c)
+		f, _ := v.Float64()
+
+		assert.Equal(t, 6.0, f)
+	})
+
+	t.Run("Float32", func(t *testing.T) {
+		c := collection.NewFromSlice([]float32{1.0, 2.0, 3.0})
+		v := collection.Sum(c)
+		f, _ := v.Float64()
+
+		assert.Equal(t, 6.0, f)
+	})
+
+	t.Run("Float64", func(t *testing.T) {
+		c := collection.NewFromSlice([]float64{1.0, 2.0, 3.0})
+		v := collection.Sum(c)
+		f, _ := v.Float64()
+
+		assert.Equal(t, 6.0, f)
+	})
+}
+
+func TestMin(t *testing.T) {
+	t.Run("Positive", func(t *testing.T) {
+
+		c := collection.NewFromSlice([]int{1, 2, 3})
+		v := collection.Min(c)
+
+		assert.Equal(t, 1, v)
+	})
+
+	t.Run("Negative", func(t *testing.T) {
+
+		c := collection.NewFromSlice([]int{-1, -2, -3})
+		v := collection.Min(c)
+
+		assert.Equal(t, -3, v)
+	})
+}
+
+func TestMax(t *testing.T) {
+	t.Run("Positive", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3})
+		v := collection.Max(c)
+
+		assert.Equal(t, 3, v)
+	})
+
+	t.Run("Negative", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{-1, -2, -3})
+		v := collection.Max(c)
+
+		assert.Equal(t, -1, v)
+	})
+}
+
+func TestMedian(t *testing.T) {
+	t.Run("OddNumberOfElements", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{3, 1, 4, 2, 5})
+		median, err := collection.Median(c)
+		f, _ := median.Float64()
+
+		assert.Nil(t, err)
+		assert.Equal(t, 3.0, f)
 	})
 
-	t.Run("Sum", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{1, 2, 3, 4, 5})
-
-		result := c.Aggregate(0, func(accumulator any, item int) any {
-			return accumulator.(int) + item
-		})
+	t.Run("EvenNumberOfElements", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{3, 1, 4, 2})
+		median, err := collection.Median(c)
+		f, _ := median.Float64()
 
-		assert.Equal(t, 15, result)
+		assert.Nil(t, err)
+		assert.Equal(t, 2.5, f)
 	})
 
-	t.Run("Concatenation", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-
-		result := c.Aggregate("", func(accumulator any, item string) any {
-			return accumulator.(string) + item
-		})
+	t.Run("SingleElement", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{42})
+		median, err := collection.Median(c)
+		f, _ := median.Float64()
 
-		assert.Equal(t, "abc", result)
+		assert.Nil(t, err)
+		assert.Equal(t, 42.0, f)
 	})
 
 	t.Run("EmptyCollection", func(t *testing.T) {
 		c := collection.NewFromSlice([]int{})
+		_, err := collection.Median(c)
 
-		seed := 10
-		result := c.Aggregate(seed, func(accumulator any, item int) any {
-			assert.Fail(t, "This should not be called")
-			return nil
-		})
-
-		assert.Equal(t, seed, result)
+		assert.NotNil(t, err)
+		assert.Equal(t, "cannot compute median of empty collection", err.Error())
 	})
-}
-
-func TestForEach(t *testing.T) {
-	numbers := collection.NewFromSlice([]int{1, 2, 3})
 
-	var results []int
+	t.Run("FloatElements", func(t *testing.T) {
+		c := collection.NewFromSlice([]float64{3.5, 1.2, 4.8, 2.1})
+		median, err := collection.Median(c)
+		f, _ := median.Float64()
 
-	numbers.ForEach(func(x int) {
-		results = append(results, x)
+		assert.Nil(t, err)
+		assert.Equal(t, 2.8, f)
 	})
-
-	if len(results) != 3 {
-		t.Errorf("Expected 3 results, got %d", len(results))
-	}
 }
 
-func TestParallelForEach(t *testing.T) {
-	t.Run("SingleThread", func(t *testing.T) {
-		numbers := collection.NewFromSlice([]int{1, 2})
-
-		start := time.Now()
-
-		var results []int
-		var mu sync.Mutex
+func TestEvictOlderThan(t *testing.T) {
+	type entry struct {
+		Key     string
+		Created time.Time
+	}
+	cutoff := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
 
-		err := numbers.ParallelForEach(
-			context.Background(),
-			func(ctx context.Context, x int) error {
-				time.Sleep(1 * time.Second)
+	t.Run("Evicts", func(t *testing.T) {
+		c := collection.NewFromSlice([]entry{
+			{Key: "a", Created: cutoff.Add(-time.Hour)},
+			{Key: "b", Created: cutoff.Add(time.Hour)},
+			{Key: "c", Created: cutoff.Add(-time.Minute)},
+		})
 
-				mu.Lock()
-				results = append(results, x)
-				mu.Unlock()
-				return nil
-			},
-			1,
-		)
+		kept, evicted := c.EvictOlderThan(func(x entry) time.Time { return x.Created }, cutoff)
 
-		duration := time.Since(start)
+		assert.Equal(t, 2, evicted)
+		assert.Equal(t, []entry{{Key: "b", Created: cutoff.Add(time.Hour)}}, kept.ToSlice())
+	})
 
-		if err != nil {
-			t.Fatalf("Unexpected error: %v", err)
-		}
+	t.Run("BoundaryKept", func(t *testing.T) {
+		c := collection.NewFromSlice([]entry{
+			{Key: "a", Created: cutoff},
+		})
 
-		if duration < 2*time.Second {
-			t.Error("Expected single-threaded execution")
-		}
+		kept, evicted := c.EvictOlderThan(func(x entry) time.Time { return x.Created }, cutoff)
 
-		if len(results) != 2 {
-			t.Errorf("Expected 2 results, got %d", len(results))
-		}
+		assert.Equal(t, 0, evicted)
+		assert.Equal(t, 1, kept.Len())
 	})
 
-	t.Run("MultiThread", func(t *testing.T) {
-		numbers := collection.NewFromSlice([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
-
-		start := time.Now()
+	t.Run("EmptyCollection", func(t *testing.T) {
+		c := collection.NewFromSlice([]entry{})
 
-		var results []int
-		var mu sync.Mutex
+		kept, evicted := c.EvictOlderThan(func(x entry) time.Time { return x.Created }, cutoff)
 
-		err := numbers.ParallelForEach(
-			context.Background(),
-			func(ctx context.Context, x int) error {
-				time.Sleep(1 * time.Second)
+		assert.Equal(t, 0, evicted)
+		assert.True(t, kept.IsEmpty())
+	})
+}
 
-				mu.Lock()
-				results = append(results, x)
-				mu.Unlock()
-				return nil
-			},
-			5,
-		)
+func TestPercentileSketch(t *testing.T) {
+	exactPercentile := func(s []float64, p float64) float64 {
+		sorted := slices.Clone(s)
+		slices.Sort(sorted)
+		rank := p / 100 * float64(len(sorted)-1)
+		lower := int(rank)
+		if lower+1 >= len(sorted) {
+			return sorted[lower]
+		}
+		return sorted[lower] + (rank-float64(lower))*(sorted[lower+1]-sorted[lower])
+	}
 
-		duration := time.Since(start)
+	assertWithinTolerance := func(t *testing.T, samples []float64, tolerance float64) {
+		estimates, err := collection.PercentileSketch(collection.NewFromSlice(samples), 50, 90, 99)
+		assert.Nil(t, err)
 
-		if err != nil {
-			t.Fatalf("Unexpected error: %v", err)
+		for _, p := range []float64{50, 90, 99} {
+			exact := exactPercentile(samples, p)
+			assert.InEpsilon(t, exact, estimates[p], tolerance, "p%v", p)
 		}
+	}
 
-		if duration > 3*time.Second {
-			t.Error("Expected parallel execution")
+	t.Run("Uniform", func(t *testing.T) {
+		r := rand.New(rand.NewSource(1))
+		samples := make([]float64, 100000)
+		for i := range samples {
+			samples[i] = r.Float64() * 1000
 		}
 
-		if len(results) != 10 {
-			t.Errorf("Expected 10 results, got %d", len(results))
-		}
+		assertWithinTolerance(t, samples, 0.01)
 	})
 
-	t.Run("Error", func(t *testing.T) {
-		numbers := collection.NewFromSlice([]int{1, 2, 3, 4, 5})
+	t.Run("LogNormal", func(t *testing.T) {
+		r := rand.New(rand.NewSource(1))
+		samples := make([]float64, 100000)
+		for i := range samples {
+			samples[i] = math.Exp(r.NormFloat64())
+		}
 
-		err := numbers.ParallelForEach(
-			context.Background(),
-			func(ctx context.Context, x int) error {
-				if x == 3 {
-					return errors.New("error")
-				}
-				return nil
-			},
-			1,
-		)
+		assertWithinTolerance(t, samples, 0.05)
+	})
 
-		if err == nil {
-			t.Error("Expected error, got nil")
-		}
+	t.Run("MinAndMax", func(t *testing.T) {
+		estimates, err := collection.PercentileSketch(collection.NewFromSlice([]float64{5, 1, 9, 3, 7, 2}), 0, 100)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1.0, estimates[0])
+		assert.Equal(t, 9.0, estimates[100])
 	})
 
-	t.Run("Cancel", func(t *testing.T) {
-		numbers := collection.NewFromSlice([]int{1, 2, 3, 4, 5, 6})
+	t.Run("FewSamples", func(t *testing.T) {
+		estimates, err := collection.PercentileSketch(collection.NewFromSlice([]float64{4, 1, 3}), 50)
 
-		ctx, cancel := context.WithCancel(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, 3.0, estimates[50])
+	})
 
+	t.Run("ChannelSource", func(t *testing.T) {
+		ch := make(chan float64)
 		go func() {
-			time.Sleep(1 * time.Second)
-			cancel()
+			defer close(ch)
+			for i := range 1000 {
+				ch <- float64(i)
+			}
 		}()
 
-		err := numbers.ParallelForEach(
-			ctx,
-			func(ctx context.Context, x int) error {
-				if x == 3 {
-					return errors.New("error")
-				}
-
-				time.Sleep(1 * time.Second)
-				return nil
-			},
-			1,
-		)
+		estimates, err := collection.PercentileSketch(collection.NewFromChannel(ch), 50)
 
-		assert.IsType(t, context.Canceled, err)
+		assert.Nil(t, err)
+		assert.InDelta(t, 499.5, estimates[50], 10)
 	})
-}
 
-func TestPeek(t *testing.T) {
-	t.Run("Peek", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{1, 2, 3})
-
-		var results []int
-		c.Peek(func(x int) {
-			results = append(results, x)
-		}).ToSlice()
+	t.Run("EmptyCollection", func(t *testing.T) {
+		_, err := collection.PercentileSketch(collection.NewFromSlice([]float64{}), 50)
 
-		assert.Equal(t, 3, len(results))
-		assert.Equal(t, 1, results[0])
-		assert.Equal(t, 2, results[1])
-		assert.Equal(t, 3, results[2])
+		assert.Equal(t, collection.ErrEmptyCollection, err)
 	})
 
-	t.Run("Break", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{1, 2, 3})
+	t.Run("InvalidPercentile", func(t *testing.T) {
+		_, err := collection.PercentileSketch(collection.NewFromSlice([]float64{1}), 101)
 
-		for range *c.Peek(func(x int) {}) {
-			break
-		}
+		assert.ErrorIs(t, err, collection.ErrInvalidPercentile)
 	})
 }
 
-func TestZip(t *testing.T) {
-	t.Run("EqualLength", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]int{1, 2, 3})
-		c2 := collection.NewFromSlice([]string{"a", "b", "c"})
+func TestCountPerInterval(t *testing.T) {
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	identity := func(x time.Time) time.Time { return x }
+
+	t.Run("Counts", func(t *testing.T) {
+		c := collection.NewFromSlice([]time.Time{
+			base,
+			base.Add(10 * time.Second),
+			base.Add(59 * time.Second),
+			base.Add(time.Minute),
+			base.Add(3*time.Minute + 30*time.Second),
+		})
 
-		result := collection.Zip(c1, c2, func(a int, b string) string {
-			return strconv.Itoa(a) + b
-		}).ToSlice()
+		result, err := c.CountPerInterval(identity, time.Minute, false)
 
-		assert.Equal(t, 3, len(result))
-		assert.Equal(t, "1a", result[0])
-		assert.Equal(t, "2b", result[1])
-		assert.Equal(t, "3c", result[2])
+		assert.Nil(t, err)
+		assert.Equal(t, []collection.IntervalCount{
+			{Start: base, Count: 3},
+			{Start: base.Add(time.Minute), Count: 1},
+			{Start: base.Add(3 * time.Minute), Count: 1},
+		}, result.ToSlice())
 	})
 
-	t.Run("FirstShorter", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]int{1, 2})
-		c2 := collection.NewFromSlice([]string{"a", "b", "c"})
-
-		result := collection.Zip(c1, c2, func(a int, b string) string {
-			return strconv.Itoa(a) + b
-		}).ToSlice()
+	t.Run("FillGaps", func(t *testing.T) {
+		c := collection.NewFromSlice([]time.Time{
+			base.Add(30 * time.Second),
+			base.Add(3 * time.Minute),
+		})
 
-		assert.Equal(t, 2, len(result))
-		assert.Equal(t, "1a", result[0])
-		assert.Equal(t, "2b", result[1])
-	})
+		result, err := c.CountPerInterval(identity, time.Minute, true)
 
-	t.Run("SecondShorter", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]int{1, 2, 3})
-		c2 := collection.NewFromSlice([]string{"a", "b"})
+		assert.Nil(t, err)
+		assert.Equal(t, []collection.IntervalCount{
+			{Start: base, Count: 1},
+			{Start: base.Add(time.Minute), Count: 0},
+			{Start: base.Add(2 * time.Minute), Count: 0},
+			{Start: base.Add(3 * time.Minute), Count: 1},
+		}, result.ToSlice())
+	})
+
+	t.Run("Unordered", func(t *testing.T) {
+		c := collection.NewFromSlice([]time.Time{
+			base.Add(time.Minute),
+			base,
+		})
 
-		result := collection.Zip(c1, c2, func(a int, b string) string {
-			return strconv.Itoa(a) + b
-		}).ToSlice()
+		_, err := c.CountPerInterval(identity, time.Minute, false)
 
-		assert.Equal(t, 2, len(result))
-		assert.Equal(t, "1a", result[0])
-		assert.Equal(t, "2b", result[1])
+		assert.ErrorIs(t, err, collection.ErrNotOrdered)
 	})
 
-	t.Run("Break", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]int{1, 2, 3})
-		c2 := collection.NewFromSlice([]string{"a", "b", "c"})
+	t.Run("InvalidWidth", func(t *testing.T) {
+		c := collection.NewFromSlice([]time.Time{base})
 
-		for range *collection.Zip(c1, c2, func(a int, b string) string {
-			return strconv.Itoa(a) + b
-		}) {
-			break
-		}
+		_, err := c.CountPerInterval(identity, 0, false)
+
+		assert.ErrorIs(t, err, collection.ErrInvalidInterval)
 	})
-}
 
-func TestElementAt(t *testing.T) {
-	t.Run("Valid", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		v, ok := c.ElementAt(0)
+	t.Run("EmptyCollection", func(t *testing.T) {
+		c := collection.NewFromSlice([]time.Time{})
 
-		assert.True(t, ok)
-		assert.Equal(t, "a", v)
+		result, err := c.CountPerInterval(identity, time.Minute, true)
+
+		assert.Nil(t, err)
+		assert.True(t, result.IsEmpty())
 	})
+}
 
-	t.Run("LessThanZero", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		_, ok := c.ElementAt(-1)
+func TestAddElementwise(t *testing.T) {
+	t.Run("Adds", func(t *testing.T) {
+		result, err := collection.AddElementwise(collection.NewFromSlice([]int{1, 2, 3}), collection.NewFromSlice([]int{10, 20, 30}))
 
-		assert.False(t, ok)
+		assert.Nil(t, err)
+		assert.Equal(t, []int{11, 22, 33}, result.ToSlice())
 	})
 
-	t.Run("OutOfBounds", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		_, ok := c.ElementAt(3)
+	t.Run("LengthMismatch", func(t *testing.T) {
+		_, err := collection.AddElementwise(collection.NewFromSlice([]int{1, 2, 3}), collection.NewFromSlice([]int{10}))
+		assert.ErrorIs(t, err, collection.ErrLengthMismatch)
+		assert.ErrorContains(t, err, "index 1")
 
-		assert.False(t, ok)
+		_, err = collection.AddElementwise(collection.NewFromSlice([]int{1}), collection.NewFromSlice([]int{10, 20}))
+		assert.ErrorIs(t, err, collection.ErrLengthMismatch)
 	})
-}
-
-func TestElementAtOrError(t *testing.T) {
-	t.Run("Valid", func(t *testing.T) {
 
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		v, err := c.ElementAtOrError(0)
+	t.Run("EmptyCollections", func(t *testing.T) {
+		result, err := collection.AddElementwise(collection.NewFromSlice([]int{}), collection.NewFromSlice([]int{}))
 
 		assert.Nil(t, err)
-		assert.Equal(t, "a", v)
+		assert.True(t, result.IsEmpty())
 	})
 
-	t.Run("LessThanZero", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		_, err := c.ElementAtOrError(-1)
-
-		assert.NotNil(t, err)
+	t.Run("IntegerOverflowWraps", func(t *testing.T) {
+		result, err := collection.AddElementwise(collection.NewFromSlice([]int8{127}), collection.NewFromSlice([]int8{1}))
+
+		assert.Nil(t, err)
+		assert.Equal(t, []int8{-128}, result.ToSlice())
 	})
 
-	t.Run("OutOfBounds", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"a", "b", "c"})
-		_, err := c.ElementAtOrError(3)
+	t.Run("FloatPrecision", func(t *testing.T) {
+		result, err := collection.AddElementwise(collection.NewFromSlice([]float64{0.1}), collection.NewFromSlice([]float64{0.2}))
 
-		assert.NotNil(t, err)
+		assert.Nil(t, err)
+		assert.InDelta(t, 0.3, result.ToSlice()[0], 1e-9)
 	})
 }
 
-func TestRandom(t *testing.T) {
-	t.Run("RandomElement", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{1, 2, 3, 4, 5})
-		result, ok := c.Random()
+func TestSubElementwise(t *testing.T) {
+	t.Run("Subtracts", func(t *testing.T) {
+		result, err := collection.SubElementwise(collection.NewFromSlice([]float64{5, 2.5}), collection.NewFromSlice([]float64{1, 0.5}))
 
-		assert.True(t, ok)
-		assert.Contains(t, []int{1, 2, 3, 4, 5}, result)
+		assert.Nil(t, err)
+		assert.Equal(t, []float64{4, 2}, result.ToSlice())
 	})
 
-	t.Run("EmptyCollection", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{})
-		result, ok := c.Random()
+	t.Run("LengthMismatch", func(t *testing.T) {
+		_, err := collection.SubElementwise(collection.NewFromSlice([]int{1}), collection.NewFromSlice([]int{}))
 
-		assert.False(t, ok)
-		assert.Equal(t, 0, result)
+		assert.ErrorIs(t, err, collection.ErrLengthMismatch)
 	})
 }
 
-func TestRandomN(t *testing.T) {
-	t.Run("RandomElements", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{1, 2, 3, 4, 5})
-		result, ok := c.RandomN(3)
+func TestMulElementwise(t *testing.T) {
+	t.Run("Multiplies", func(t *testing.T) {
+		result, err := collection.MulElementwise(collection.NewFromSlice([]int{1, 2, 3}), collection.NewFromSlice([]int{4, 5, 6}))
 
-		assert.True(t, ok)
-		assert.Equal(t, 3, len(result))
+		assert.Nil(t, err)
+		assert.Equal(t, []int{4, 10, 18}, result.ToSlice())
 	})
 
-	t.Run("NLessThan1", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{1, 2})
-		result, ok := c.RandomN(0)
+	t.Run("LengthMismatch", func(t *testing.T) {
+		_, err := collection.MulElementwise(collection.NewFromSlice([]int{}), collection.NewFromSlice([]int{1}))
 
-		assert.False(t, ok)
-		assert.Len(t, result, 0)
+		assert.ErrorIs(t, err, collection.ErrLengthMismatch)
 	})
+}
 
-	t.Run("MoreThanAvailable", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{1, 2})
-		result, ok := c.RandomN(5)
+func TestScale(t *testing.T) {
+	t.Run("Scales", func(t *testing.T) {
+		result := collection.Scale(collection.NewFromSlice([]float64{1, 2.5, -3}), 2)
 
-		assert.False(t, ok)
-		assert.Len(t, result, 0)
+		assert.Equal(t, []float64{2, 5, -6}, result.ToSlice())
 	})
 
 	t.Run("EmptyCollection", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{})
-		result, ok := c.RandomN(3)
-
-		assert.False(t, ok)
-		assert.Len(t, result, 0)
+		assert.True(t, collection.Scale(collection.NewFromSlice([]int{}), 2).IsEmpty())
 	})
 }
 
-func TestIndexOf(t *testing.T) {
-	t.Run("ElementFound", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{10, 20, 30, 40})
-		index := c.IndexOf(func(x int) bool {
-			return x == 30
-		})
+func TestMovingAverage(t *testing.T) {
+	t.Run("Ints", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4, 5, 6})
 
-		assert.Equal(t, 2, index)
+		assert.Equal(t, []float64{2, 3, 4, 5}, collection.MovingAverage(c, 3).ToSlice())
 	})
 
-	t.Run("ElementNotFound", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{10, 20, 30, 40})
-		index := c.IndexOf(func(x int) bool {
-			return x == 50
-		})
+	t.Run("Floats", func(t *testing.T) {
+		c := collection.NewFromSlice([]float64{1.5, 2.5, 4, 0})
 
-		assert.Equal(t, -1, index)
+		result := collection.MovingAverage(c, 2).ToSlice()
+
+		assert.Len(t, result, 3)
+		assert.InDelta(t, 2.0, result[0], 1e-9)
+		assert.InDelta(t, 3.25, result[1], 1e-9)
+		assert.InDelta(t, 2.0, result[2], 1e-9)
 	})
 
-	t.Run("EmptyCollection", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{})
-		index := c.IndexOf(func(x int) bool {
-			return x == 10
-		})
+	t.Run("WindowOfOne", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{4, 8})
 
-		assert.Equal(t, -1, index)
+		assert.Equal(t, []float64{4, 8}, collection.MovingAverage(c, 1).ToSlice())
 	})
 
-	t.Run("FirstElement", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{10, 20, 30, 40})
-		index := c.IndexOf(func(x int) bool {
-			return x == 10
-		})
+	t.Run("WindowLargerThanCollection", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2})
 
-		assert.Equal(t, 0, index)
+		assert.True(t, collection.MovingAverage(c, 3).IsEmpty())
 	})
 
-	t.Run("LastElement", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{10, 20, 30, 40})
-		index := c.IndexOf(func(x int) bool {
-			return x == 40
-		})
+	t.Run("Break", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4})
 
-		assert.Equal(t, 3, index)
+		for range *collection.MovingAverage(c, 2) {
+			break
+		}
 	})
-}
-
-func TestPartition(t *testing.T) {
-	t.Run("Partitions", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
 
-		even, odd := c.Partition(func(x int) bool {
-			return x%2 == 0
-		})
+	t.Run("InvalidWindow", func(t *testing.T) {
+		assert.Panics(t, func() { collection.MovingAverage(collection.NewFromSlice([]int{1}), 0) })
+	})
+}
 
-		assert.Equal(t, 5, len(even.ToSlice()))
-		assert.Equal(t, 5, len(odd.ToSlice()))
+func TestEnumerate(t *testing.T) {
+	t.Run("Logic", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"a", "b", "c"})
 
-		assert.Contains(t, even.ToSlice(), 2)
-		assert.Contains(t, even.ToSlice(), 4)
-		assert.Contains(t, even.ToSlice(), 6)
-		assert.Contains(t, even.ToSlice(), 8)
-		assert.Contains(t, even.ToSlice(), 10)
+		var indexes []int
+		var values []string
+		for i, v := range c.Enumerate() {
+			indexes = append(indexes, i)
+			values = append(values, v)
+		}
 
-		assert.Contains(t, odd.ToSlice(), 1)
-		assert.Contains(t, odd.ToSlice(), 3)
-		assert.Contains(t, odd.ToSlice(), 5)
-		assert.Contains(t, odd.ToSlice(), 7)
-		assert.Contains(t, odd.ToSlice(), 9)
+		assert.Equal(t, []int{0, 1, 2}, indexes)
+		assert.Equal(t, []string{"a", "b", "c"}, values)
 	})
 
-	t.Run("BreakEven", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
-
-		even, _ := c.Partition(func(x int) bool {
-			return x%2 == 0
-		})
+	t.Run("Break", func(t *testing.T) {
+		ch := make(chan string, 3)
+		for _, v := range []string{"a", "b", "c"} {
+			ch <- v
+		}
+		close(ch)
 
-		for range *even {
+		for i, v := range collection.NewFromChannel(ch).Enumerate() {
+			assert.Equal(t, 0, i)
+			assert.Equal(t, "a", v)
 			break
 		}
-	})
 
-	t.Run("BreakOdd", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+		assert.Equal(t, 2, len(ch))
+	})
 
-		_, odd := c.Partition(func(x int) bool {
-			return x%2 != 0
-		})
+	t.Run("EmptyCollection", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{})
 
-		for range *odd {
-			break
+		for range c.Enumerate() {
+			assert.Fail(t, "This should not be called")
 		}
 	})
 }
 
-func TestJoin(t *testing.T) {
-	type testPerson struct {
-		ID   int
+func TestToSlice(t *testing.T) {
+	c := collection.NewFromSlice([]string{"a", "b", "c"})
+	v := c.ToSlice()
+
+	assert.Equal(t, []string{"a", "b", "c"}, v)
+}
+
+func TestCollectionToMap(t *testing.T) {
+	type person struct {
 		Name string
 		Age  int
 	}
+	c := collection.NewFromSlice([]person{
+		{Name: "Alice", Age: 25},
+		{Name: "Bob", Age: 30},
+		{Name: "Charlie", Age: 35},
+	})
+	v := c.ToMap(func(x person) any {
+		return x.Name
+	})
+	s := c.ToSlice()
 
-	type testPet struct {
-		OwnerID int
-		Name    string
-		Species string
+	assert.Equal(t, s[0], v["Alice"])
+	assert.Equal(t, s[1], v["Bob"])
+	assert.Equal(t, s[2], v["Charlie"])
+}
+
+func TestToChannel(t *testing.T) {
+	c := collection.NewFromSlice([]string{"a", "b", "c"})
+	ch := c.ToChannel()
+
+	var results []string
+	for v := range ch {
+		results = append(results, v)
 	}
 
-	t.Run("Joins", func(t *testing.T) {
+	assert.Equal(t, []string{"a", "b", "c"}, results)
+}
 
-		// Setup
-		people := collection.NewFromSlice([]testPerson{
-			{1, "Alice", 30},
-			{2, "Bob", 25},
-			{3, "Charlie", 35},
-		})
+func TestToChannelCtx(t *testing.T) {
+	t.Run("Logic", func(t *testing.T) {
+		ch := collection.NewFromSlice([]string{"a", "b", "c"}).ToChannelCtx(context.Background(), 2)
 
-		pets := collection.NewFromSlice([]testPet{
-			{1, "Fluffy", "Cat"},
-			{2, "Rex", "Dog"},
-			{1, "Whiskers", "Cat"},
-			{4, "Spike", "Dog"},
-		})
+		var results []string
+		for v := range ch {
+			results = append(results, v)
+		}
 
-		// Perform join
-		result := collection.Join(
-			people,
-			pets,
-			func(p testPerson) int { return p.ID },
-			func(pet testPet) int { return pet.OwnerID },
-			func(p testPerson, pet testPet) string {
-				return fmt.Sprintf("%s owns %s", p.Name, pet.Name)
-			},
-		).ToSlice()
+		assert.Equal(t, 2, cap(ch))
+		assert.Equal(t, []string{"a", "b", "c"}, results)
+	})
 
-		// Verify results
-		expected := []string{
-			"Alice owns Fluffy",
-			"Alice owns Whiskers",
-			"Bob owns Rex",
+	t.Run("Cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		source := collection.New[int](iter.Seq[int](func(yield func(int) bool) {
+			defer close(done)
+			for i := range 10 {
+				if !yield(i) {
+					return
+				}
+			}
+		}))
+
+		ch := source.ToChannelCtx(ctx, 0)
+		assert.Equal(t, 0, <-ch)
+		assert.Equal(t, 1, <-ch)
+		cancel()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			assert.Fail(t, "producer did not exit")
+		}
+		for range ch {
 		}
+	})
+}
 
-		if len(result) != len(expected) {
-			t.Errorf("Expected %d results, got %d", len(expected), len(result))
+func TestToChannelStop(t *testing.T) {
+	t.Run("Logic", func(t *testing.T) {
+		ch, stop := collection.NewFromSlice([]string{"a", "b", "c"}).ToChannelStop()
+		defer stop()
+
+		var results []string
+		for v := range ch {
+			results = append(results, v)
 		}
 
-		// Check that all expected results are present
-		for _, exp := range expected {
-			found := false
-			for _, res := range result {
-				if res == exp {
-					found = true
-					break
+		assert.Equal(t, []string{"a", "b", "c"}, results)
+	})
+
+	t.Run("StopsSource", func(t *testing.T) {
+		var pulled atomic.Int64
+		c := collection.NewFromRange(0, 1000).Peek(func(int) { pulled.Add(1) })
+
+		ch, stop := c.ToChannelStop()
+		for range 3 {
+			<-ch
+		}
+		stop()
+		stop()
+
+		for range ch {
+		}
+		count := pulled.Load()
+		time.Sleep(10 * time.Millisecond)
+
+		assert.Equal(t, count, pulled.Load())
+		assert.Less(t, count, int64(1000))
+	})
+}
+
+func TestFanOut(t *testing.T) {
+	t.Run("ExactlyOnce", func(t *testing.T) {
+		outs := collection.NewFromRange(0, 1000).FanOut(context.Background(), 4, 2)
+
+		var mu sync.Mutex
+		var results []int
+		var wg sync.WaitGroup
+		for _, out := range outs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for v := range out {
+					mu.Lock()
+					results = append(results, v)
+					mu.Unlock()
 				}
-			}
-			if !found {
-				t.Errorf("Expected result not found: %s", exp)
-			}
+			}()
 		}
+		wg.Wait()
+
+		assert.Len(t, outs, 4)
+		assert.ElementsMatch(t, collection.NewFromRange(0, 1000).ToSlice(), results)
 	})
 
-	t.Run("Break", func(t *testing.T) {
-		people := collection.NewFromSlice([]testPerson{
-			{1, "Alice", 30},
-			{2, "Bob", 25},
-			{3, "Charlie", 35},
-		})
+	t.Run("SlowConsumer", func(t *testing.T) {
+		outs := collection.NewFromRange(0, 100).FanOut(context.Background(), 2, 0)
+
+		<-outs[0]
+		count := 1
+		for range outs[1] {
+			count++
+		}
+		for range outs[0] {
+			count++
+		}
+
+		assert.Equal(t, 100, count)
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var pulled atomic.Int64
+		c := collection.NewFromRange(0, 1000).Peek(func(int) { pulled.Add(1) })
+
+		outs := c.FanOut(ctx, 2, 0)
+		<-outs[0]
+		cancel()
+
+		for _, out := range outs {
+			for range out {
+			}
+		}
+		count := pulled.Load()
+		time.Sleep(10 * time.Millisecond)
 
-		pets := collection.NewFromSlice([]testPet{
-			{1, "Fluffy", "Cat"},
-			{2, "Rex", "Dog"},
-			{1, "Whiskers", "Cat"},
-			{4, "Spike", "Dog"},
-		})
+		assert.Equal(t, count, pulled.Load())
+		assert.Less(t, count, int64(1000))
+	})
 
-		for range *collection.Join(
-			people,
-			pets,
-			func(p testPerson) int { return p.ID },
-			func(pet testPet) int { return pet.OwnerID },
-			func(p testPerson, pet testPet) string {
-				return fmt.Sprintf("%s owns %s", p.Name, pet.Name)
-			},
-		) {
-			break
+	t.Run("Single", func(t *testing.T) {
+		outs := collection.NewFromSlice([]string{"a", "b", "c"}).FanOut(context.Background(), 1, 0)
+
+		var results []string
+		for v := range outs[0] {
+			results = append(results, v)
 		}
+
+		assert.Equal(t, []string{"a", "b", "c"}, results)
+	})
+
+	t.Run("InvalidCount", func(t *testing.T) {
+		assert.Panics(t, func() { collection.NewFromSlice([]int{1}).FanOut(context.Background(), 0, 0) })
 	})
 }
 
-func TestFlatten(t *testing.T) {
-	t.Run("FlattenNonEmpty", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]int{1, 2, 3})
-		c2 := collection.NewFromSlice([]int{4, 5, 6})
-		c3 := collection.NewFromSlice([]int{7, 8, 9})
+func TestToJSON(t *testing.T) {
+	c := collection.NewFromSlice([]string{"a", "b", "c"})
+	v, err := c.ToJSON()
 
-		collections := collection.NewFromSlice([]*collection.Collection[int]{c1, c2, c3})
-		result := collection.Flatten(collections).ToSlice()
+	assert.Nil(t, err)
+	assert.Equal(t, `["a","b","c"]`, string(v))
+}
 
-		assert.Equal(t, 9, len(result))
-		assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}, result)
+func TestWriteJSON(t *testing.T) {
+	t.Run("MatchesToJSON", func(t *testing.T) {
+		type item struct {
+			Name  string `json:"name"`
+			Count int    `json:"count"`
+		}
+		c := collection.NewFromSlice([]item{{"a", 1}, {"b", 2}, {"<c>", 3}})
+		var buf bytes.Buffer
+		err := c.WriteJSON(&buf)
+		expected, _ := c.ToJSON()
+
+		assert.NoError(t, err)
+		assert.JSONEq(t, string(expected), buf.String())
 	})
 
-	t.Run("FlattenEmpty", func(t *testing.T) {
-		collections := collection.NewFromSlice([]*collection.Collection[int]{})
-		result := collection.Flatten(collections).ToSlice()
+	t.Run("EmptyCollection", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := collection.NewFromSlice([]int{}).WriteJSON(&buf)
 
-		assert.Equal(t, 0, len(result))
+		assert.NoError(t, err)
+		assert.Equal(t, "[]", buf.String())
 	})
 
-	t.Run("FlattenWithEmptyCollections", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]int{})
-		c2 := collection.NewFromSlice([]int{4, 5, 6})
-		c3 := collection.NewFromSlice([]int{})
+	t.Run("RoundTrip", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := collection.NewFromRange(0, 1000).WriteJSON(&buf)
+		assert.NoError(t, err)
 
-		collections := collection.NewFromSlice([]*collection.Collection[int]{c1, c2, c3})
-		result := collection.Flatten(collections).ToSlice()
+		c := collection.NewFromJSONReader[int](&buf)
 
-		assert.Equal(t, 3, len(result))
-		assert.Equal(t, []int{4, 5, 6}, result)
+		assert.Equal(t, collection.NewFromRange(0, 1000).ToSlice(), c.ToSlice())
+		assert.NoError(t, c.Err())
 	})
 
-	t.Run("Break", func(t *testing.T) {
-		c1 := collection.NewFromSlice([]int{1, 2, 3})
-		c2 := collection.NewFromSlice([]int{4, 5, 6})
-		c3 := collection.NewFromSlice([]int{7, 8, 9})
+	t.Run("MarshalError", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := collection.NewFromSlice([]float64{1, math.Inf(1), 3}).WriteJSON(&buf)
 
-		collections := collection.NewFromSlice([]*collection.Collection[int]{c1, c2, c3})
-		for range *collection.Flatten(collections) {
-			break
-		}
+		assert.ErrorContains(t, err, "element 1")
+		assert.Equal(t, "[1\n,", buf.String())
 	})
-}
 
-func TestMode(t *testing.T) {
-	t.Run("SingleMode", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{1, 2, 2, 3, 4})
-		mode, err := collection.Mode(c)
+	t.Run("WriterError", func(t *testing.T) {
+		err := collection.NewFromSlice([]int{1, 2, 3}).WriteJSON(&failingWriter{n: 2})
 
-		assert.Nil(t, err)
-		assert.Equal(t, 2, mode)
+		assert.EqualError(t, err, "write failed")
 	})
+}
 
-	t.Run("MultipleModes_FirstEncountered", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{1, 2, 2, 3, 3})
-		mode, err := collection.Mode(c)
+func TestWriteNDJSON(t *testing.T) {
+	t.Run("OneElementPerLine", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := collection.NewFromSlice([]string{"a", "b"}).WriteNDJSON(&buf)
 
-		assert.Nil(t, err)
-		assert.Equal(t, 2, mode) // First encountered mode
+		assert.NoError(t, err)
+		assert.Equal(t, "\"a\"\n\"b\"\n", buf.String())
 	})
 
-	t.Run("AllUnique", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{1, 2, 3, 4, 5})
-		mode, err := collection.Mode(c)
+	t.Run("RoundTrip", func(t *testing.T) {
+		type event struct {
+			ID   int      `json:"id"`
+			Tags []string `json:"tags"`
+		}
+		events := []event{{1, []string{"x"}}, {2, nil}, {3, []string{"y", "z"}}}
+		var buf bytes.Buffer
+		err := collection.NewFromSlice(events).WriteNDJSON(&buf)
+		assert.NoError(t, err)
 
-		assert.Nil(t, err)
-		assert.Equal(t, 1, mode) // First element is returned
+		c := collection.NewFromNDJSON[event](&buf)
+
+		assert.Equal(t, events, c.ToSlice())
+		assert.NoError(t, c.Err())
 	})
 
 	t.Run("EmptyCollection", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{})
-		_, err := collection.Mode(c)
+		var buf bytes.Buffer
+		err := collection.NewFromSlice([]int{}).WriteNDJSON(&buf)
 
-		assert.NotNil(t, err)
-		assert.Equal(t, collection.ErrEmptyCollection, err)
+		assert.NoError(t, err)
+		assert.Empty(t, buf.String())
 	})
 
-	t.Run("StringElements", func(t *testing.T) {
-		c := collection.NewFromSlice([]string{"apple", "banana", "apple", "cherry", "banana", "banana"})
-		mode, err := collection.Mode(c)
+	t.Run("MarshalError", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := collection.NewFromSlice([]float64{1, math.NaN()}).WriteNDJSON(&buf)
 
-		assert.Nil(t, err)
-		assert.Equal(t, "banana", mode)
+		assert.ErrorContains(t, err, "element 1")
+		assert.Equal(t, "1\n", buf.String())
+	})
+
+	t.Run("WriterError", func(t *testing.T) {
+		err := collection.NewFromSlice([]int{1, 2, 3}).WriteNDJSON(&failingWriter{n: 1})
+
+		assert.EqualError(t, err, "write failed")
 	})
 }
 
-func TestAverageOrError(t *testing.T) {
-	t.Run("Empty_Error", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{})
-		_, err := collection.AverageOrError(c)
-		assert.NotNil(t, err)
+func TestEncodeGob(t *testing.T) {
+	type record struct {
+		ID   int
+		Name string
+		Tags []string
+	}
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		records := []record{{1, "a", []string{"x"}}, {2, "b", nil}, {3, "c", []string{"y", "z"}}}
+		var buf bytes.Buffer
+		err := collection.NewFromSlice(records).EncodeGob(&buf)
+		assert.NoError(t, err)
+
+		c := collection.NewFromGob[record](&buf)
+
+		assert.Equal(t, records, c.ToSlice())
+		assert.NoError(t, c.Err())
 	})
 
-	t.Run("Uint", func(t *testing.T) {
-		c := collection.NewFromSlice([]uint{1, 2, 3})
-		v, err := collection.AverageOrError(c)
-		f, _ := v.Float64()
+	t.Run("EnumeratedTwice", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := collection.NewFromSlice([]int{1, 2, 3}).EncodeGob(&buf)
+		assert.NoError(t, err)
 
-		assert.Nil(t, err)
-		assert.Equal(t, 2.0, f)
+		c := collection.NewFromGob[int](&buf)
+
+		assert.Equal(t, 3, c.Count())
+		assert.NoError(t, c.Err())
+
+		assert.Empty(t, c.ToSlice())
+		assert.ErrorIs(t, c.Err(), collection.ErrConsumed)
 	})
 
-	t.Run("Uint8", func(t *testing.T) {
-		c := collection.NewFromSlice([]uint8{1, 2, 3})
-		v, err := collection.AverageOrError(c)
-		f, _ := v.Float64()
+	t.Run("EmptyCollection", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := collection.NewFromSlice([]record{}).EncodeGob(&buf)
+		assert.NoError(t, err)
 
-		assert.Nil(t, err)
-		assert.Equal(t, 2.0, f)
+		c := collection.NewFromGob[record](&buf)
+
+		assert.True(t, c.IsEmpty())
+		assert.NoError(t, c.Err())
 	})
 
-	t.Run("Uint16", func(t *testing.T) {
-		c := collection.NewFromSlice([]uint16{1, 2, 3})
-		v, err := collection.AverageOrError(c)
-		f, _ := v.Float64()
+	t.Run("TruncatedStream", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := collection.NewFromRange(0, 10).EncodeGob(&buf)
+		assert.NoError(t, err)
 
-		assert.Nil(t, err)
-		assert.Equal(t, 2.0, f)
+		data := buf.Bytes()
+		c := collection.NewFromGob[int](bytes.NewReader(data[:len(data)/2]))
+		result := c.ToSlice()
+
+		assert.Less(t, len(result), 10)
+		assert.ErrorIs(t, c.Err(), io.ErrUnexpectedEOF)
+		assert.ErrorContains(t, c.Err(), fmt.Sprintf("element %d", len(result)))
 	})
 
-	t.Run("Uint32", func(t *testing.T) {
-		c := collection.NewFromSlice([]uint32{1, 2, 3})
-		v, err := collection.AverageOrError(c)
-		f, _ := v.Float64()
+	t.Run("MissingEndMarker", func(t *testing.T) {
+		c := collection.NewFromGob[int](bytes.NewReader(nil))
 
-		assert.Nil(t, err)
-		assert.Equal(t, 2.0, f)
+		assert.True(t, c.IsEmpty())
+		assert.ErrorIs(t, c.Err(), io.ErrUnexpectedEOF)
 	})
 
-	t.Run("Uint64", func(t *testing.T) {
-		c := collection.NewFromSlice([]uint64{1, 2, 3})
-		v, err := collection.AverageOrError(c)
-		f, _ := v.Float64()
+	t.Run("EarlyBreak", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := collection.NewFromRange(0, 100).EncodeGob(&buf)
+		assert.NoError(t, err)
 
-		assert.Nil(t, err)
-		assert.Equal(t, 2.0, f)
+		c := collection.NewFromGob[int](&buf)
+
+		assert.Equal(t, []int{0, 1, 2}, c.Take(3).ToSlice())
+		assert.NoError(t, c.Err())
 	})
 
-	t.Run("Int", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{1, 2, 3})
-		v, err := collection.AverageOrError(c)
-		f, _ := v.Float64()
+	t.Run("WriterError", func(t *testing.T) {
+		err := collection.NewFromSlice([]int{1, 2, 3}).EncodeGob(&failingWriter{n: 2})
 
-		assert.Nil(t, err)
-		assert.Equal(t, 2.0, f)
+		assert.EqualError(t, err, "write failed")
 	})
+}
 
-	t.Run("Int8", func(t *testing.T) {
-		c := collection.NewFromSlice([]int8{1, 2, 3})
-		v, err := collection.AverageOrError(c)
-		f, _ := v.Float64()
+func TestGobEncode(t *testing.T) {
+	t.Run("TopLevel", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := gob.NewEncoder(&buf).Encode(collection.NewFromSlice([]int{1, 2, 3}))
+		assert.NoError(t, err)
 
-		assert.Nil(t, err)
-		assert.Equal(t, 2.0, f)
+		var decoded collection.Collection[int]
+		err = gob.NewDecoder(&buf).Decode(&decoded)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3}, decoded.ToSlice())
 	})
 
-	t.Run("Int16", func(t *testing.T) {
-		c := collection.NewFromSlice([]int16{1, 2, 3})
-		v, err := collection.AverageOrError(c)
-		f, _ := v.Float64()
+	t.Run("MapValues", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := gob.NewEncoder(&buf).Encode(map[string]*collection.Collection[string]{
+			"a": collection.NewFromSlice([]string{"x", "y"}),
+			"b": collection.NewFromSlice([]string{"z"}),
+		})
+		assert.NoError(t, err)
 
-		assert.Nil(t, err)
-		assert.Equal(t, 2.0, f)
+		var decoded map[string]*collection.Collection[string]
+		err = gob.NewDecoder(&buf).Decode(&decoded)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"x", "y"}, decoded["a"].ToSlice())
+		assert.Equal(t, []string{"z"}, decoded["b"].ToSlice())
 	})
 
-	t.Run("Int32", func(t *testing.T) {
-		c := collection.NewFromSlice([]int32{1, 2, 3})
-		v, err := collection.AverageOrError(c)
-		f, _ := v.Float64()
+	t.Run("StructField", func(t *testing.T) {
+		type cached struct {
+			Name  string
+			Items collection.GobCollection[int]
+			None  collection.GobCollection[int]
+		}
 
-		assert.Nil(t, err)
-		assert.Equal(t, 2.0, f)
+		var buf bytes.Buffer
+		err := gob.NewEncoder(&buf).Encode(cached{Name: "evens", Items: collection.GobCollection[int]{Collection: collection.NewFromSlice([]int{2, 4, 6})}})
+		assert.NoError(t, err)
+
+		var decoded cached
+		err = gob.NewDecoder(&buf).Decode(&decoded)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "evens", decoded.Name)
+		assert.Equal(t, []int{2, 4, 6}, decoded.Items.ToSlice())
+		assert.Nil(t, decoded.None.Collection)
 	})
 
-	t.Run("Int64", func(t *testing.T) {
-		c := collection.NewFromSlice([]int64{1, 2, 3})
-		v, err := collection.AverageOrError(c)
-		f, _ := v.Float64()
+	t.Run("EmptyCollection", func(t *testing.T) {
+		data, err := collection.NewFromSlice([]int{}).GobEncode()
+		assert.NoError(t, err)
 
-		assert.Nil(t, err)
-		assert.Equal(t, 2.0, f)
+		c := collection.NewFromSlice([]int{9})
+		err = c.GobDecode(data)
+
+		assert.NoError(t, err)
+		assert.True(t, c.IsEmpty())
 	})
 
-	t.Run("Float32", func(t *testing.T) {
-		c := collection.NewFromSlice([]float32{1.0, 2.0, 3.0})
-		v, err := collection.AverageOrError(c)
-		f, _ := v.Float64()
+	t.Run("InvalidData", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{9})
+		err := c.GobDecode([]byte("not gob"))
 
-		assert.Nil(t, err)
-		assert.Equal(t, 2.0, f)
+		assert.Error(t, err)
+		assert.Equal(t, []int{9}, c.ToSlice())
 	})
 
-	t.Run("Float64", func(t *testing.T) {
-		c := collection.NewFromSlice([]float64{1.0, 2.0, 3.0})
-		v, err := collection.AverageOrError(c)
-		f, _ := v.Float64()
+	t.Run("StructFieldDropped", func(t *testing.T) {
+		type holder struct {
+			Name  string
+			Items *collection.Collection[int]
+		}
+		var buf bytes.Buffer
+		err := gob.NewEncoder(&buf).Encode(holder{Name: "a", Items: collection.NewFromSlice([]int{1, 2, 3})})
+		assert.NoError(t, err)
 
-		assert.Nil(t, err)
-		assert.Equal(t, 2.0, f)
+		var decoded holder
+		err = gob.NewDecoder(&buf).Decode(&decoded)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "a", decoded.Name)
+		assert.Nil(t, decoded.Items)
 	})
 }
 
-func TestSum(t *testing.T) {
-	t.Run("Uint", func(t *testing.T) {
-		c := collection.NewFromSlice([]uint{1, 2, 3})
-		v := collection.Sum(c)
-		f, _ := v.Float64()
+func TestSnapshot(t *testing.T) {
+	encode := func(x string) ([]byte, error) { return []byte(x), nil }
+	decode := func(b []byte) (string, error) { return string(b), nil }
 
-		assert.Equal(t, 6.0, f)
-	})
+	t.Run("RoundTrip", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := collection.NewFromSlice([]string{"a", "", "ccc"}).Snapshot(&buf, encode)
+		assert.Nil(t, err)
 
-	t.Run("Uint8", func(t *testing.T) {
-		c := collection.NewFromSlice([]uint8{1, 2, 3})
-		v := collection.Sum(c)
-		f, _ := v.Float64()
+		c, err := collection.RestoreSnapshot(&buf, decode)
+		assert.Nil(t, err)
 
-		assert.Equal(t, 6.0, f)
+		assert.Equal(t, []string{"a", "", "ccc"}, c.ToSlice())
+		assert.Nil(t, c.Err())
 	})
 
-	t.Run("Uint16", func(t *testing.T) {
-		c := collection.NewFromSlice([]uint16{1, 2, 3})
-		v := collection.Sum(c)
-		f, _ := v.Float64()
+	t.Run("ErrDuringEnumeration", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := collection.NewFromSlice([]string{"a", "b", "c"}).Snapshot(&buf, encode)
+		assert.Nil(t, err)
 
-		assert.Equal(t, 6.0, f)
+		c, err := collection.RestoreSnapshot(&buf, decode)
+		assert.Nil(t, err)
+
+		var wg sync.WaitGroup
+		for range *c.Collection {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.Nil(t, c.Err())
+			}()
+		}
+		wg.Wait()
+
+		assert.Nil(t, c.Err())
 	})
 
-	t.Run("Uint32", func(t *testing.T) {
-		c := collection.NewFromSlice([]uint32{1, 2, 3})
-		v := collection.Sum(c)
-		f, _ := v.Float64()
+	t.Run("EnumeratedTwice", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := collection.NewFromSlice([]string{"a", "b", "c"}).Snapshot(&buf, encode)
+		assert.Nil(t, err)
 
-		assert.Equal(t, 6.0, f)
+		c, err := collection.RestoreSnapshot(&buf, decode)
+		assert.Nil(t, err)
+
+		assert.Equal(t, 3, c.Count())
+		assert.Nil(t, c.Err())
+
+		assert.Empty(t, c.ToSlice())
+		assert.ErrorIs(t, c.Err(), collection.ErrConsumed)
+		assert.NotErrorIs(t, c.Err(), collection.ErrCorruptSnapshot)
 	})
 
-	t.Run("Uint64", func(t *testing.T) {
-		c := collection.NewFromSlice([]uint64{1, 2, 3})
-		v := collection.Sum(c)
-		f, _ := v.Float64()
+	t.Run("EnumeratedAgainAfterBreak", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := collection.NewFromSlice([]string{"a", "b", "c"}).Snapshot(&buf, encode)
+		assert.Nil(t, err)
 
-		assert.Equal(t, 6.0, f)
+		c, err := collection.RestoreSnapshot(&buf, decode)
+		assert.Nil(t, err)
+
+		v, ok := c.First()
+		assert.True(t, ok)
+		assert.Equal(t, "a", v)
+
+		assert.Empty(t, c.ToSlice())
+		assert.ErrorIs(t, c.Err(), collection.ErrConsumed)
 	})
 
-	t.Run("Int", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{1, 2, 3})
-		v := collection.Sum(c)
-		f, _ := v.Float64()
+	t.Run("EmptyCollection", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := collection.NewFromSlice([]string{}).Snapshot(&buf, encode)
+		assert.Nil(t, err)
 
-		assert.Equal(t, 6.0, f)
+		c, err := collection.RestoreSnapshot(&buf, decode)
+		assert.Nil(t, err)
+
+		assert.True(t, c.IsEmpty())
+		assert.Nil(t, c.Err())
 	})
 
-	t.Run("Int8", func(t *testing.T) {
-		c := collection.NewFromSlice([]int8{1, 2, 3})
-		v := collection.Sum(c)
-		f, _ := v.Float64()
+	t.Run("EncodeError", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := collection.NewFromSlice([]string{"a", "b"}).Snapshot(&buf, func(x string) ([]byte, error) {
+			if x == "b" {
+				return nil, errors.New("encode failed")
+			}
+			return []byte(x), nil
+		})
 
-		assert.Equal(t, 6.0, f)
+		assert.ErrorContains(t, err, "element 1")
 	})
 
-	t.Run("Int16", func(t *testing.T) {
-		c := collection.NewFromSlice([]int16{1, 2, 3})
-		v := collection.Sum(c)
-		f, _ := v.Float64()
+	t.Run("TruncatedStream", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := collection.NewFromSlice([]string{"aaaa", "bbbb"}).Snapshot(&buf, encode)
+		assert.Nil(t, err)
 
-		assert.Equal(t, 6.0, f)
+		// Header (14) + first frame (8) + second length (4) + 2 bytes of the second element
+		truncated := bytes.NewReader(buf.Bytes()[:28])
+		c, err := collection.RestoreSnapshot(truncated, decode)
+		assert.Nil(t, err)
+
+		assert.Equal(t, []string{"aaaa"}, c.ToSlice())
+		assert.ErrorIs(t, c.Err(), collection.ErrCorruptSnapshot)
+		assert.ErrorContains(t, c.Err(), "truncated element 1 at offset 28")
 	})
 
-	t.Run("Int32", func(t *testing.T) {
-		c := collection.NewFromSlice([]int32{1, 2, 3})
-		v := collection.Sum(c)
-		f, _ := v.Float64()
+	t.Run("TruncatedHeader", func(t *testing.T) {
+		c, err := collection.RestoreSnapshot(bytes.NewReader([]byte("GCOL")), decode)
 
-		assert.Equal(t, 6.0, f)
+		assert.ErrorIs(t, err, collection.ErrCorruptSnapshot)
+		assert.Nil(t, c)
 	})
 
-	t.Run("Int64", func(t *testing.T) {
-		c := collection.NewFromSlice([]int64{1, 2, 3})
-		v := collection.Sum(c)
-		f, _ := v.Float64()
+	t.Run("InvalidMagic", func(t *testing.T) {
+		c, err := collection.RestoreSnapshot(bytes.NewReader([]byte("XXXX\x00\x01\x00\x00\x00\x00\x00\x00\x00\x00")), decode)
 
-		assert.Equal(t, 6.0, f)
+		assert.ErrorIs(t, err, collection.ErrCorruptSnapshot)
+		assert.Nil(t, c)
 	})
 
-	t.Run("Float32", func(t *testing.T) {
-		c := collection.NewFromSlice([]float32{1.0, 2.0, 3.0})
-		v := collection.Sum(c)
-		f, _ := v.Float64()
+	t.Run("UnknownVersion", func(t *testing.T) {
+		c, err := collection.RestoreSnapshot(bytes.NewReader([]byte("GCOL\x00\x02\x00\x00\x00\x00\x00\x00\x00\x00")), decode)
 
-		assert.Equal(t, 6.0, f)
+		assert.ErrorIs(t, err, collection.ErrUnsupportedSnapshotVersion)
+		assert.Nil(t, c)
 	})
 
-	t.Run("Float64", func(t *testing.T) {
-		c := collection.NewFromSlice([]float64{1.0, 2.0, 3.0})
-		v := collection.Sum(c)
-		f, _ := v.Float64()
+	t.Run("DecodeError", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := collection.NewFromSlice([]string{"a", "b"}).Snapshot(&buf, encode)
+		assert.Nil(t, err)
 
-		assert.Equal(t, 6.0, f)
+		c, err := collection.RestoreSnapshot(&buf, func(b []byte) (string, error) {
+			if string(b) == "b" {
+				return "", errors.New("decode failed")
+			}
+			return string(b), nil
+		})
+		assert.Nil(t, err)
+
+		assert.Equal(t, []string{"a"}, c.ToSlice())
+		assert.ErrorContains(t, c.Err(), "element 1 at offset 23")
 	})
 }
 
-func TestMin(t *testing.T) {
-	t.Run("Positive", func(t *testing.T) {
+// failingWriter fails every write after the first n
+type failingWriter struct {
+	n       int
+	written int
+}
 
-		c := collection.NewFromSlice([]int{1, 2, 3})
-		v := collection.Min(c)
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.written >= w.n {
+		return 0, errors.New("write failed")
+	}
+	w.written++
+	return len(p), nil
+}
 
-		assert.Equal(t, 1, v)
+func TestToWriter(t *testing.T) {
+	render := func(x int) ([]byte, error) {
+		return []byte(strconv.Itoa(x) + "\n"), nil
+	}
+
+	t.Run("WritesInOrder", func(t *testing.T) {
+		var buf bytes.Buffer
+		n, err := collection.NewFromRange(8, 4).ToWriter(&buf, render)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "8\n9\n10\n11\n", buf.String())
+		assert.Equal(t, int64(buf.Len()), n)
 	})
 
-	t.Run("Negative", func(t *testing.T) {
+	t.Run("EmptyCollection", func(t *testing.T) {
+		var buf bytes.Buffer
+		n, err := collection.NewFromSlice([]int{}).ToWriter(&buf, render)
 
-		c := collection.NewFromSlice([]int{-1, -2, -3})
-		v := collection.Min(c)
+		assert.NoError(t, err)
+		assert.Zero(t, n)
+		assert.Empty(t, buf.String())
+	})
 
-		assert.Equal(t, -3, v)
+	t.Run("RenderError", func(t *testing.T) {
+		var buf bytes.Buffer
+		n, err := collection.NewFromRange(0, 5).ToWriter(&buf, func(x int) ([]byte, error) {
+			if x == 3 {
+				return nil, errors.New("render failed")
+			}
+			return render(x)
+		})
+
+		assert.EqualError(t, err, "failed to render element 3: render failed")
+		assert.Equal(t, "0\n1\n2\n", buf.String())
+		assert.Equal(t, int64(6), n)
 	})
-}
 
-func TestMax(t *testing.T) {
-	t.Run("Positive", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{1, 2, 3})
-		v := collection.Max(c)
+	t.Run("WriteError", func(t *testing.T) {
+		n, err := collection.NewFromRange(0, 5).ToWriter(&failingWriter{n: 2}, render)
 
-		assert.Equal(t, 3, v)
+		assert.EqualError(t, err, "failed to write element 2: write failed")
+		assert.Equal(t, int64(4), n)
 	})
 
-	t.Run("Negative", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{-1, -2, -3})
-		v := collection.Max(c)
+	t.Run("ShortWriteCounted", func(t *testing.T) {
+		w := writerFunc(func(p []byte) (int, error) {
+			return 1, io.ErrShortWrite
+		})
+		n, err := collection.NewFromSlice([]int{10}).ToWriter(w, render)
 
-		assert.Equal(t, -1, v)
+		assert.ErrorIs(t, err, io.ErrShortWrite)
+		assert.Equal(t, int64(1), n)
 	})
 }
 
-func TestMedian(t *testing.T) {
-	t.Run("OddNumberOfElements", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{3, 1, 4, 2, 5})
-		median, err := collection.Median(c)
-		f, _ := median.Float64()
+func TestToWriterParallel(t *testing.T) {
+	render := func(x int) ([]byte, error) {
+		time.Sleep(time.Duration(x%5) * time.Millisecond)
+		return []byte(strconv.Itoa(x) + "\n"), nil
+	}
 
-		assert.Nil(t, err)
-		assert.Equal(t, 3.0, f)
-	})
+	t.Run("MatchesSequential", func(t *testing.T) {
+		c := collection.NewFromRange(0, 200)
 
-	t.Run("EvenNumberOfElements", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{3, 1, 4, 2})
-		median, err := collection.Median(c)
-		f, _ := median.Float64()
+		var expected bytes.Buffer
+		for v := range *c {
+			b, _ := render(v)
+			expected.Write(b)
+		}
+
+		var actual bytes.Buffer
+		err := c.ToWriterParallel(context.Background(), &actual, render, 8)
 
 		assert.Nil(t, err)
-		assert.Equal(t, 2.5, f)
+		assert.Equal(t, expected.String(), actual.String())
 	})
 
-	t.Run("SingleElement", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{42})
-		median, err := collection.Median(c)
-		f, _ := median.Float64()
+	t.Run("RenderError", func(t *testing.T) {
+		c := collection.NewFromRange(0, 100)
 
-		assert.Nil(t, err)
-		assert.Equal(t, 42.0, f)
+		var buf bytes.Buffer
+		err := c.ToWriterParallel(context.Background(), &buf, func(x int) ([]byte, error) {
+			if x == 10 {
+				return nil, errors.New("render failed")
+			}
+			return render(x)
+		}, 4)
+
+		assert.ErrorContains(t, err, "failed to render element 10")
+		assert.True(t, strings.HasSuffix(buf.String(), "\n9\n"))
 	})
 
-	t.Run("EmptyCollection", func(t *testing.T) {
-		c := collection.NewFromSlice([]int{})
-		_, err := collection.Median(c)
+	t.Run("WriteErrorAbortsWorkers", func(t *testing.T) {
+		c := collection.NewFromRange(0, 1000)
 
-		assert.NotNil(t, err)
-		assert.Equal(t, "cannot compute median of empty collection", err.Error())
+		var renders atomic.Int32
+		err := c.ToWriterParallel(context.Background(), &failingWriter{n: 3}, func(x int) ([]byte, error) {
+			renders.Add(1)
+			return render(x)
+		}, 4)
+
+		assert.ErrorContains(t, err, "failed to write element 3")
+		assert.Less(t, int(renders.Load()), 50)
 	})
 
-	t.Run("FloatElements", func(t *testing.T) {
-		c := collection.NewFromSlice([]float64{3.5, 1.2, 4.8, 2.1})
-		median, err := collection.Median(c)
-		f, _ := median.Float64()
+	t.Run("BoundedBuffer", func(t *testing.T) {
+		c := collection.NewFromRange(0, 200)
+		workers := 4
+
+		var rendered, written, peak atomic.Int32
+		w := writerFunc(func(p []byte) (int, error) {
+			// Slow writer, so rendering would race ahead if unbounded
+			time.Sleep(time.Millisecond)
+			written.Add(1)
+			return len(p), nil
+		})
+
+		err := c.ToWriterParallel(context.Background(), w, func(x int) ([]byte, error) {
+			buffered := rendered.Add(1) - written.Load()
+			for {
+				current := peak.Load()
+				if buffered <= current || peak.CompareAndSwap(current, buffered) {
+					break
+				}
+			}
+			return []byte{byte(x)}, nil
+		}, workers)
 
 		assert.Nil(t, err)
-		assert.Equal(t, 2.8, f)
+		assert.LessOrEqual(t, int(peak.Load()), 2*workers+1)
 	})
-}
 
-func TestToSlice(t *testing.T) {
-	c := collection.NewFromSlice([]string{"a", "b", "c"})
-	v := c.ToSlice()
+	t.Run("Cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		c := collection.NewFromRange(0, 1000)
 
-	assert.Equal(t, []string{"a", "b", "c"}, v)
-}
+		var buf bytes.Buffer
+		err := c.ToWriterParallel(ctx, &buf, func(x int) ([]byte, error) {
+			if x == 10 {
+				cancel()
+			}
+			return render(x)
+		}, 2)
 
-func TestCollectionToMap(t *testing.T) {
-	type person struct {
-		Name string
-		Age  int
-	}
-	c := collection.NewFromSlice([]person{
-		{Name: "Alice", Age: 25},
-		{Name: "Bob", Age: 30},
-		{Name: "Charlie", Age: 35},
-	})
-	v := c.ToMap(func(x person) any {
-		return x.Name
+		assert.ErrorIs(t, err, context.Canceled)
 	})
-	s := c.ToSlice()
 
-	assert.Equal(t, s[0], v["Alice"])
-	assert.Equal(t, s[1], v["Bob"])
-	assert.Equal(t, s[2], v["Charlie"])
-}
+	t.Run("NoLeaks", func(t *testing.T) {
+		before := runtime.NumGoroutine()
 
-func TestToChannel(t *testing.T) {
-	c := collection.NewFromSlice([]string{"a", "b", "c"})
-	ch := c.ToChannel()
+		c := collection.NewFromRange(0, 100)
+		_ = c.ToWriterParallel(context.Background(), &failingWriter{n: 5}, render, 8)
 
-	var results []string
-	for v := range ch {
-		results = append(results, v)
-	}
+		deadline := time.Now().Add(time.Second)
+		for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+	})
 
-	assert.Equal(t, []string{"a", "b", "c"}, results)
+	t.Run("EmptyCollection", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := collection.NewFromSlice([]int{}).ToWriterParallel(context.Background(), &buf, render, 2)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, buf.Len())
+	})
 }
 
-func TestToJSON(t *testing.T) {
-	c := collection.NewFromSlice([]string{"a", "b", "c"})
-	v, err := c.ToJSON()
+// writerFunc adapts a function to an io.Writer
+type writerFunc func(p []byte) (int, error)
 
-	assert.Nil(t, err)
-	assert.Equal(t, `["a","b","c"]`, string(v))
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
 }
 
 func TestPop(t *testing.T) {
@@ -2716,3 +9286,225 @@ func TestShift(t *testing.T) {
 		assert.Equal(t, collection.ErrEmptyCollection, err)
 	})
 }
+
+func TestRemoveWhere(t *testing.T) {
+	even := func(x int) bool { return x%2 == 0 }
+
+	t.Run("SomeRemoved", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3, 4, 5})
+		removed := c.RemoveWhere(even)
+
+		assert.Equal(t, 2, removed)
+		assert.Equal(t, []int{1, 3, 5}, c.ToSlice())
+	})
+
+	t.Run("NoneRemoved", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 3})
+		removed := c.RemoveWhere(even)
+
+		assert.Equal(t, 0, removed)
+		assert.Equal(t, []int{1, 3}, c.ToSlice())
+	})
+
+	t.Run("AllRemoved", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{2, 4})
+		removed := c.RemoveWhere(even)
+
+		assert.Equal(t, 2, removed)
+		assert.True(t, c.IsEmpty())
+	})
+
+	t.Run("ReceiverReflectsRemoval", func(t *testing.T) {
+		c := collection.NewFromRange(0, 10)
+		c.RemoveWhere(even)
+		c.RemoveWhere(func(x int) bool { return x > 5 })
+
+		assert.Equal(t, []int{1, 3, 5}, c.ToSlice())
+		assert.Equal(t, []int{1, 3, 5}, c.ToSlice())
+	})
+
+	t.Run("SourceSliceUnchanged", func(t *testing.T) {
+		s := []int{1, 2, 3}
+		c := collection.NewFromSlice(s)
+		c.RemoveWhere(even)
+
+		assert.Equal(t, []int{1, 2, 3}, s)
+	})
+}
+
+func TestMapInPlace(t *testing.T) {
+	t.Run("AllElements", func(t *testing.T) {
+		c := collection.NewFromSlice([]string{"Alice", "BOB"})
+		c.MapInPlace(strings.ToLower)
+
+		assert.Equal(t, []string{"alice", "bob"}, c.ToSlice())
+	})
+
+	t.Run("VisibleOnReiteration", func(t *testing.T) {
+		calls := 0
+		c := collection.NewFromRange(1, 3)
+		c.MapInPlace(func(x int) int {
+			calls++
+			return x * 10
+		})
+
+		assert.Equal(t, []int{10, 20, 30}, c.ToSlice())
+		assert.Equal(t, []int{10, 20, 30}, c.ToSlice())
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("EmptyCollection", func(t *testing.T) {
+		c := collection.Empty[int]()
+		c.MapInPlace(func(x int) int { return x + 1 })
+
+		assert.True(t, c.IsEmpty())
+	})
+}
+
+func TestFilterSeq(t *testing.T) {
+	s := []int{1, 2, 3, 4, 5}
+	even := func(x int) bool { return x%2 == 0 }
+
+	t.Run("MatchesWhere", func(t *testing.T) {
+		expected := collection.NewFromSlice(s).Where(even).ToSlice()
+
+		assert.Equal(t, expected, slices.Collect(collection.FilterSeq(slices.Values(s), even)))
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		var pulled []int
+		seq := func(yield func(int) bool) {
+			for _, v := range s {
+				pulled = append(pulled, v)
+				if !yield(v) {
+					return
+				}
+			}
+		}
+
+		for range collection.FilterSeq(seq, even) {
+			break
+		}
+
+		assert.Equal(t, []int{1, 2}, pulled)
+	})
+}
+
+func TestMapSeq(t *testing.T) {
+	s := []int{1, 2, 3}
+	double := func(x int) string { return strconv.Itoa(x * 2) }
+
+	t.Run("MatchesSelect", func(t *testing.T) {
+		expected := collection.Select(collection.NewFromSlice(s), double).ToSlice()
+
+		assert.Equal(t, expected, slices.Collect(collection.MapSeq(slices.Values(s), double)))
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		for range collection.MapSeq(slices.Values(s), double) {
+			break
+		}
+	})
+}
+
+func TestTakeSeq(t *testing.T) {
+	s := []int{1, 2, 3, 4, 5}
+
+	t.Run("MatchesTake", func(t *testing.T) {
+		for _, n := range []int{-1, 0, 2, 5, 10} {
+			expected := collection.NewFromSlice(s).Take(n).ToSlice()
+
+			assert.Equal(t, expected, slices.Collect(collection.TakeSeq(slices.Values(s), n)))
+		}
+	})
+
+	t.Run("StopsPulling", func(t *testing.T) {
+		pulled := 0
+		seq := func(yield func(int) bool) {
+			for _, v := range s {
+				pulled++
+				if !yield(v) {
+					return
+				}
+			}
+		}
+
+		for range collection.TakeSeq(seq, 2) {
+		}
+
+		assert.Equal(t, 2, pulled)
+	})
+}
+
+func TestEmpty(t *testing.T) {
+	t.Run("Logic", func(t *testing.T) {
+		c := collection.Empty[string]()
+		_, ok := c.First()
+
+		assert.Equal(t, 0, c.Count())
+		assert.True(t, c.IsEmpty())
+		assert.False(t, ok)
+	})
+
+	t.Run("ConcatIdentity", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2})
+
+		assert.Equal(t, []int{1, 2}, collection.Empty[int]().Concat(c).ToSlice())
+		assert.Equal(t, []int{1, 2}, c.Concat(collection.Empty[int]()).ToSlice())
+	})
+
+	t.Run("Accumulate", func(t *testing.T) {
+		acc := collection.Empty[int]()
+		for i := range 3 {
+			acc = acc.Concat(collection.NewFromItems(i))
+		}
+
+		assert.Equal(t, []int{0, 1, 2}, acc.ToSlice())
+	})
+
+	t.Run("ConcatWithEmptyBreak", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2, 3})
+
+		assert.Equal(t, []int{1}, collection.Empty[int]().Concat(c).Take(1).ToSlice())
+		assert.Equal(t, []int{1}, c.Concat(collection.Empty[int]()).Take(1).ToSlice())
+	})
+
+	t.Run("ConcatDoesNotAlias", func(t *testing.T) {
+		c := collection.NewFromSlice([]int{1, 2})
+		acc := collection.Empty[int]().Concat(c)
+		_, err := acc.Pop()
+
+		assert.NoError(t, err)
+		assert.NotSame(t, c, acc)
+		assert.NotSame(t, c, c.Concat(collection.Empty[int]()))
+		assert.Equal(t, []int{1}, acc.ToSlice())
+		assert.Equal(t, []int{1, 2}, c.ToSlice())
+	})
+}
+
+func TestConcatSeq(t *testing.T) {
+	a := []int{1, 2}
+	b := []int{3, 4}
+
+	t.Run("MatchesConcat", func(t *testing.T) {
+		expected := collection.NewFromSlice(a).Concat(collection.NewFromSlice(b)).ToSlice()
+
+		assert.Equal(t, expected, slices.Collect(collection.ConcatSeq(slices.Values(a), slices.Values(b))))
+	})
+
+	t.Run("NoSeqs", func(t *testing.T) {
+		assert.Empty(t, slices.Collect(collection.ConcatSeq[int]()))
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		var result []int
+		for v := range collection.ConcatSeq(slices.Values(a), slices.Values(b)) {
+			result = append(result, v)
+			if v == 3 {
+				break
+			}
+		}
+
+		assert.Equal(t, []int{1, 2, 3}, result)
+	})
+}
@@ -0,0 +1,233 @@
+package collection_test
+
+import (
+	"iter"
+	"math/rand"
+	"testing"
+
+	collection "github.com/0x4c6565/go-collection"
+	"github.com/stretchr/testify/assert"
+)
+
+// memTracker measures how many elements an operator retains. Elements are produced by a tracked source, and each is
+// released according to what the consumer observes: once the consumer has received it for the last time or, if the
+// consumer never receives it, once the operator asks the source for the element after it. How many times the
+// consumer receives each element is recorded by a reference enumeration of the same operator, so no bound relies on
+// a model of the operator under test. The number of unreleased elements is sampled as each element is produced and
+// received, so an operator which holds elements it later yields is caught holding them
+type memTracker struct {
+	// expected holds the number of times the consumer receives each source element, or is nil while recording
+	expected map[int]int
+	received map[int]int
+	retained int
+	peak     int
+}
+
+// trackedElement is an element produced by a memTracker source, whose Value is its index in the source. Elements
+// created outside a source, such as those an operator inserts, have no tracker and are never counted
+type trackedElement struct {
+	tracker  *memTracker
+	released bool
+	Value    int
+}
+
+// release marks e as no longer retained, if it has not been released already
+func (e *trackedElement) release() {
+	if e.released {
+		return
+	}
+	e.released = true
+	e.tracker.retained--
+}
+
+// source returns a collection generating n tracked elements without retaining them. An element the consumer will
+// never receive is released once the operator asks for the element after it, as the operator has passed over it
+func (m *memTracker) source(n int) *collection.Collection[*trackedElement] {
+	return collection.NewFromIterator(iter.Seq[*trackedElement](func(yield func(*trackedElement) bool) {
+		var prev *trackedElement
+		defer func() { m.pass(prev) }()
+
+		for i := range n {
+			m.pass(prev)
+
+			e := &trackedElement{tracker: m, Value: i}
+			m.retained++
+			m.peak = max(m.peak, m.retained)
+			if !yield(e) {
+				return
+			}
+			prev = e
+		}
+	}))
+}
+
+// pass releases e once the operator has passed over it, if the consumer never receives it
+func (m *memTracker) pass(e *trackedElement) {
+	if e != nil && m.expected != nil && m.expected[e.Value] == 0 {
+		e.release()
+	}
+}
+
+// receive samples the number of unreleased elements, counting e itself as retained, and releases e if the consumer
+// has now received it for the last time
+func (m *memTracker) receive(e *trackedElement) {
+	if e.tracker == nil {
+		return
+	}
+
+	m.peak = max(m.peak, m.retained)
+	m.received[e.Value]++
+	if m.expected != nil && m.received[e.Value] == m.expected[e.Value] {
+		e.release()
+	}
+}
+
+// run enumerates operator over a source of n tracked elements, receiving each element the consumer is given
+func (m *memTracker) run(n int, operator func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement]) {
+	for v := range *operator(m.source(n)) {
+		m.receive(v)
+	}
+}
+
+// measure records how many times the consumer receives each element of a source of n elements given to operator,
+// then enumerates operator again with elements released accordingly, returning the tracker of the second pass
+func measure(n int, operator func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement]) *memTracker {
+	reference := &memTracker{received: map[int]int{}}
+	reference.run(n, operator)
+
+	m := &memTracker{expected: reference.received, received: map[int]int{}}
+	m.run(n, operator)
+	return m
+}
+
+// memoryBound documents the maximum number of elements an operator may retain when applied to the tracked source
+type memoryBound struct {
+	name     string
+	bound    int
+	operator func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement]
+}
+
+const memTrackSourceSize = 1000
+
+var memoryBounds = []memoryBound{
+	{"Where", 1, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return c.Where(func(x *trackedElement) bool { return x.Value%2 == 0 })
+	}},
+	{"Reject", 1, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return c.Reject(func(x *trackedElement) bool { return x.Value%2 == 0 })
+	}},
+	{"Select", 1, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return collection.Select(c, func(x *trackedElement) *trackedElement { return x })
+	}},
+	{"Skip", 1, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return c.Skip(100)
+	}},
+	{"SkipWhile", 1, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return c.SkipWhile(func(x *trackedElement) bool { return x.Value < 100 })
+	}},
+	{"SkipUntil", 1, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return c.SkipUntil(func(x *trackedElement) bool { return x.Value >= 100 })
+	}},
+	{"SkipLast", 11, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return c.SkipLast(10)
+	}},
+	{"Take", 1, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return c.Take(500)
+	}},
+	{"TakeWhile", 1, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return c.TakeWhile(func(x *trackedElement) bool { return x.Value < 500 })
+	}},
+	{"TakeUntil", 1, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return c.TakeUntil(func(x *trackedElement) bool { return x.Value >= 500 })
+	}},
+	{"TakeUntilInclusive", 1, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return c.TakeUntilInclusive(func(x *trackedElement) bool { return x.Value >= 500 })
+	}},
+	{"Stride", 1, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return c.Stride(7)
+	}},
+	{"StrideFrom", 1, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return c.StrideFrom(3, 7)
+	}},
+	{"SampleFraction", 1, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return c.SampleFraction(0.5, rand.New(rand.NewSource(1)))
+	}},
+	{"DedupConsecutive", 1, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return c.DedupConsecutive(func(a, b *trackedElement) bool { return a.Value/3 == b.Value/3 })
+	}},
+	{"Insert", 1, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return c.Insert(500, &trackedElement{Value: -1})
+	}},
+	{"RemoveAt", 1, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return c.RemoveAt(100)
+	}},
+	{"RemoveRange", 1, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return c.RemoveRange(100, 50)
+	}},
+	{"ReplaceWhere", 1, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return c.ReplaceWhere(func(x *trackedElement) bool { return x.Value%2 == 0 }, &trackedElement{Value: -1})
+	}},
+	{"WhereFollowedBy", 2, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return c.WhereFollowedBy(func(x *trackedElement) bool { return x.Value%2 == 0 })
+	}},
+	{"WhereNotFollowedBy", 2, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return c.WhereNotFollowedBy(func(x *trackedElement) bool { return x.Value%2 == 0 })
+	}},
+	{"ChunkSeq", 10, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return collection.Flatten(collection.ChunkSeq(c, 10))
+	}},
+	{"Windowed", 10, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return collection.Flatten(collection.Windowed(c, 10))
+	}},
+	{"SplitWhen", 10, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return collection.Flatten(collection.SplitWhen(c, func(x *trackedElement) bool { return x.Value%10 == 5 }))
+	}},
+	{"Peek", 1, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return c.Peek(func(*trackedElement) {})
+	}},
+	{"Append", 1, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return c.Append(&trackedElement{Value: -1})
+	}},
+	{"Prepend", 1, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return c.Prepend(&trackedElement{Value: -1})
+	}},
+	{"Concat", 1, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+		return c.Concat(collection.NewFromSlice([]*trackedElement{}))
+	}},
+}
+
+func TestMemoryBounds(t *testing.T) {
+	for _, b := range memoryBounds {
+		t.Run(b.name, func(t *testing.T) {
+			m := measure(memTrackSourceSize, b.operator)
+
+			assert.NotEmpty(t, m.received)
+			assert.Equal(t, b.bound, m.peak, "%s retained %d elements", b.name, m.peak)
+		})
+	}
+
+	t.Run("DetectsMaterialization", func(t *testing.T) {
+		m := measure(memTrackSourceSize, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+			return collection.NewFromSlice(c.ToSlice())
+		})
+
+		assert.Equal(t, memTrackSourceSize, m.peak)
+	})
+
+	t.Run("DetectsBuffering", func(t *testing.T) {
+		m := measure(memTrackSourceSize, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+			return collection.NewFromSlice(c.Where(func(x *trackedElement) bool { return x.Value%2 == 0 }).ToSlice())
+		})
+
+		// Every even element is held until the end, alongside the last odd element
+		assert.Equal(t, memTrackSourceSize/2+1, m.peak)
+	})
+
+	t.Run("DetectsReordering", func(t *testing.T) {
+		m := measure(memTrackSourceSize, func(c *collection.Collection[*trackedElement]) *collection.Collection[*trackedElement] {
+			return c.Reverse()
+		})
+
+		assert.Equal(t, memTrackSourceSize, m.peak)
+	})
+}